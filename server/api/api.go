@@ -2,7 +2,9 @@ package api
 
 import (
 	"canvas-admin/canvas"
+	"canvas-admin/sla"
 	"canvas-admin/supabase"
+	"canvas-admin/transcript"
 	"fmt"
 	"net/http"
 	"time"
@@ -13,16 +15,30 @@ import (
 )
 
 type APIController struct {
-	canvasClient   *canvas.CanvasClient
-	supabaseClient *supabase.SupabaseClient
-	auther         *auther
+	canvasClient             *canvas.CanvasClient
+	supabaseClient           *supabase.SupabaseClient
+	auther                   *auther
+	staffUsers               *staffUsers
+	slaConfig                sla.Config
+	transcripts              *transcript.Generator
+	classificationRulesCache *classificationRulesCache
+	liveEvents               *auther
+	accountSubtrees          *accountSubtreeCache
+	liveUngraded             *liveUngradedCache
 }
 
-func NewAPIController(canvasClient *canvas.CanvasClient, supabaseClient *supabase.SupabaseClient, secret []byte) *APIController {
+func NewAPIController(canvasClient *canvas.CanvasClient, supabaseClient *supabase.SupabaseClient, authConfig AuthConfig, slaConfig sla.Config, transcriptConfig transcript.Config, liveEventsConfig LiveEventsConfig) *APIController {
 	return &APIController{
-		canvasClient:   canvasClient,
-		supabaseClient: supabaseClient,
-		auther:         newAuther(secret),
+		canvasClient:             canvasClient,
+		supabaseClient:           supabaseClient,
+		auther:                   newAuther(authConfig),
+		staffUsers:               newStaffUsers(),
+		slaConfig:                slaConfig,
+		transcripts:              transcript.NewGenerator(transcriptConfig),
+		classificationRulesCache: newClassificationRulesCache(),
+		liveEvents:               newAuther(AuthConfig(liveEventsConfig)),
+		liveUngraded:             newLiveUngradedCache(),
+		accountSubtrees:          newAccountSubtreeCache(),
 	}
 }
 
@@ -32,7 +48,7 @@ func NewRouter(c *APIController, webUrl string) *chi.Mux {
 	r.Use(cors.Handler(cors.Options{
 		AllowedOrigins:   []string{webUrl},
 		AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-		AllowedHeaders:   []string{"Origin", "X-Requested-With", "Accept", "Authorization", "Content-Type", "X-CSRF-Token"},
+		AllowedHeaders:   []string{"Origin", "X-Requested-With", "Accept", "Authorization", "Content-Type", "X-CSRF-Token", "X-API-Key"},
 		AllowCredentials: false,
 		MaxAge:           300,
 	}))
@@ -43,28 +59,472 @@ func NewRouter(c *APIController, webUrl string) *chi.Mux {
 	r.Use(middleware.Recoverer)
 	r.Use(middleware.Timeout(60 * time.Second))
 
-	r.Route("/", func(r chi.Router) {
-		r.Get("/courses/{course_id}/ungraded-assignments", withError(withAuth(c, c.GetUngradedAssignmentsByCourse)))
-		r.Get("/courses/{course_id}/enrollments-results", withError(withAuth(c, c.GetEnrollmentResultsByCourse)))
-		r.Get("/courses/ungraded-assignments", withError(withAuth(c, c.GetUngradedAssignmentsByCourses)))
-
-		r.Get("/users/{user_id}/assignments-results", withError(withAuth(c, withUser(c, c.GetAssignmentsResultsByUser))))
-		r.Get("/users/{user_id}/enrollments-results", withError(withAuth(c, withUser(c, c.GetEnrollmentsResultsByUser))))
-		r.Get("/users/{user_id}/ungraded-assignments", withError(withAuth(c, withUser(c, c.GetUngradedAssignmentsByUser))))
-		r.Get("/users/{grader_id}/grade-change-logs", withError(withAuth(c, c.GetGradeChangeLogsByGraderID)))
-
-		r.Get("/accounts/{account_id}/courses", withError(withAuth(c, c.GetCoursesByAccountID)))
-		r.Get("/accounts/{account_id}/ungraded-assignments", withError(withAuth(c, c.GetUngradedAssignmentsByAccountID)))
+	routes := c.routes()
 
-		r.Delete("/users/{user_id}/sessions", withError(withAuth(c, c.TerminateUserSessions)))
-		r.Delete("/users/mobile_sessions", withError(withAuth(c, c.TerminateMobileSessions)))
+	r.Route("/", func(r chi.Router) {
+		for _, rt := range routes {
+			r.Method(rt.method, rt.pattern, withOperation(rt.operation, rt.handler))
+		}
 
+		r.Get("/openapi.json", openAPIHandler(routes))
 		r.Get("/hello", hello)
 	})
 
 	return r
 }
 
+// route describes an endpoint for both the router and the OpenAPI document.
+type route struct {
+	method      string
+	pattern     string
+	operation   string // OpenAPI operation id, also the generated client function name
+	summary     string
+	description string   // longer notes of the OpenAPI operation, optional
+	public      bool     // no access token is required
+	userOnly    bool     // signed in staff only, API keys cannot be granted the route
+	request     any      // bind request struct, nil when there are no parameters
+	body        any      // bindJSON request body, nil when there is none
+	response    any      // JSON response body, nil when there is none
+	downloads   []string // content types also served, selected with a format parameter
+	handler     http.HandlerFunc
+}
+
+func (c *APIController) routes() []route {
+	return []route{
+		{
+			method:    http.MethodGet,
+			pattern:   "/courses/{course_id}/ungraded-assignments",
+			operation: "GetUngradedAssignmentsByCourse",
+			summary:   "Ungraded assignments of a course by section",
+			request:   ungradedAssignmentsByCourseRequest{},
+			response:  []UngradedAssignmentWithAccountCourseInfo{},
+			handler:   withError(withAuth(c, c.GetUngradedAssignmentsByCourse)),
+		},
+		{
+			method:    http.MethodGet,
+			pattern:   "/courses/{course_id}/enrollments-results",
+			operation: "GetEnrollmentResultsByCourse",
+			summary:   "Student enrollment results of a course",
+			request:   enrollmentResultsByCourseRequest{},
+			response:  []EnrollmentResult{},
+			handler:   withError(withAuth(c, c.GetEnrollmentResultsByCourse)),
+		},
+		{
+			method:    http.MethodGet,
+			pattern:   "/courses/ungraded-assignments",
+			operation: "GetUngradedAssignmentsByCourses",
+			summary:   "Ungraded assignments of many courses by section",
+			request:   ungradedAssignmentsByCoursesRequest{},
+			response:  []UngradedAssignment{},
+			handler:   withError(withAuth(c, c.GetUngradedAssignmentsByCourses)),
+		},
+		{
+			method:    http.MethodGet,
+			pattern:   "/courses/{course_id}/assignments",
+			operation: "GetAssignmentExplorerByCourse",
+			summary:   "Assignments of a course by Canvas bucket, search, publishing and grading type, with the dates of each section",
+			request:   assignmentExplorerByCourseRequest{},
+			response:  []ExploredAssignment{},
+			handler:   withError(withAuth(c, c.GetAssignmentExplorerByCourse)),
+		},
+		{
+			method:    http.MethodGet,
+			pattern:   "/users/{user_id}/assignments-results",
+			operation: "GetAssignmentsResultsByUser",
+			summary:   "Assignment results of a student with course totals",
+			request:   userRequest{},
+			response:  []AssignmentResult{},
+			handler:   withError(withAuth(c, withUser(c, c.GetAssignmentsResultsByUser))),
+		},
+		{
+			method:    http.MethodGet,
+			pattern:   "/users/{user_id}/enrollments-results",
+			operation: "GetEnrollmentsResultsByUser",
+			summary:   "Enrollment results of a user",
+			request:   userRequest{},
+			response:  []EnrollmentResult{},
+			handler:   withError(withAuth(c, withUser(c, c.GetEnrollmentsResultsByUser))),
+		},
+		{
+			method:    http.MethodGet,
+			pattern:   "/users/{user_id}/statement-of-results",
+			operation: "GetStatementOfResults",
+			summary:   "Statement of results PDF of the completed enrollments of a student, by Canvas id or sis_user_id:<sis id>",
+			request:   statementOfResultsRequest{},
+			downloads: []string{pdfContentType},
+			handler:   withError(withAuth(c, c.GetStatementOfResults)),
+		},
+		{
+			method:    http.MethodGet,
+			pattern:   "/users/{user_id}/ungraded-assignments",
+			operation: "GetUngradedAssignmentsByUser",
+			summary:   "Submitted but ungraded assignments of a student",
+			request:   userRequest{},
+			response:  []GetUngradedAssignmentsByUserResponse{},
+			handler:   withError(withAuth(c, withUser(c, c.GetUngradedAssignmentsByUser))),
+		},
+		{
+			method:    http.MethodPost,
+			pattern:   "/users/batch-results",
+			operation: "GetBatchResultsBySISIDs",
+			summary:   "Enrollments, assignments and ungraded assignments of the students of a JSON or CSV list of SIS ids",
+			request:   batchResultsRequest{},
+			body:      batchResultsBody{},
+			response:  BatchResults{},
+			handler:   withError(withAuth(c, c.GetBatchResultsBySISIDs)),
+		},
+		{
+			method:    http.MethodGet,
+			pattern:   "/users/{grader_id}/grade-change-logs",
+			operation: "GetGradeChangeLogsByGraderID",
+			summary:   "Grade change audit logs of a grader",
+			request:   gradeChangeLogsRequest{},
+			response:  []GradeChangeLog{},
+			handler:   withError(withAuth(c, c.GetGradeChangeLogsByGraderID)),
+		},
+		{
+			method:    http.MethodGet,
+			pattern:   "/accounts/{account_id}/terms",
+			operation: "GetEnrollmentTermsByAccountID",
+			summary:   "Active enrollment terms of the root account of an account",
+			request:   accountRequest{},
+			response:  []canvas.EnrollmentTerm{},
+			handler:   withError(withAuth(c, c.GetEnrollmentTermsByAccountID)),
+		},
+		{
+			method:    http.MethodGet,
+			pattern:   "/accounts/{account_id}/tree",
+			operation: "GetAccountTree",
+			summary:   "Sub-account tree of an account with the courses of each sub-account",
+			request:   accountReportRequest{},
+			response:  AccountTree{},
+			handler:   withError(withAuth(c, c.GetAccountTree)),
+		},
+		{
+			method:    http.MethodGet,
+			pattern:   "/accounts/{account_id}/courses",
+			operation: "GetCoursesByAccountID",
+			summary:   "Courses of an account with student enrollments",
+			request:   accountReportRequest{},
+			response:  []canvas.Course{},
+			handler:   withError(withAuth(c, c.GetCoursesByAccountID)),
+		},
+		{
+			method:      http.MethodGet,
+			pattern:     "/accounts/{account_id}/courses/search",
+			operation:   "SearchCoursesByAccountID",
+			summary:     "Search the courses of an account by name, teacher, SIS id prefix, state and term, sorted and paginated",
+			description: courseSearchDescription,
+			request:     courseSearchRequest{},
+			response:    CourseSearchResults{},
+			handler:     withError(withAuth(c, c.SearchCoursesByAccountID)),
+		},
+		{
+			method:    http.MethodGet,
+			pattern:   "/accounts/{account_id}/ungraded-assignments",
+			operation: "GetUngradedAssignmentsByAccountID",
+			summary:   "Ungraded assignments of all courses in an account",
+			request:   accountReportRequest{},
+			response:  []UngradedAssignmentWithAccountCourseInfo{},
+			handler:   withError(withAuth(c, c.GetUngradedAssignmentsByAccountID)),
+		},
+		{
+			method:    http.MethodGet,
+			pattern:   "/accounts/{account_id}/ungraded-assignments/subtotals",
+			operation: "GetUngradedAssignmentSubtotalsByAccountID",
+			summary:   "Ungraded assignments of all courses in an account with subtotals for every sub-account",
+			request:   accountReportRequest{},
+			response:  UngradedAssignmentsReport{},
+			handler:   withError(withAuth(c, c.GetUngradedAssignmentSubtotalsByAccountID)),
+		},
+		{
+			method:    http.MethodGet,
+			pattern:   "/accounts/{account_id}/assignments",
+			operation: "GetAssignmentExplorerByAccount",
+			summary:   "Assignments of the courses of an account by Canvas bucket, search, publishing and grading type, with the dates of each section",
+			request:   assignmentExplorerByAccountRequest{},
+			response:  []ExploredAssignment{},
+			handler:   withError(withAuth(c, c.GetAssignmentExplorerByAccount)),
+		},
+		{
+			method:    http.MethodGet,
+			pattern:   "/accounts/{account_id}/teacher-workload",
+			operation: "GetTeacherWorkloadByAccountID",
+			summary:   "Submissions awaiting grading per teacher across an account",
+			request:   accountReportRequest{},
+			response:  []TeacherWorkload{},
+			handler:   withError(withAuth(c, c.GetTeacherWorkloadByAccountID)),
+		},
+		{
+			method:    http.MethodGet,
+			pattern:   "/accounts/{account_id}/grading-turnaround",
+			operation: "GetGradingTurnaroundByAccountID",
+			summary:   "Grading turnaround per assignment, section and grader with SLA breaches",
+			request:   turnaroundRequest{},
+			response:  TurnaroundReport{},
+			handler:   withError(withAuth(c, c.GetGradingTurnaroundByAccountID)),
+		},
+		{
+			method:    http.MethodGet,
+			pattern:   "/accounts/{account_id}/sla-breaches",
+			operation: "GetSLABreachesByAccountID",
+			summary:   "Submissions waiting for grading longer than the marking SLA",
+			request:   accountReportRequest{},
+			response:  []SLABreach{},
+			handler:   withError(withAuth(c, c.GetSLABreachesByAccountID)),
+		},
+		{
+			method:    http.MethodPost,
+			pattern:   "/sla/scan",
+			operation: "ScanGradingSLA",
+			summary:   "Alert teachers and escalate to managers the marking SLA breaches of accounts",
+			body:      slaScanRequest{},
+			response:  SLAScanResult{},
+			handler:   withError(withAuth(c, c.ScanGradingSLA)),
+		},
+		{
+			method:    http.MethodGet,
+			pattern:   "/courses/{course_id}/unposted-grades",
+			operation: "GetUnpostedGradesByCourse",
+			summary:   "Graded submissions of a course hidden from students by post policies",
+			request:   courseRequest{},
+			response:  UnpostedGradesReport{},
+			handler:   withError(withAuth(c, c.GetUnpostedGradesByCourse)),
+		},
+		{
+			method:    http.MethodPost,
+			pattern:   "/courses/{course_id}/grades/post",
+			operation: "PostGradesByCourse",
+			summary:   "Post the graded but hidden submissions of a course to students, or preview them with dry_run",
+			userOnly:  true,
+			request:   courseRequest{},
+			body:      postGradesBody{},
+			response:  GradePostingResult{},
+			handler:   withError(withAuth(c, withRole(adminAppRole, c.PostGradesByCourse))),
+		},
+		{
+			method:    http.MethodPost,
+			pattern:   "/courses/{course_id}/assignments/{assignment_id}/grades/hide",
+			operation: "HideGradesByAssignment",
+			summary:   "Hide the grades of an assignment from students, of some sections when set",
+			userOnly:  true,
+			request:   hideGradesRequest{},
+			body:      hideGradesBody{},
+			response:  GradePosting{},
+			handler:   withError(withAuth(c, withRole(adminAppRole, c.HideGradesByAssignment))),
+		},
+		{
+			method:    http.MethodGet,
+			pattern:   "/courses/{course_id}/extensions",
+			operation: "GetExtensionsByCourse",
+			summary:   "Active students with their own dates on the assignments of a course, with the reason and approver of each extension, expired ones with include_expired",
+			request:   extensionsRequest{},
+			response:  []Extension{},
+			handler:   withError(withAuth(c, c.GetExtensionsByCourse)),
+		},
+		{
+			method:    http.MethodPost,
+			pattern:   "/courses/{course_id}/extensions",
+			operation: "GrantExtensions",
+			summary:   "Grant students new due and lock dates on an assignment",
+			userOnly:  true,
+			request:   courseRequest{},
+			body:      grantExtensionsBody{},
+			response:  []ExtensionChange{},
+			handler:   withError(withAuth(c, withRole(adminAppRole, c.GrantExtensions))),
+		},
+		{
+			method:    http.MethodDelete,
+			pattern:   "/courses/{course_id}/assignments/{assignment_id}/extensions/{user_id}",
+			operation: "RemoveExtension",
+			summary:   "Remove the extension of a student on an assignment",
+			userOnly:  true,
+			request:   extensionRequest{},
+			handler:   withError(withAuth(c, withRole(adminAppRole, c.RemoveExtension))),
+		},
+		{
+			method:    http.MethodPost,
+			pattern:   "/courses/{course_id}/additional-attempts",
+			operation: "GrantAdditionalAttempts",
+			summary:   "Grant students with a failed attempt more attempts on assignments of a course, or validate them with dry_run",
+			userOnly:  true,
+			request:   courseRequest{},
+			body:      grantAttemptsBody{},
+			response:  []AttemptGrant{},
+			handler:   withError(withAuth(c, withRole(adminAppRole, c.GrantAdditionalAttempts))),
+		},
+		{
+			method:    http.MethodGet,
+			pattern:   "/accounts/{account_id}/unposted-grades",
+			operation: "GetUnpostedGradesByAccount",
+			summary:   "Graded submissions of all available courses in an account hidden from students by post policies",
+			request:   recursiveAccountReportRequest{},
+			response:  UnpostedGradesReport{},
+			handler:   withError(withAuth(c, c.GetUnpostedGradesByAccount)),
+		},
+		{
+			method:    http.MethodGet,
+			pattern:   "/courses/{course_id}/gradebook",
+			operation: "GetGradebookByCourse",
+			summary:   "Students by assignments matrix of a course or section as JSON, CSV or XLSX",
+			request:   gradebookRequest{},
+			response:  GradebookMatrix{},
+			downloads: []string{csvContentType, xlsxContentType},
+			handler:   withError(withAuth(c, c.GetGradebookByCourse)),
+		},
+		{
+			method:    http.MethodGet,
+			pattern:   "/courses/{course_id}/at-risk-students",
+			operation: "GetAtRiskStudentsByCourse",
+			summary:   "Active students of a course ranked by risk score",
+			request:   atRiskByCourseRequest{},
+			response:  []AtRiskStudent{},
+			handler:   withError(withAuth(c, c.GetAtRiskStudentsByCourse)),
+		},
+		{
+			method:    http.MethodGet,
+			pattern:   "/sections/{section_id}/at-risk-students",
+			operation: "GetAtRiskStudentsBySection",
+			summary:   "Active students of a section ranked by risk score",
+			request:   atRiskBySectionRequest{},
+			response:  []AtRiskStudent{},
+			handler:   withError(withAuth(c, c.GetAtRiskStudentsBySection)),
+		},
+		{
+			method:    http.MethodGet,
+			pattern:   "/accounts/{account_id}/at-risk-students",
+			operation: "GetAtRiskStudentsByAccount",
+			summary:   "Active students of all available courses in an account ranked by risk score",
+			request:   atRiskByAccountRequest{},
+			response:  []AtRiskStudent{},
+			handler:   withError(withAuth(c, c.GetAtRiskStudentsByAccount)),
+		},
+		{
+			method:    http.MethodDelete,
+			pattern:   "/users/{user_id}/sessions",
+			operation: "TerminateUserSessions",
+			summary:   "Terminate all sessions of a user",
+			userOnly:  true,
+			request:   userRequest{},
+			handler:   withError(withAuth(c, withRole(adminAppRole, c.TerminateUserSessions))),
+		},
+		{
+			method:    http.MethodDelete,
+			pattern:   "/users/mobile_sessions",
+			operation: "TerminateMobileSessions",
+			summary:   "Terminate all mobile app sessions",
+			userOnly:  true,
+			handler:   withError(withAuth(c, withRole(adminAppRole, c.TerminateMobileSessions))),
+		},
+		{
+			method:    http.MethodPost,
+			pattern:   "/users/batch-sessions/terminate",
+			operation: "TerminateBatchSessions",
+			summary:   "Terminate the sessions of the students of sections and courses and of users by SIS id, or preview them with dry_run",
+			userOnly:  true,
+			body:      batchSessionsBody{},
+			response:  BatchSessionTerminations{},
+			handler:   withError(withAuth(c, withRole(adminAppRole, c.TerminateBatchSessions))),
+		},
+		{
+			method:    http.MethodPost,
+			pattern:   "/enrollments/changes",
+			operation: "ChangeEnrollments",
+			summary:   "Conclude, deactivate, delete or reactivate the student enrollments of users in courses, or validate them with dry_run",
+			userOnly:  true,
+			body:      enrollmentChangesBody{},
+			response:  EnrollmentChangesResult{},
+			handler:   withError(withAuth(c, withRole(adminAppRole, c.ChangeEnrollments))),
+		},
+		{
+			method:    http.MethodPost,
+			pattern:   "/live-events",
+			operation: "IngestLiveEvent",
+			summary:   "Store a Canvas Live Event sent as a signed JWT, events are stored once and update the live ungraded counts",
+			public:    true,
+			response:  LiveEventReceipt{},
+			handler:   withError(c.IngestLiveEvent),
+		},
+		{
+			method:    http.MethodGet,
+			pattern:   "/courses/{course_id}/live-ungraded-counts",
+			operation: "GetLiveUngradedCountsByCourse",
+			summary:   "Submissions awaiting grading of each assignment of a course, from the Canvas Live Events",
+			request:   courseRequest{},
+			response:  []LiveUngradedCount{},
+			handler:   withError(withAuth(c, c.GetLiveUngradedCountsByCourse)),
+		},
+		{
+			method:    http.MethodGet,
+			pattern:   "/assignment-rules",
+			operation: "GetAssignmentRules",
+			summary:   "Rules classifying assignments in the reports, e.g. coversheets left out of them, in the order they apply",
+			response:  []supabase.AssignmentRule{},
+			handler:   withError(withAuth(c, c.GetAssignmentRules)),
+		},
+		{
+			method:    http.MethodPost,
+			pattern:   "/assignment-rules",
+			operation: "CreateAssignmentRule",
+			summary:   "Create an assignment rule, Admin only",
+			userOnly:  true,
+			body:      assignmentRuleBody{},
+			response:  supabase.AssignmentRule{},
+			handler:   withError(withAuth(c, withRole(adminAppRole, c.CreateAssignmentRule))),
+		},
+		{
+			method:    http.MethodPost,
+			pattern:   "/assignment-rules/{rule_id}",
+			operation: "UpdateAssignmentRule",
+			summary:   "Replace the conditions and classification of an assignment rule, Admin only",
+			userOnly:  true,
+			request:   assignmentRuleRequest{},
+			body:      assignmentRuleBody{},
+			response:  supabase.AssignmentRule{},
+			handler:   withError(withAuth(c, withRole(adminAppRole, c.UpdateAssignmentRule))),
+		},
+		{
+			method:    http.MethodDelete,
+			pattern:   "/assignment-rules/{rule_id}",
+			operation: "DeleteAssignmentRule",
+			summary:   "Delete an assignment rule, Admin only",
+			userOnly:  true,
+			request:   assignmentRuleRequest{},
+			response:  supabase.AssignmentRule{},
+			handler:   withError(withAuth(c, withRole(adminAppRole, c.DeleteAssignmentRule))),
+		},
+		{
+			method:    http.MethodGet,
+			pattern:   "/api-keys",
+			operation: "GetAPIKeys",
+			summary:   "API keys, Superadmin only",
+			userOnly:  true,
+			response:  []APIKey{},
+			handler:   withError(withAuth(c, withRole(superadminAppRole, c.GetAPIKeys))),
+		},
+		{
+			method:    http.MethodPost,
+			pattern:   "/api-keys",
+			operation: "CreateAPIKey",
+			summary:   "Create an API key, the key is only returned once. Superadmin only",
+			userOnly:  true,
+			body:      createAPIKeyRequest{},
+			response:  CreatedAPIKey{},
+			handler:   withError(withAuth(c, withRole(superadminAppRole, c.CreateAPIKey))),
+		},
+		{
+			method:    http.MethodDelete,
+			pattern:   "/api-keys/{api_key_id}",
+			operation: "RevokeAPIKey",
+			summary:   "Revoke an API key, Superadmin only",
+			userOnly:  true,
+			request:   apiKeyRequest{},
+			response:  APIKey{},
+			handler:   withError(withAuth(c, withRole(superadminAppRole, c.RevokeAPIKey))),
+		},
+	}
+}
+
 func hello(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
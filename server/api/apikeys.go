@@ -0,0 +1,274 @@
+package api
+
+import (
+	"canvas-admin/supabase"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/guregu/null/v5"
+)
+
+const (
+	apiKeyHeader = "X-API-Key"
+	apiKeyPrefix = "crk_"
+
+	// last_used_at is written at most once per apiKeyTouchInterval per key
+	apiKeyTouchInterval = time.Minute
+)
+
+type APIKey struct {
+	ID         string    `json:"id"`
+	Name       string    `json:"name"`
+	Prefix     string    `json:"prefix"`
+	Scopes     []string  `json:"scopes"`
+	AccountIDs []int     `json:"account_ids"`
+	CreatedBy  string    `json:"created_by"`
+	CreatedAt  time.Time `json:"created_at"`
+	ExpiresAt  null.Time `json:"expires_at"`
+	RevokedAt  null.Time `json:"revoked_at"`
+	LastUsedAt null.Time `json:"last_used_at"`
+}
+
+type CreatedAPIKey struct {
+	APIKey
+	Key string `json:"key"` // only returned on creation
+}
+
+type createAPIKeyRequest struct {
+	Name       string    `json:"name" validate:"required,max=100"`
+	Scopes     []string  `json:"scopes" validate:"required,min=1,dive,required"`
+	AccountIDs []int     `json:"account_ids,omitempty" validate:"dive,gt=0"`
+	ExpiresAt  null.Time `json:"expires_at,omitempty"`
+}
+
+type apiKeyRequest struct {
+	APIKeyID string `path:"api_key_id" validate:"required,uuid"`
+}
+
+func newAPIKey(k supabase.APIKey) APIKey {
+	return APIKey{
+		ID:         k.ID,
+		Name:       k.Name,
+		Prefix:     k.Prefix,
+		Scopes:     k.Scopes,
+		AccountIDs: k.AccountIDs,
+		CreatedBy:  k.CreatedBy,
+		CreatedAt:  k.CreatedAt,
+		ExpiresAt:  k.ExpiresAt,
+		RevokedAt:  k.RevokedAt,
+		LastUsedAt: k.LastUsedAt,
+	}
+}
+
+func hashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// apiKeyScopes returns the operations an API key can be granted.
+func (c *APIController) apiKeyScopes() []string {
+	scopes := make([]string, 0)
+
+	for _, rt := range c.routes() {
+		if !rt.public && !rt.userOnly {
+			scopes = append(scopes, rt.operation)
+		}
+	}
+
+	return scopes
+}
+
+// authenticateAPIKey checks the key is active and allowed to call the current
+// route. Keys limited to accounts can only call routes of an account, course
+// or section in one of the accounts or their sub-accounts.
+func (c *APIController) authenticateAPIKey(r *http.Request, key string) (principal, int, error) {
+	unauthorized := fmt.Errorf("%s", http.StatusText(http.StatusUnauthorized))
+	forbidden := fmt.Errorf("%s", http.StatusText(http.StatusForbidden))
+
+	apiKey, err := c.supabaseClient.GetAPIKeyByHash(hashAPIKey(key))
+	if errors.Is(err, supabase.ErrNotFound) {
+		return principal{}, http.StatusUnauthorized, unauthorized
+	}
+	if err != nil {
+		return principal{}, http.StatusInternalServerError, err
+	}
+
+	now := time.Now()
+
+	if apiKey.RevokedAt.Valid || (apiKey.ExpiresAt.Valid && now.After(apiKey.ExpiresAt.Time)) {
+		return principal{}, http.StatusUnauthorized, unauthorized
+	}
+
+	if !slices.Contains(apiKey.Scopes, operationFromContext(r.Context())) {
+		return principal{}, http.StatusForbidden, forbidden
+	}
+
+	if len(apiKey.AccountIDs) > 0 {
+		var accountID int
+
+		if id, err := strconv.Atoi(chi.URLParam(r, "account_id")); err == nil {
+			accountID = id
+		} else if id, err := strconv.Atoi(chi.URLParam(r, "course_id")); err == nil {
+			course, code, err := c.canvasClient.GetCourseByID(r.Context(), id)
+			if err != nil {
+				return principal{}, code, err
+			}
+
+			accountID = course.AccountID
+		} else if id, err := strconv.Atoi(chi.URLParam(r, "section_id")); err == nil {
+			section, code, err := c.canvasClient.GetSectionByID(r.Context(), id)
+			if err != nil {
+				return principal{}, code, err
+			}
+
+			course, code, err := c.canvasClient.GetCourseByID(r.Context(), section.CourseID)
+			if err != nil {
+				return principal{}, code, err
+			}
+
+			accountID = course.AccountID
+		}
+
+		if accountID == 0 {
+			return principal{}, http.StatusForbidden, forbidden
+		}
+
+		allowed := false
+
+		for _, keyAccountID := range apiKey.AccountIDs {
+			ids, code, err := c.accountSubtree(r.Context(), keyAccountID)
+			if err != nil {
+				return principal{}, code, err
+			}
+
+			if ids[accountID] {
+				allowed = true
+				break
+			}
+		}
+
+		if !allowed {
+			return principal{}, http.StatusForbidden, forbidden
+		}
+	}
+
+	if !apiKey.LastUsedAt.Valid || now.Sub(apiKey.LastUsedAt.Time) > apiKeyTouchInterval {
+		if err := c.supabaseClient.UpdateAPIKeyLastUsedAt(apiKey.ID, now); err != nil {
+			log.Printf("error updating last used at of api key %s: %v", apiKey.ID, err)
+		}
+	}
+
+	return principal{apiKey: &apiKey}, http.StatusOK, nil
+}
+
+func (c *APIController) GetAPIKeys(w http.ResponseWriter, r *http.Request) (int, error) {
+	apiKeys, err := c.supabaseClient.GetAPIKeys()
+	if err != nil {
+		return http.StatusInternalServerError, err
+	}
+
+	results := make([]APIKey, 0, len(apiKeys))
+
+	for _, k := range apiKeys {
+		results = append(results, newAPIKey(k))
+	}
+
+	if err := json.NewEncoder(w).Encode(results); err != nil {
+		return http.StatusInternalServerError, err
+	}
+
+	return http.StatusOK, nil
+}
+
+func (c *APIController) CreateAPIKey(w http.ResponseWriter, r *http.Request) (int, error) {
+	var req createAPIKeyRequest
+	if err := bindJSON(r, &req); err != nil {
+		return http.StatusBadRequest, err
+	}
+
+	fields := make([]fieldError, 0)
+
+	scopes := c.apiKeyScopes()
+
+	for i, scope := range req.Scopes {
+		if !slices.Contains(scopes, scope) {
+			fields = append(fields, fieldError{Field: fmt.Sprintf("scopes[%d]", i), Message: "is not an operation available to API keys"})
+		}
+	}
+
+	if req.ExpiresAt.Valid && req.ExpiresAt.Time.Before(time.Now()) {
+		fields = append(fields, fieldError{Field: "expires_at", Message: "must be in the future"})
+	}
+
+	if len(fields) > 0 {
+		return http.StatusBadRequest, &bindError{Fields: fields}
+	}
+
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return http.StatusInternalServerError, err
+	}
+
+	key := apiKeyPrefix + base64.RawURLEncoding.EncodeToString(secret)
+
+	accountIDs := req.AccountIDs
+	if accountIDs == nil {
+		accountIDs = []int{}
+	}
+
+	apiKey, err := c.supabaseClient.CreateAPIKey(supabase.NewAPIKey{
+		Name:       req.Name,
+		Prefix:     key[:len(apiKeyPrefix)+6],
+		KeyHash:    hashAPIKey(key),
+		Scopes:     req.Scopes,
+		AccountIDs: accountIDs,
+		CreatedBy:  principalFromContext(r.Context()).email,
+		ExpiresAt:  req.ExpiresAt,
+	})
+	if err != nil {
+		return http.StatusInternalServerError, err
+	}
+
+	result := CreatedAPIKey{
+		APIKey: newAPIKey(apiKey),
+		Key:    key,
+	}
+
+	if err := json.NewEncoder(w).Encode(result); err != nil {
+		return http.StatusInternalServerError, err
+	}
+
+	return http.StatusOK, nil
+}
+
+func (c *APIController) RevokeAPIKey(w http.ResponseWriter, r *http.Request) (int, error) {
+	var req apiKeyRequest
+	if err := bind(r, &req); err != nil {
+		return http.StatusBadRequest, err
+	}
+
+	apiKey, err := c.supabaseClient.RevokeAPIKey(req.APIKeyID, time.Now())
+	if errors.Is(err, supabase.ErrNotFound) {
+		return http.StatusNotFound, fmt.Errorf("api key not found or already revoked")
+	}
+	if err != nil {
+		return http.StatusInternalServerError, err
+	}
+
+	if err := json.NewEncoder(w).Encode(newAPIKey(apiKey)); err != nil {
+		return http.StatusInternalServerError, err
+	}
+
+	return http.StatusOK, nil
+}
@@ -0,0 +1,190 @@
+package api
+
+import (
+	"canvas-admin/canvas"
+	"canvas-admin/supabase"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/guregu/null/v5"
+)
+
+// newAPIKeyController serves the api keys from a fake Supabase, and an
+// account tree 1 > 2 > 3 from a fake Canvas with course 10 in account 1,
+// course 30 in account 3 and its section 300. It returns the count of sub
+// account listings.
+func newAPIKeyController(t *testing.T, keys map[string]supabase.APIKey) (*APIController, *atomic.Int32) {
+	t.Helper()
+
+	subAccountFetches := &atomic.Int32{}
+
+	canvasServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body any
+
+		switch r.URL.Path {
+		case "/accounts/1":
+			body = canvas.Account{ID: 1, Name: "Institute"}
+		case "/accounts/2":
+			body = canvas.Account{ID: 2, Name: "Health", ParentAccountID: null.IntFrom(1)}
+		case "/accounts/3":
+			body = canvas.Account{ID: 3, Name: "Fitness", ParentAccountID: null.IntFrom(2)}
+		case "/accounts/1/sub_accounts":
+			subAccountFetches.Add(1)
+			body = []canvas.Account{
+				{ID: 2, Name: "Health", ParentAccountID: null.IntFrom(1)},
+				{ID: 3, Name: "Fitness", ParentAccountID: null.IntFrom(2)},
+			}
+		case "/accounts/2/sub_accounts":
+			subAccountFetches.Add(1)
+			body = []canvas.Account{{ID: 3, Name: "Fitness", ParentAccountID: null.IntFrom(2)}}
+		case "/accounts/3/sub_accounts":
+			subAccountFetches.Add(1)
+			body = []canvas.Account{}
+		case "/courses/10":
+			body = map[string]any{"id": 10, "account_id": 1}
+		case "/courses/30":
+			body = map[string]any{"id": 30, "account_id": 3}
+		case "/sections/300":
+			body = map[string]any{"id": 300, "course_id": 30}
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		json.NewEncoder(w).Encode(body)
+	}))
+	t.Cleanup(canvasServer.Close)
+
+	supabaseServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/rest/v1/api_keys" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		if r.Method == http.MethodPatch {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+
+		results := []supabase.APIKey{}
+
+		hash := strings.TrimPrefix(r.URL.Query().Get("key_hash"), "eq.")
+
+		for key, apiKey := range keys {
+			if hashAPIKey(key) == hash {
+				results = append(results, apiKey)
+			}
+		}
+
+		json.NewEncoder(w).Encode(results)
+	}))
+	t.Cleanup(supabaseServer.Close)
+
+	supabaseClient, err := supabase.NewSupabaseClient(supabaseServer.URL, "anon", string(testSecret))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c := &APIController{
+		canvasClient:    canvas.NewCanvasClient(canvasServer.URL, "token", 100, ""),
+		supabaseClient:  supabaseClient,
+		accountSubtrees: newAccountSubtreeCache(),
+	}
+
+	return c, subAccountFetches
+}
+
+// newAPIKeyRequest is a request of an operation with the url params of its route.
+func newAPIKeyRequest(operation string, params map[string]string) *http.Request {
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+
+	rctx := chi.NewRouteContext()
+	for key, value := range params {
+		rctx.URLParams.Add(key, value)
+	}
+
+	ctx := context.WithValue(r.Context(), chi.RouteCtxKey, rctx)
+	ctx = context.WithValue(ctx, operationContextKey, operation)
+
+	return r.WithContext(ctx)
+}
+
+func TestAuthenticateAPIKey(t *testing.T) {
+	const operation = "GetUngradedAssignmentsByCourse"
+
+	hourAgo := time.Now().Add(-time.Hour)
+	nextHour := time.Now().Add(time.Hour)
+
+	keys := map[string]supabase.APIKey{
+		"crk_any":      {ID: "1", Name: "any", Scopes: []string{operation}},
+		"crk_revoked":  {ID: "2", Name: "revoked", Scopes: []string{operation}, RevokedAt: null.TimeFrom(hourAgo)},
+		"crk_expired":  {ID: "3", Name: "expired", Scopes: []string{operation}, ExpiresAt: null.TimeFrom(hourAgo)},
+		"crk_expiring": {ID: "4", Name: "expiring", Scopes: []string{operation}, ExpiresAt: null.TimeFrom(nextHour)},
+		"crk_other":    {ID: "5", Name: "other scope", Scopes: []string{"GetCoursesByAccountID"}},
+		"crk_health":   {ID: "6", Name: "health", Scopes: []string{operation}, AccountIDs: []int{2}},
+		"crk_fitness":  {ID: "7", Name: "fitness", Scopes: []string{operation}, AccountIDs: []int{3}},
+	}
+
+	tests := []struct {
+		name   string
+		key    string
+		params map[string]string
+		want   int
+	}{
+		{name: "active", key: "crk_any", params: map[string]string{"course_id": "10"}, want: http.StatusOK},
+		{name: "unknown", key: "crk_unknown", want: http.StatusUnauthorized},
+		{name: "revoked", key: "crk_revoked", want: http.StatusUnauthorized},
+		{name: "expired", key: "crk_expired", want: http.StatusUnauthorized},
+		{name: "not expired yet", key: "crk_expiring", want: http.StatusOK},
+		{name: "scope denied", key: "crk_other", params: map[string]string{"course_id": "10"}, want: http.StatusForbidden},
+		{name: "course of the account tree", key: "crk_health", params: map[string]string{"course_id": "30"}, want: http.StatusOK},
+		{name: "course of a parent account", key: "crk_health", params: map[string]string{"course_id": "10"}, want: http.StatusForbidden},
+		{name: "sub-account", key: "crk_health", params: map[string]string{"account_id": "3"}, want: http.StatusOK},
+		{name: "account of the key", key: "crk_health", params: map[string]string{"account_id": "2"}, want: http.StatusOK},
+		{name: "parent account", key: "crk_health", params: map[string]string{"account_id": "1"}, want: http.StatusForbidden},
+		{name: "section of the account tree", key: "crk_fitness", params: map[string]string{"section_id": "300"}, want: http.StatusOK},
+		{name: "course of another account", key: "crk_fitness", params: map[string]string{"course_id": "10"}, want: http.StatusForbidden},
+		{name: "route without an account", key: "crk_health", want: http.StatusForbidden},
+		{name: "unknown course", key: "crk_health", params: map[string]string{"course_id": "99"}, want: http.StatusNotFound},
+	}
+
+	c, _ := newAPIKeyController(t, keys)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, code, err := c.authenticateAPIKey(newAPIKeyRequest(operation, tt.params), tt.key)
+			if code != tt.want {
+				t.Fatalf("authenticateAPIKey() = %d, %v, want %d", code, err, tt.want)
+			}
+
+			if tt.want == http.StatusOK && (p.apiKey == nil || p.apiKey.Name != keys[tt.key].Name) {
+				t.Errorf("principal = %+v, want api key %s", p, keys[tt.key].Name)
+			}
+		})
+	}
+}
+
+func TestAuthenticateAPIKeyCachesSubAccounts(t *testing.T) {
+	const operation = "GetUngradedAssignmentsByCourse"
+
+	c, fetches := newAPIKeyController(t, map[string]supabase.APIKey{
+		"crk_health": {ID: "1", Name: "health", Scopes: []string{operation}, AccountIDs: []int{2}},
+	})
+
+	for range 3 {
+		if _, code, err := c.authenticateAPIKey(newAPIKeyRequest(operation, map[string]string{"course_id": "30"}), "crk_health"); code != http.StatusOK {
+			t.Fatalf("authenticateAPIKey() = %d, %v", code, err)
+		}
+	}
+
+	if got := fetches.Load(); got != 1 {
+		t.Errorf("sub-account fetches = %d, want 1", got)
+	}
+}
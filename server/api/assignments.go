@@ -6,10 +6,8 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
-	"strconv"
 	"strings"
 
-	"github.com/go-chi/chi/v5"
 	"github.com/guregu/null/v5"
 )
 
@@ -41,6 +39,13 @@ type UngradedAssignmentWithAccountCourseInfo struct {
 	GradebookURL          string `json:"gradebook_url"`
 }
 
+// UngradedAssignmentsReport is the ungraded assignments of the courses of an
+// account with subtotals for every sub-account of its tree.
+type UngradedAssignmentsReport struct {
+	Assignments []UngradedAssignmentWithAccountCourseInfo `json:"assignments"`
+	Accounts    []AccountSubtotal                         `json:"accounts"`
+}
+
 type UngradedAssignmentOfUser struct {
 	UserSisID       string     `json:"user_sis_id"`
 	Name            string     `json:"name"`
@@ -59,21 +64,22 @@ type UngradedAssignmentOfUser struct {
 }
 
 type AssignmentResult struct {
-	UserSisID       string     `json:"user_sis_id"`
-	Name            string     `json:"name"`
-	Acccount        string     `json:"account"`
-	CourseName      string     `json:"course_name"`
-	Section         string     `json:"section"`
-	Title           string     `json:"title"`
-	PointsPossible  null.Float `json:"points_possible"`
-	Score           null.Float `json:"score"`
-	Discrepancy     string     `json:"discrepancy"`
-	SubmittedAt     string     `json:"submitted_at"`
-	Status          string     `json:"status"`
-	DueAt           string     `json:"due_at"`
-	CourseState     string     `json:"course_state"`
-	EnrollmentRole  string     `json:"enrollment_role"`
-	EnrollmentState string     `json:"enrollment_state"`
+	UserSisID       string        `json:"user_sis_id"`
+	Name            string        `json:"name"`
+	Acccount        string        `json:"account"`
+	CourseName      string        `json:"course_name"`
+	Section         string        `json:"section"`
+	Title           string        `json:"title"`
+	PointsPossible  null.Float    `json:"points_possible"`
+	Score           null.Float    `json:"score"`
+	Discrepancy     string        `json:"discrepancy"` // "ERROR" with discrepancies
+	Discrepancies   []Discrepancy `json:"discrepancies"`
+	SubmittedAt     string        `json:"submitted_at"`
+	Status          string        `json:"status"`
+	DueAt           string        `json:"due_at"`
+	CourseState     string        `json:"course_state"`
+	EnrollmentRole  string        `json:"enrollment_role"`
+	EnrollmentState string        `json:"enrollment_state"`
 }
 
 type GradingStandardAssignment struct {
@@ -127,13 +133,28 @@ type GetUngradedAssignmentsByUserResponse struct {
 }
 
 const (
-	AssessmentCoversheet string = "Assessment Coversheet"
+	AssessmentCoversheet string = "Assessment Coversheet" // excluded by the default assignment rules
 	StatusOnTime         string = "on_time"
 	StatusLate           string = "late"
 )
 
 func (c *APIController) GetUngradedAssignmentsByUser(w http.ResponseWriter, r *http.Request, user canvas.User) (int, error) {
-	ctx, cancel := context.WithCancel(r.Context())
+	results, code, err := c.ungradedAssignmentsOfUser(r.Context(), user)
+	if err != nil {
+		return code, err
+	}
+
+	if err := json.NewEncoder(w).Encode(results); err != nil {
+		return http.StatusInternalServerError, err
+	}
+
+	return http.StatusOK, nil
+}
+
+// ungradedAssignmentsOfUser returns the submitted but ungraded submissions of
+// a student in available courses.
+func (c *APIController) ungradedAssignmentsOfUser(ctx context.Context, user canvas.User) ([]GetUngradedAssignmentsByUserResponse, int, error) {
+	ctx, cancel := context.WithCancel(ctx)
 	defer cancel()
 
 	results := make([]GetUngradedAssignmentsByUserResponse, 0)
@@ -143,25 +164,27 @@ func (c *APIController) GetUngradedAssignmentsByUser(w http.ResponseWriter, r *h
 
 	enrollments, code, err := c.canvasClient.GetEnrollmentsByUserID(ctx, user.ID, states)
 	if err != nil {
-		return code, err
+		return nil, code, err
 	}
 
 	coursesMap := make(map[int]canvas.Course, len(enrollments))
 
 	courses, code, err := c.canvasClient.GetCoursesByUserID(ctx, user.ID)
 	if err != nil {
-		return code, err
+		return nil, code, err
 	}
 
 	for _, course := range courses {
 		coursesMap[course.ID] = course
 	}
 
+	rules := c.classificationRules(ctx)
+
 outer:
 	for _, enrollment := range enrollments {
 		select {
 		case <-ctx.Done():
-			return http.StatusRequestTimeout, ctx.Err()
+			return nil, http.StatusRequestTimeout, ctx.Err()
 		default:
 			{
 				if enrollment.Role != string(canvas.StudentEnrollment) {
@@ -171,7 +194,7 @@ outer:
 				if _, ok := coursesMap[enrollment.CourseID]; !ok {
 					course, code, err := c.canvasClient.GetCourseByID(ctx, enrollment.CourseID)
 					if err != nil {
-						return code, err
+						return nil, code, err
 					}
 
 					coursesMap[enrollment.CourseID] = course
@@ -183,7 +206,7 @@ outer:
 
 				data, code, err := c.canvasClient.GetSubmissionsByCourseID(ctx, enrollment.CourseID, user.ID, canvas.SubmittedSubmissionWorkflowState)
 				if err != nil {
-					return code, err
+					return nil, code, err
 				}
 
 				sectionName := enrollment.SISSectionID
@@ -191,7 +214,7 @@ outer:
 				if sectionName == "" {
 					section, code, err := c.canvasClient.GetSectionByID(ctx, enrollment.CourseSectionID)
 					if err != nil {
-						return code, err
+						return nil, code, err
 					}
 
 					sectionName = section.Name
@@ -199,7 +222,7 @@ outer:
 
 			inner:
 				for _, submission := range data {
-					if strings.Contains(submission.Assignment.Name, AssessmentCoversheet) {
+					if rules.excludes(submissionTarget(submission, coursesMap[enrollment.CourseID].AccountID)) {
 						continue inner
 					}
 
@@ -231,6 +254,15 @@ outer:
 		}
 	}
 
+	return results, http.StatusOK, nil
+}
+
+func (c *APIController) GetAssignmentsResultsByUser(w http.ResponseWriter, r *http.Request, user canvas.User) (int, error) {
+	results, code, err := c.assignmentsResultsOfUser(r.Context(), user)
+	if err != nil {
+		return code, err
+	}
+
 	if err := json.NewEncoder(w).Encode(results); err != nil {
 		return http.StatusInternalServerError, err
 	}
@@ -238,8 +270,10 @@ outer:
 	return http.StatusOK, nil
 }
 
-func (c *APIController) GetAssignmentsResultsByUser(w http.ResponseWriter, r *http.Request, user canvas.User) (int, error) {
-	ctx, cancel := context.WithCancel(r.Context())
+// assignmentsResultsOfUser returns the results of a student in available
+// courses, each course followed by its total row.
+func (c *APIController) assignmentsResultsOfUser(ctx context.Context, user canvas.User) ([]AssignmentResult, int, error) {
+	ctx, cancel := context.WithCancel(ctx)
 	defer cancel()
 
 	results := make([]AssignmentResult, 0)
@@ -250,25 +284,30 @@ func (c *APIController) GetAssignmentsResultsByUser(w http.ResponseWriter, r *ht
 
 	enrollments, code, err := c.canvasClient.GetEnrollmentsByUserID(ctx, user.ID, states)
 	if err != nil {
-		return code, err
+		return nil, code, err
 	}
 
 	coursesMap := make(map[int]canvas.Course, len(enrollments))
 
 	courses, code, err := c.canvasClient.GetCoursesByUserID(ctx, user.ID)
 	if err != nil {
-		return code, err
+		return nil, code, err
 	}
 
 	for _, course := range courses {
 		coursesMap[course.ID] = course
 	}
 
+	rules := c.classificationRules(ctx)
+
+	// grading standards of the courses by id
+	gradingStandards := make(map[int]*canvas.GradingStandard)
+
 outer:
 	for _, enrollment := range enrollments {
 		select {
 		case <-ctx.Done():
-			return http.StatusRequestTimeout, ctx.Err()
+			return nil, http.StatusRequestTimeout, ctx.Err()
 		default:
 			{
 				if enrollment.Role != string(canvas.StudentEnrollment) {
@@ -278,7 +317,7 @@ outer:
 				if _, ok := coursesMap[enrollment.CourseID]; !ok {
 					course, code, err := c.canvasClient.GetCourseByID(ctx, enrollment.CourseID)
 					if err != nil {
-						return code, err
+						return nil, code, err
 					}
 
 					coursesMap[enrollment.CourseID] = course
@@ -290,7 +329,7 @@ outer:
 
 				data, code, err := c.canvasClient.GetAssignmentsDataOfUserByCourseID(ctx, user.ID, enrollment.CourseID)
 				if err != nil {
-					return code, err
+					return nil, code, err
 				}
 
 				sectionName := enrollment.SISSectionID
@@ -298,21 +337,40 @@ outer:
 				if sectionName == "" {
 					section, code, err := c.canvasClient.GetSectionByID(ctx, enrollment.CourseSectionID)
 					if err != nil {
-						return code, err
+						return nil, code, err
 					}
 
 					sectionName = section.Name
 				}
 
+				submissions, code, err := c.canvasClient.GetSubmissionsByCourseID(ctx, enrollment.CourseID, user.ID, "")
+				if err != nil {
+					return nil, code, err
+				}
+
+				submissionsMap := make(map[int]canvas.Submission, len(submissions))
+
+				for _, submission := range submissions {
+					submissionsMap[submission.AssignmentID] = submission
+				}
+
 				var pointsPossibleTotal float64
 				var scoreTotal float64
 
-				count := 0        // count of assignments that are not "Assessment Coversheet"
+				count := 0        // count of assignments not excluded by the rules
 				hasValid := false // there is atleast one valid score value
+				graded := true    // every assignment has a score or is excused
 
 			inner:
 				for _, ad := range data {
-					if strings.Contains(ad.Title, AssessmentCoversheet) {
+					target := ruleTarget{name: ad.Title, accountID: coursesMap[enrollment.CourseID].AccountID}
+
+					// the analytics leave out the group and grading type
+					if submission, ok := submissionsMap[ad.AssignmentID]; ok {
+						target = submissionTarget(submission, target.accountID)
+					}
+
+					if rules.excludes(target) {
 						continue inner
 					}
 
@@ -335,9 +393,13 @@ outer:
 						CourseState:     coursesMap[enrollment.CourseID].WorkflowState,
 					}
 
-					// Check for situation where student got more marks than possible
-					if ad.Submission.Score.Float64 > ad.PointsPossible.Float64 {
-						result.Discrepancy = "ERROR"
+					row := assignmentRow{data: ad, submission: submissionsMap[ad.AssignmentID]}
+
+					result.Discrepancies = checkAssignmentRow(row)
+					result.Discrepancy = discrepancyFlag(result.Discrepancies)
+
+					if !row.scored() && !row.submission.Excused.Bool {
+						graded = false
 					}
 
 					results = append(results, result)
@@ -350,11 +412,23 @@ outer:
 					}
 				}
 
+				gradingStandard, code, err := c.courseGradingStandard(ctx, gradingStandards, coursesMap[enrollment.CourseID])
+				if err != nil {
+					return nil, code, err
+				}
+
 				totalRow := AssignmentResult{
 					PointsPossible: null.FloatFrom(pointsPossibleTotal),
 					CourseName:     "Total",
 				}
 
+				totalRow.Discrepancies = checkCourseRow(courseRow{
+					grades:          enrollment.Grades,
+					gradingStandard: gradingStandard,
+					graded:          graded,
+				})
+				totalRow.Discrepancy = discrepancyFlag(totalRow.Discrepancies)
+
 				if hasValid {
 					totalRow.Score = null.FloatFrom(scoreTotal)
 				} else {
@@ -369,38 +443,85 @@ outer:
 		}
 	}
 
-	if err := json.NewEncoder(w).Encode(results); err != nil {
-		return http.StatusInternalServerError, err
-	}
-
-	return http.StatusOK, nil
+	return results, http.StatusOK, nil
 }
 
 type sectionWithTeachers struct {
 	id           int
 	sisSectionID string
-	teachers     []string
+	teachers     []canvas.User
 }
 
-func (c *APIController) GetUngradedAssignmentsByCourse(w http.ResponseWriter, r *http.Request) (int, error) {
-	ctx, cancel := context.WithCancel(r.Context())
-	defer cancel()
+func (st sectionWithTeachers) teacherNames() string {
+	names := make([]string, 0, len(st.teachers))
 
-	courseName := r.URL.Query().Get("course_name")
-	if courseName == "" {
-		return http.StatusBadRequest, fmt.Errorf("missing course_name query paramater")
+	for _, teacher := range st.teachers {
+		names = append(names, teacher.Name)
 	}
 
-	accountName := r.URL.Query().Get("account_name")
-	if accountName == "" {
-		return http.StatusBadRequest, fmt.Errorf("missing account_name query parameter")
+	return strings.Join(names, ";")
+}
+
+// getSectionWithTeachers resolves the teachers and the SIS id, or name, of a
+// section. Sections are cached in sectionsWithTeachersMap.
+func (c *APIController) getSectionWithTeachers(ctx context.Context, sectionsWithTeachersMap map[int]sectionWithTeachers, sectionID int) (sectionWithTeachers, int, error) {
+	if st, ok := sectionsWithTeachersMap[sectionID]; ok {
+		return st, http.StatusOK, nil
 	}
 
-	courseID, err := strconv.Atoi(chi.URLParam(r, "course_id"))
+	enrollments, code, err := c.canvasClient.GetEnrollmentsBySectionID(ctx, sectionID, nil, []canvas.EnrollmentType{canvas.TeacherEnrollment})
 	if err != nil {
+		return sectionWithTeachers{}, code, err
+	}
+
+	teachers := make([]canvas.User, 0, len(enrollments))
+
+	for _, enrollment := range enrollments {
+		teachers = append(teachers, enrollment.User)
+	}
+
+	st := sectionWithTeachers{
+		id:       sectionID,
+		teachers: teachers,
+	}
+
+	// there are teachers in the section
+	if len(enrollments) != 0 {
+		st.sisSectionID = enrollments[0].SISSectionID
+	}
+
+	// get section when there is no sis section id
+	if st.sisSectionID == "" {
+		section, code, err := c.canvasClient.GetSectionByID(ctx, sectionID)
+		if err != nil {
+			return sectionWithTeachers{}, code, err
+		}
+
+		st.sisSectionID = section.Name
+	}
+
+	sectionsWithTeachersMap[sectionID] = st
+
+	return st, http.StatusOK, nil
+}
+
+type ungradedAssignmentsByCourseRequest struct {
+	CourseID    int    `path:"course_id" validate:"required,gt=0"`
+	CourseName  string `query:"course_name" validate:"required"`
+	AccountName string `query:"account_name" validate:"required"`
+}
+
+func (c *APIController) GetUngradedAssignmentsByCourse(w http.ResponseWriter, r *http.Request) (int, error) {
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+
+	var req ungradedAssignmentsByCourseRequest
+	if err := bind(r, &req); err != nil {
 		return http.StatusBadRequest, err
 	}
 
+	courseID := req.CourseID
+
 	results := make([]UngradedAssignmentWithAccountCourseInfo, 0)
 
 	assignments, code, err := c.canvasClient.GetAssignmentsByCourseID(ctx, courseID, "", canvas.UngradedBucket, true)
@@ -408,6 +529,13 @@ func (c *APIController) GetUngradedAssignmentsByCourse(w http.ResponseWriter, r
 		return code, err
 	}
 
+	rules := c.classificationRules(ctx)
+
+	accountID, code, err := c.courseAccountID(ctx, rules, courseID)
+	if err != nil {
+		return code, err
+	}
+
 	// holds sections with teachers names
 	sectionsWithTeachersMap := make(map[int]sectionWithTeachers)
 
@@ -417,6 +545,10 @@ func (c *APIController) GetUngradedAssignmentsByCourse(w http.ResponseWriter, r
 			return http.StatusRequestTimeout, ctx.Err()
 		default:
 			{
+				if rules.excludes(assignmentTarget(assignment, accountID)) {
+					continue
+				}
+
 				for _, section := range assignment.NeedsGradingCountBySection {
 
 					datesMap := make(map[int]canvas.AssignmentDate)
@@ -448,41 +580,9 @@ func (c *APIController) GetUngradedAssignmentsByCourse(w http.ResponseWriter, r
 						}
 					}
 
-					// no section information at the moment
-					if _, ok := sectionsWithTeachersMap[section.SectionID]; !ok {
-
-						enrollments, code, err := c.canvasClient.GetEnrollmentsBySectionID(ctx, section.SectionID, nil, []canvas.EnrollmentType{canvas.TeacherEnrollment})
-						if err != nil {
-							return code, err
-						}
-
-						teachers := make([]string, 0, len(enrollments))
-
-						for _, enrollment := range enrollments {
-							teachers = append(teachers, enrollment.User.Name)
-						}
-
-						st := sectionWithTeachers{
-							id:       section.SectionID,
-							teachers: teachers,
-						}
-
-						// there are teachers in the section
-						if len(enrollments) != 0 {
-							st.sisSectionID = enrollments[0].SISSectionID
-						}
-
-						// get section when there is no sis section id
-						if st.sisSectionID == "" {
-							_section, code, err := c.canvasClient.GetSectionByID(ctx, section.SectionID)
-							if err != nil {
-								return code, err
-							}
-
-							st.sisSectionID = _section.Name
-						}
-
-						sectionsWithTeachersMap[section.SectionID] = st
+					st, code, err := c.getSectionWithTeachers(ctx, sectionsWithTeachersMap, section.SectionID)
+					if err != nil {
+						return code, err
 					}
 
 					result := UngradedAssignmentWithAccountCourseInfo{
@@ -490,16 +590,13 @@ func (c *APIController) GetUngradedAssignmentsByCourse(w http.ResponseWriter, r
 						CourseID:              assignment.CourseID,
 						NeedingGradingSection: section.NeedsGradingCount,
 						Published:             assignment.Published,
-						Account:               accountName,
-						CourseName:            courseName,
+						Account:               req.AccountName,
+						CourseName:            req.CourseName,
 						GradebookURL:          fmt.Sprintf(`%s/courses/%d/gradebook`, c.canvasClient.HtmlUrl, courseID),
 					}
 
-					// now we have section information
-					if st, ok := sectionsWithTeachersMap[section.SectionID]; ok {
-						result.Section = st.sisSectionID
-						result.Teachers = strings.Join(st.teachers, ";")
-					}
+					result.Section = st.sisSectionID
+					result.Teachers = st.teacherNames()
 
 					// section has date
 					if date, ok := datesMap[section.SectionID]; ok {
@@ -521,31 +618,35 @@ func (c *APIController) GetUngradedAssignmentsByCourse(w http.ResponseWriter, r
 	return http.StatusOK, nil
 }
 
+type ungradedAssignmentsByCoursesRequest struct {
+	IDs []int `query:"ids" validate:"required,min=1,dive,gt=0"`
+}
+
 func (c *APIController) GetUngradedAssignmentsByCourses(w http.ResponseWriter, r *http.Request) (int, error) {
 	ctx, cancel := context.WithCancel(r.Context())
 	defer cancel()
 
-	results := make([]UngradedAssignment, 0)
-
-	ids := r.URL.Query().Get("ids")
-	if ids == "" {
-		return http.StatusBadRequest, fmt.Errorf("missing courses ids")
+	var req ungradedAssignmentsByCoursesRequest
+	if err := bind(r, &req); err != nil {
+		return http.StatusBadRequest, err
 	}
 
-	courseIDs := strings.Split(ids, ",")
+	results := make([]UngradedAssignment, 0)
+
+	rules := c.classificationRules(ctx)
 
-	for _, id := range courseIDs {
+	for _, courseID := range req.IDs {
 		select {
 		case <-ctx.Done():
 			return http.StatusRequestTimeout, ctx.Err()
 		default:
 			{
-				courseID, err := strconv.Atoi(id)
+				assignments, code, err := c.canvasClient.GetAssignmentsByCourseID(ctx, courseID, "", canvas.UngradedBucket, true)
 				if err != nil {
-					return http.StatusBadRequest, fmt.Errorf("invalid course id: %s", id)
+					return code, err
 				}
 
-				assignments, code, err := c.canvasClient.GetAssignmentsByCourseID(ctx, courseID, "", canvas.UngradedBucket, true)
+				accountID, code, err := c.courseAccountID(ctx, rules, courseID)
 				if err != nil {
 					return code, err
 				}
@@ -554,6 +655,9 @@ func (c *APIController) GetUngradedAssignmentsByCourses(w http.ResponseWriter, r
 				sectionsWithTeachersMap := make(map[int]sectionWithTeachers)
 
 				for _, assignment := range assignments {
+					if rules.excludes(assignmentTarget(assignment, accountID)) {
+						continue
+					}
 
 					for _, section := range assignment.NeedsGradingCountBySection {
 
@@ -586,41 +690,9 @@ func (c *APIController) GetUngradedAssignmentsByCourses(w http.ResponseWriter, r
 							}
 						}
 
-						// no section information at the moment
-						if _, ok := sectionsWithTeachersMap[section.SectionID]; !ok {
-
-							enrollments, code, err := c.canvasClient.GetEnrollmentsBySectionID(ctx, section.SectionID, nil, []canvas.EnrollmentType{canvas.TeacherEnrollment})
-							if err != nil {
-								return code, err
-							}
-
-							teachers := make([]string, 0, len(enrollments))
-
-							for _, enrollment := range enrollments {
-								teachers = append(teachers, enrollment.User.Name)
-							}
-
-							st := sectionWithTeachers{
-								id:       section.SectionID,
-								teachers: teachers,
-							}
-
-							// there are teachers in the section
-							if len(enrollments) != 0 {
-								st.sisSectionID = enrollments[0].SISSectionID
-							}
-
-							// get section when there is no sis section id
-							if st.sisSectionID == "" {
-								_section, code, err := c.canvasClient.GetSectionByID(ctx, section.SectionID)
-								if err != nil {
-									return code, err
-								}
-
-								st.sisSectionID = _section.Name
-							}
-
-							sectionsWithTeachersMap[section.SectionID] = st
+						st, code, err := c.getSectionWithTeachers(ctx, sectionsWithTeachersMap, section.SectionID)
+						if err != nil {
+							return code, err
 						}
 
 						result := UngradedAssignment{
@@ -631,11 +703,8 @@ func (c *APIController) GetUngradedAssignmentsByCourses(w http.ResponseWriter, r
 							GradebookURL:          fmt.Sprintf(`%s/courses/%d/gradebook`, c.canvasClient.HtmlUrl, courseID),
 						}
 
-						// now we have section information
-						if st, ok := sectionsWithTeachersMap[section.SectionID]; ok {
-							result.Section = st.sisSectionID
-							result.Teachers = strings.Join(st.teachers, ";")
-						}
+						result.Section = st.sisSectionID
+						result.Teachers = st.teacherNames()
 
 						// section has date
 						if date, ok := datesMap[section.SectionID]; ok {
@@ -659,38 +728,107 @@ func (c *APIController) GetUngradedAssignmentsByCourses(w http.ResponseWriter, r
 }
 
 func (c *APIController) GetUngradedAssignmentsByAccountID(w http.ResponseWriter, r *http.Request) (int, error) {
-	ctx, cancel := context.WithCancel(r.Context())
-	defer cancel()
+	var req accountReportRequest
+	if err := bind(r, &req); err != nil {
+		return http.StatusBadRequest, err
+	}
 
-	results := make([]UngradedAssignmentWithAccountCourseInfo, 0)
+	filter, err := req.coursesFilter()
+	if err != nil {
+		return http.StatusBadRequest, err
+	}
 
-	accountID, err := strconv.Atoi(chi.URLParam(r, "account_id"))
+	results, _, code, err := c.ungradedAssignmentsByAccount(r.Context(), req.AccountID, filter)
 	if err != nil {
-		return http.StatusBadRequest, fmt.Errorf("invalid account id")
+		return code, err
 	}
 
-	types := []canvas.CourseEnrollmentType{canvas.StudentCourseEnrollment}
+	if err := json.NewEncoder(w).Encode(results); err != nil {
+		return http.StatusInternalServerError, err
+	}
+
+	return http.StatusOK, nil
+}
+
+// GetUngradedAssignmentSubtotalsByAccountID is GetUngradedAssignmentsByAccountID
+// with the submissions needing grading summed for every sub-account.
+func (c *APIController) GetUngradedAssignmentSubtotalsByAccountID(w http.ResponseWriter, r *http.Request) (int, error) {
+	var req accountReportRequest
+	if err := bind(r, &req); err != nil {
+		return http.StatusBadRequest, err
+	}
 
-	courses, code, err := c.canvasClient.GetCoursesByAccountID(ctx, accountID, "", types)
+	filter, err := req.coursesFilter()
+	if err != nil {
+		return http.StatusBadRequest, err
+	}
+
+	results, courses, code, err := c.ungradedAssignmentsByAccount(r.Context(), req.AccountID, filter)
+	if err != nil {
+		return code, err
+	}
+
+	tree, code, err := c.accountTree(r.Context(), req.AccountID, courses)
 	if err != nil {
 		return code, err
 	}
 
+	courseAccounts := make(map[int]int, len(courses))
+
+	for _, course := range courses {
+		courseAccounts[course.ID] = course.AccountID
+	}
+
+	counts := make(map[int]int)
+
+	for _, result := range results {
+		counts[courseAccounts[result.CourseID]] += result.NeedingGradingSection
+	}
+
+	report := UngradedAssignmentsReport{Assignments: results, Accounts: tree.subtotals(counts)}
+
+	if err := json.NewEncoder(w).Encode(report); err != nil {
+		return http.StatusInternalServerError, err
+	}
+
+	return http.StatusOK, nil
+}
+
+// ungradedAssignmentsByAccount returns the ungraded assignments of the courses
+// of an account, with the courses.
+func (c *APIController) ungradedAssignmentsByAccount(ctx context.Context, accountID int, filter canvas.CoursesFilter) ([]UngradedAssignmentWithAccountCourseInfo, []canvas.Course, int, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	results := make([]UngradedAssignmentWithAccountCourseInfo, 0)
+
+	types := []canvas.CourseEnrollmentType{canvas.StudentCourseEnrollment}
+
+	courses, code, err := c.canvasClient.GetCoursesByAccountID(ctx, accountID, "", types, filter)
+	if err != nil {
+		return nil, nil, code, err
+	}
+
+	rules := c.classificationRules(ctx)
+
 	for _, course := range courses {
 		select {
 		case <-ctx.Done():
-			return http.StatusRequestTimeout, ctx.Err()
+			return nil, nil, http.StatusRequestTimeout, ctx.Err()
 		default:
 			{
 				assignments, code, err := c.canvasClient.GetAssignmentsByCourseID(ctx, course.ID, "", canvas.UngradedBucket, true)
 				if err != nil {
-					return code, err
+					return nil, nil, code, err
 				}
 
 				// holds sections with teachers names
 				sectionsWithTeachersMap := make(map[int]sectionWithTeachers)
 
 				for _, assignment := range assignments {
+					if rules.excludes(assignmentTarget(assignment, course.AccountID)) {
+						continue
+					}
 
 					for _, section := range assignment.NeedsGradingCountBySection {
 
@@ -701,7 +839,7 @@ func (c *APIController) GetUngradedAssignmentsByAccountID(w http.ResponseWriter,
 						if len(assignment.AllDates) == 0 {
 							assignment, code, err := c.canvasClient.GetAssignmentByID(ctx, assignment.ID, assignment.CourseID, true)
 							if err != nil {
-								return code, err
+								return nil, nil, code, err
 							}
 
 							for _, o := range assignment.Overrides {
@@ -723,41 +861,9 @@ func (c *APIController) GetUngradedAssignmentsByAccountID(w http.ResponseWriter,
 							}
 						}
 
-						// no section information at the moment
-						if _, ok := sectionsWithTeachersMap[section.SectionID]; !ok {
-
-							enrollments, code, err := c.canvasClient.GetEnrollmentsBySectionID(ctx, section.SectionID, nil, []canvas.EnrollmentType{canvas.TeacherEnrollment})
-							if err != nil {
-								return code, err
-							}
-
-							teachers := make([]string, 0, len(enrollments))
-
-							for _, enrollment := range enrollments {
-								teachers = append(teachers, enrollment.User.Name)
-							}
-
-							st := sectionWithTeachers{
-								id:       section.SectionID,
-								teachers: teachers,
-							}
-
-							// there are teachers in the section
-							if len(enrollments) != 0 {
-								st.sisSectionID = enrollments[0].SISSectionID
-							}
-
-							// get section when there is no sis section id
-							if st.sisSectionID == "" {
-								_section, code, err := c.canvasClient.GetSectionByID(ctx, section.SectionID)
-								if err != nil {
-									return code, err
-								}
-
-								st.sisSectionID = _section.Name
-							}
-
-							sectionsWithTeachersMap[section.SectionID] = st
+						st, code, err := c.getSectionWithTeachers(ctx, sectionsWithTeachersMap, section.SectionID)
+						if err != nil {
+							return nil, nil, code, err
 						}
 
 						result := UngradedAssignmentWithAccountCourseInfo{
@@ -770,11 +876,8 @@ func (c *APIController) GetUngradedAssignmentsByAccountID(w http.ResponseWriter,
 							GradebookURL:          fmt.Sprintf(`%s/courses/%d/gradebook`, c.canvasClient.HtmlUrl, course.ID),
 						}
 
-						// now we have section information
-						if st, ok := sectionsWithTeachersMap[section.SectionID]; ok {
-							result.Section = st.sisSectionID
-							result.Teachers = strings.Join(st.teachers, ";")
-						}
+						result.Section = st.sisSectionID
+						result.Teachers = st.teacherNames()
 
 						// section has date
 						if date, ok := datesMap[section.SectionID]; ok {
@@ -790,9 +893,5 @@ func (c *APIController) GetUngradedAssignmentsByAccountID(w http.ResponseWriter,
 		}
 	}
 
-	if err := json.NewEncoder(w).Encode(results); err != nil {
-		return http.StatusInternalServerError, err
-	}
-
-	return http.StatusOK, nil
+	return results, courses, http.StatusOK, nil
 }
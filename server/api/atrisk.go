@@ -0,0 +1,413 @@
+package api
+
+import (
+	"canvas-admin/canvas"
+	"context"
+	"encoding/json"
+	"math"
+	"net/http"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/guregu/null/v5"
+)
+
+const (
+	StatusMissing string = "missing"
+
+	// analytics are requested for this many students at a time
+	atRiskConcurrency = 5
+)
+
+// risk factors and the points they add to the risk score
+const (
+	RiskMissing  string = "missing"
+	RiskLate     string = "late"
+	RiskLowScore string = "low_score"
+	RiskInactive string = "inactive"
+)
+
+var riskWeights = map[string]int{
+	RiskMissing:  3,
+	RiskLowScore: 3,
+	RiskInactive: 2,
+	RiskLate:     1,
+}
+
+type AtRiskStudent struct {
+	UserID                  int         `json:"user_id"`
+	UserSisID               string      `json:"user_sis_id"`
+	Name                    string      `json:"name"`
+	Account                 string      `json:"account"`
+	CourseID                int         `json:"course_id"`
+	CourseName              string      `json:"course_name"`
+	Section                 string      `json:"section"`
+	RiskScore               int         `json:"risk_score"`
+	RiskFactors             []string    `json:"risk_factors"`
+	MissingCount            int         `json:"missing_count"`
+	LateCount               int         `json:"late_count"`
+	OnTimeCount             int         `json:"on_time_count"`
+	CurrentScore            null.Float  `json:"current_score"`
+	CurrentGrade            null.String `json:"current_grade"`
+	LastSubmittedAt         null.String `json:"last_submitted_at"`
+	DaysSinceLastSubmission null.Int    `json:"days_since_last_submission"`
+	GradesURL               string      `json:"grades_url"`
+}
+
+// atRiskThresholds flag a student when reached. A student without any
+// submission is inactive once InactiveDays have passed since they enrolled.
+type atRiskThresholds struct {
+	MissingCount int      `query:"missing_count" validate:"gte=1"`
+	LateCount    int      `query:"late_count" validate:"gte=1"`
+	CurrentScore float64  `query:"current_score" validate:"gte=0,lte=100"`
+	InactiveDays int      `query:"inactive_days" validate:"gte=1"`
+	MinRiskScore int      `query:"min_risk_score" validate:"gte=0"`
+	Factors      []string `query:"factors" validate:"dive,oneof=missing late low_score inactive"`
+}
+
+func defaultAtRiskThresholds() atRiskThresholds {
+	return atRiskThresholds{
+		MissingCount: 2,
+		LateCount:    3,
+		CurrentScore: 50,
+		InactiveDays: 14,
+		MinRiskScore: 1,
+	}
+}
+
+type atRiskByCourseRequest struct {
+	CourseID int `path:"course_id" validate:"required,gt=0"`
+	atRiskThresholds
+}
+
+type atRiskBySectionRequest struct {
+	SectionID int `path:"section_id" validate:"required,gt=0"`
+	atRiskThresholds
+}
+
+type atRiskByAccountRequest struct {
+	AccountID int `path:"account_id" validate:"required,gt=0"`
+	atRiskThresholds
+	termFilter
+}
+
+func (c *APIController) GetAtRiskStudentsByCourse(w http.ResponseWriter, r *http.Request) (int, error) {
+	req := atRiskByCourseRequest{atRiskThresholds: defaultAtRiskThresholds()}
+	if err := bind(r, &req); err != nil {
+		return http.StatusBadRequest, err
+	}
+
+	course, code, err := c.canvasClient.GetCourseByID(r.Context(), req.CourseID)
+	if err != nil {
+		return code, err
+	}
+
+	results, code, err := c.atRiskStudents(r.Context(), course, nil, req.atRiskThresholds)
+	if err != nil {
+		return code, err
+	}
+
+	rankAtRiskStudents(results)
+
+	if err := json.NewEncoder(w).Encode(results); err != nil {
+		return http.StatusInternalServerError, err
+	}
+
+	return http.StatusOK, nil
+}
+
+func (c *APIController) GetAtRiskStudentsBySection(w http.ResponseWriter, r *http.Request) (int, error) {
+	req := atRiskBySectionRequest{atRiskThresholds: defaultAtRiskThresholds()}
+	if err := bind(r, &req); err != nil {
+		return http.StatusBadRequest, err
+	}
+
+	section, code, err := c.canvasClient.GetSectionByID(r.Context(), req.SectionID)
+	if err != nil {
+		return code, err
+	}
+
+	course, code, err := c.canvasClient.GetCourseByID(r.Context(), section.CourseID)
+	if err != nil {
+		return code, err
+	}
+
+	results, code, err := c.atRiskStudents(r.Context(), course, &section, req.atRiskThresholds)
+	if err != nil {
+		return code, err
+	}
+
+	rankAtRiskStudents(results)
+
+	if err := json.NewEncoder(w).Encode(results); err != nil {
+		return http.StatusInternalServerError, err
+	}
+
+	return http.StatusOK, nil
+}
+
+func (c *APIController) GetAtRiskStudentsByAccount(w http.ResponseWriter, r *http.Request) (int, error) {
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+
+	req := atRiskByAccountRequest{atRiskThresholds: defaultAtRiskThresholds()}
+	if err := bind(r, &req); err != nil {
+		return http.StatusBadRequest, err
+	}
+
+	filter, err := req.coursesFilter()
+	if err != nil {
+		return http.StatusBadRequest, err
+	}
+
+	types := []canvas.CourseEnrollmentType{canvas.StudentCourseEnrollment}
+
+	courses, code, err := c.canvasClient.GetCoursesByAccountID(ctx, req.AccountID, "", types, filter)
+	if err != nil {
+		return code, err
+	}
+
+	results := make([]AtRiskStudent, 0)
+
+	for _, course := range courses {
+		select {
+		case <-ctx.Done():
+			return http.StatusRequestTimeout, ctx.Err()
+		default:
+			{
+				if course.WorkflowState != string(canvas.AvailableCourseWorkflowState) {
+					continue
+				}
+
+				students, code, err := c.atRiskStudents(ctx, course, nil, req.atRiskThresholds)
+				if err != nil {
+					return code, err
+				}
+
+				results = append(results, students...)
+			}
+		}
+	}
+
+	rankAtRiskStudents(results)
+
+	if err := json.NewEncoder(w).Encode(results); err != nil {
+		return http.StatusInternalServerError, err
+	}
+
+	return http.StatusOK, nil
+}
+
+// atRiskStudents scores the active students of a course, or of one of its
+// sections when section is set. Students below the minimum risk score are left out.
+func (c *APIController) atRiskStudents(ctx context.Context, course canvas.Course, section *canvas.Section, thresholds atRiskThresholds) ([]AtRiskStudent, int, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	states := []canvas.EnrollmentState{canvas.ActiveEnrollment}
+
+	types := []canvas.EnrollmentType{canvas.StudentEnrollment}
+
+	var enrollments []canvas.Enrollment
+	var code int
+	var err error
+
+	if section != nil {
+		enrollments, code, err = c.canvasClient.GetEnrollmentsBySectionID(ctx, section.ID, states, types)
+	} else {
+		enrollments, code, err = c.canvasClient.GetEnrollmentsByCourseID(ctx, course.ID, states, types)
+	}
+	if err != nil {
+		return nil, code, err
+	}
+
+	sectionNames := make(map[int]string)
+
+	if section != nil {
+		sectionNames[section.ID] = section.Name
+	} else {
+		sections, code, err := c.canvasClient.GetSectionsByCourseID(ctx, course.ID)
+		if err != nil {
+			return nil, code, err
+		}
+
+		for _, s := range sections {
+			sectionNames[s.ID] = s.Name
+		}
+	}
+
+	// the analytics of the students leave out the group and grading type the
+	// rules match on
+	assignments, code, err := c.canvasClient.GetAssignmentsByCourseID(ctx, course.ID, "", canvas.AllBucket, false)
+	if err != nil {
+		return nil, code, err
+	}
+
+	excluded := c.classificationRules(ctx).excludedAssignments(assignments, course.AccountID)
+
+	now := time.Now()
+
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		errCode  int
+		firstErr error
+	)
+
+	results := make([]AtRiskStudent, 0, len(enrollments))
+
+	sem := make(chan struct{}, atRiskConcurrency)
+
+	for _, enrollment := range enrollments {
+		wg.Add(1)
+
+		go func(enrollment canvas.Enrollment) {
+			defer wg.Done()
+
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			if ctx.Err() != nil {
+				return
+			}
+
+			data, code, err := c.canvasClient.GetAssignmentsDataOfUserByCourseID(ctx, enrollment.UserID, course.ID)
+
+			mu.Lock()
+			defer mu.Unlock()
+
+			if err != nil {
+				if firstErr == nil {
+					errCode, firstErr = code, err
+					cancel()
+				}
+
+				return
+			}
+
+			result := scoreAtRiskStudent(enrollment, data, excluded, thresholds, now)
+
+			if result.RiskScore < thresholds.MinRiskScore {
+				return
+			}
+
+			result.Account = course.Account.Name
+			result.CourseName = course.Name
+
+			result.Section = enrollment.SISSectionID
+			if result.Section == "" {
+				result.Section = sectionNames[enrollment.CourseSectionID]
+			}
+
+			results = append(results, result)
+		}(enrollment)
+	}
+
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, errCode, firstErr
+	}
+
+	return results, http.StatusOK, nil
+}
+
+func scoreAtRiskStudent(enrollment canvas.Enrollment, data []canvas.AssignmentData, excluded map[int]bool, thresholds atRiskThresholds, now time.Time) AtRiskStudent {
+	result := AtRiskStudent{
+		UserID:       enrollment.UserID,
+		UserSisID:    enrollment.User.SISUserID,
+		Name:         enrollment.User.Name,
+		CourseID:     enrollment.CourseID,
+		CurrentScore: enrollment.Grades.CurrentScore,
+		CurrentGrade: enrollment.Grades.CurrentGrade,
+		GradesURL:    enrollment.Grades.HtmlUrl,
+		RiskFactors:  make([]string, 0),
+	}
+
+	var lastSubmittedAt time.Time
+
+	for _, ad := range data {
+		if excluded[ad.AssignmentID] {
+			continue
+		}
+
+		switch ad.Status {
+		case StatusMissing:
+			result.MissingCount++
+		case StatusLate:
+			result.LateCount++
+		case StatusOnTime:
+			result.OnTimeCount++
+		}
+
+		if submittedAt, err := time.Parse(time.RFC3339, ad.Submission.SubmittedAt); err == nil && submittedAt.After(lastSubmittedAt) {
+			lastSubmittedAt = submittedAt
+		}
+	}
+
+	// without submissions, inactivity counts from the enrollment
+	since := lastSubmittedAt
+
+	if !lastSubmittedAt.IsZero() {
+		result.LastSubmittedAt = null.StringFrom(lastSubmittedAt.Format(time.RFC3339))
+	} else if createdAt, err := time.Parse(time.RFC3339, enrollment.CreatedAt); err == nil {
+		since = createdAt
+	}
+
+	inactiveDays := -1
+
+	if !since.IsZero() {
+		inactiveDays = int(math.Floor(now.Sub(since).Hours() / 24))
+	}
+
+	if !lastSubmittedAt.IsZero() {
+		result.DaysSinceLastSubmission = null.IntFrom(int64(inactiveDays))
+	}
+
+	flagged := map[string]bool{
+		RiskMissing:  result.MissingCount >= thresholds.MissingCount,
+		RiskLate:     result.LateCount >= thresholds.LateCount,
+		RiskLowScore: result.CurrentScore.Valid && result.CurrentScore.Float64 < thresholds.CurrentScore,
+		RiskInactive: inactiveDays >= thresholds.InactiveDays,
+	}
+
+	for _, factor := range []string{RiskMissing, RiskLowScore, RiskInactive, RiskLate} {
+		if !flagged[factor] {
+			continue
+		}
+
+		// only the requested factors count towards the risk score
+		if len(thresholds.Factors) > 0 && !slices.Contains(thresholds.Factors, factor) {
+			continue
+		}
+
+		result.RiskFactors = append(result.RiskFactors, factor)
+		result.RiskScore += riskWeights[factor]
+	}
+
+	return result
+}
+
+// rankAtRiskStudents orders by risk score, then missing submissions, then
+// the lowest current score.
+func rankAtRiskStudents(results []AtRiskStudent) {
+	sort.SliceStable(results, func(i, j int) bool {
+		a, b := results[i], results[j]
+
+		if a.RiskScore != b.RiskScore {
+			return a.RiskScore > b.RiskScore
+		}
+
+		if a.MissingCount != b.MissingCount {
+			return a.MissingCount > b.MissingCount
+		}
+
+		if a.CurrentScore.Valid != b.CurrentScore.Valid {
+			return a.CurrentScore.Valid
+		}
+
+		return a.CurrentScore.Float64 < b.CurrentScore.Float64
+	})
+}
@@ -0,0 +1,235 @@
+package api
+
+import (
+	"canvas-admin/canvas"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/guregu/null/v5"
+)
+
+func TestScoreAtRiskStudent(t *testing.T) {
+	now := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+
+	daysAgo := func(days int) string {
+		return now.AddDate(0, 0, -days).Format(time.RFC3339)
+	}
+
+	status := func(id int, status string) canvas.AssignmentData {
+		return canvas.AssignmentData{AssignmentID: id, Status: status}
+	}
+
+	submitted := func(id int, status string, days int) canvas.AssignmentData {
+		return canvas.AssignmentData{AssignmentID: id, Status: status, Submission: canvas.AssignmentDataSubmission{SubmittedAt: daysAgo(days)}}
+	}
+
+	tests := []struct {
+		name          string
+		enrollment    canvas.Enrollment
+		data          []canvas.AssignmentData
+		excluded      map[int]bool
+		factors       []string
+		wantFactors   []string
+		wantScore     int
+		wantMissing   int
+		wantLate      int
+		wantOnTime    int
+		wantDaysSince null.Int
+		wantSubmitted null.String
+	}{
+		{
+			name:        "no data",
+			wantFactors: []string{},
+		},
+		{
+			name:        "missing below the threshold",
+			enrollment:  canvas.Enrollment{CreatedAt: daysAgo(1)},
+			data:        []canvas.AssignmentData{status(1, StatusMissing)},
+			wantFactors: []string{},
+			wantMissing: 1,
+		},
+		{
+			name:        "missing at the threshold",
+			enrollment:  canvas.Enrollment{CreatedAt: daysAgo(1)},
+			data:        []canvas.AssignmentData{status(1, StatusMissing), status(2, StatusMissing)},
+			wantFactors: []string{RiskMissing},
+			wantScore:   3,
+			wantMissing: 2,
+		},
+		{
+			name:          "late at the threshold",
+			data:          []canvas.AssignmentData{submitted(1, StatusLate, 1), submitted(2, StatusLate, 2), submitted(3, StatusLate, 3)},
+			wantFactors:   []string{RiskLate},
+			wantScore:     1,
+			wantLate:      3,
+			wantDaysSince: null.IntFrom(1),
+			wantSubmitted: null.StringFrom(daysAgo(1)),
+		},
+		{
+			name:        "score below the threshold",
+			enrollment:  canvas.Enrollment{CreatedAt: daysAgo(1), Grades: canvas.Grades{CurrentScore: null.FloatFrom(49.9)}},
+			wantFactors: []string{RiskLowScore},
+			wantScore:   3,
+		},
+		{
+			name:        "score at the threshold",
+			enrollment:  canvas.Enrollment{CreatedAt: daysAgo(1), Grades: canvas.Grades{CurrentScore: null.FloatFrom(50)}},
+			wantFactors: []string{},
+		},
+		{
+			name:        "inactive since enrolling",
+			enrollment:  canvas.Enrollment{CreatedAt: daysAgo(14)},
+			wantFactors: []string{RiskInactive},
+			wantScore:   2,
+		},
+		{
+			name:          "inactive since the last submission",
+			enrollment:    canvas.Enrollment{CreatedAt: daysAgo(60)},
+			data:          []canvas.AssignmentData{submitted(1, StatusOnTime, 30), submitted(2, StatusOnTime, 14)},
+			wantFactors:   []string{RiskInactive},
+			wantScore:     2,
+			wantOnTime:    2,
+			wantDaysSince: null.IntFrom(14),
+			wantSubmitted: null.StringFrom(daysAgo(14)),
+		},
+		{
+			name:          "active below the threshold",
+			enrollment:    canvas.Enrollment{CreatedAt: daysAgo(60)},
+			data:          []canvas.AssignmentData{submitted(1, StatusOnTime, 13)},
+			wantFactors:   []string{},
+			wantOnTime:    1,
+			wantDaysSince: null.IntFrom(13),
+			wantSubmitted: null.StringFrom(daysAgo(13)),
+		},
+		{
+			name:        "excluded assignments",
+			enrollment:  canvas.Enrollment{CreatedAt: daysAgo(1)},
+			data:        []canvas.AssignmentData{status(1, StatusMissing), status(2, StatusMissing), status(3, StatusMissing)},
+			excluded:    map[int]bool{1: true, 2: true},
+			wantFactors: []string{},
+			wantMissing: 1,
+		},
+		{
+			name:        "every factor in weight order",
+			enrollment:  canvas.Enrollment{CreatedAt: daysAgo(20), Grades: canvas.Grades{CurrentScore: null.FloatFrom(10)}},
+			data:        []canvas.AssignmentData{status(1, StatusMissing), status(2, StatusMissing), status(3, StatusLate), status(4, StatusLate), status(5, StatusLate)},
+			wantFactors: []string{RiskMissing, RiskLowScore, RiskInactive, RiskLate},
+			wantScore:   9,
+			wantMissing: 2,
+			wantLate:    3,
+		},
+		{
+			name:        "only requested factors",
+			enrollment:  canvas.Enrollment{CreatedAt: daysAgo(20), Grades: canvas.Grades{CurrentScore: null.FloatFrom(10)}},
+			data:        []canvas.AssignmentData{status(1, StatusMissing), status(2, StatusMissing)},
+			factors:     []string{RiskLate, RiskInactive},
+			wantFactors: []string{RiskInactive},
+			wantScore:   2,
+			wantMissing: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			thresholds := defaultAtRiskThresholds()
+			thresholds.Factors = tt.factors
+
+			got := scoreAtRiskStudent(tt.enrollment, tt.data, tt.excluded, thresholds, now)
+
+			if !reflect.DeepEqual(got.RiskFactors, tt.wantFactors) {
+				t.Errorf("RiskFactors = %v, want %v", got.RiskFactors, tt.wantFactors)
+			}
+
+			if got.RiskScore != tt.wantScore {
+				t.Errorf("RiskScore = %d, want %d", got.RiskScore, tt.wantScore)
+			}
+
+			if got.MissingCount != tt.wantMissing || got.LateCount != tt.wantLate || got.OnTimeCount != tt.wantOnTime {
+				t.Errorf("counts = %d missing, %d late, %d on time, want %d, %d, %d",
+					got.MissingCount, got.LateCount, got.OnTimeCount, tt.wantMissing, tt.wantLate, tt.wantOnTime)
+			}
+
+			if got.DaysSinceLastSubmission != tt.wantDaysSince {
+				t.Errorf("DaysSinceLastSubmission = %v, want %v", got.DaysSinceLastSubmission, tt.wantDaysSince)
+			}
+
+			if got.LastSubmittedAt != tt.wantSubmitted {
+				t.Errorf("LastSubmittedAt = %v, want %v", got.LastSubmittedAt, tt.wantSubmitted)
+			}
+		})
+	}
+}
+
+func TestRankAtRiskStudents(t *testing.T) {
+	student := func(id, score, missing int, current null.Float) AtRiskStudent {
+		return AtRiskStudent{UserID: id, RiskScore: score, MissingCount: missing, CurrentScore: current}
+	}
+
+	tests := []struct {
+		name     string
+		students []AtRiskStudent
+		want     []int
+	}{
+		{
+			name:     "empty",
+			students: []AtRiskStudent{},
+			want:     []int{},
+		},
+		{
+			name:     "single",
+			students: []AtRiskStudent{student(1, 3, 0, null.Float{})},
+			want:     []int{1},
+		},
+		{
+			name: "risk score first",
+			students: []AtRiskStudent{
+				student(1, 1, 5, null.FloatFrom(10)),
+				student(2, 9, 0, null.FloatFrom(90)),
+				student(3, 4, 2, null.FloatFrom(40)),
+			},
+			want: []int{2, 3, 1},
+		},
+		{
+			name: "then missing submissions",
+			students: []AtRiskStudent{
+				student(1, 3, 2, null.FloatFrom(10)),
+				student(2, 3, 4, null.FloatFrom(90)),
+			},
+			want: []int{2, 1},
+		},
+		{
+			name: "then the lowest current score, without a score last",
+			students: []AtRiskStudent{
+				student(1, 3, 2, null.Float{}),
+				student(2, 3, 2, null.FloatFrom(45)),
+				student(3, 3, 2, null.FloatFrom(20)),
+			},
+			want: []int{3, 2, 1},
+		},
+		{
+			name: "ties keep their order",
+			students: []AtRiskStudent{
+				student(1, 3, 2, null.FloatFrom(20)),
+				student(2, 3, 2, null.FloatFrom(20)),
+				student(3, 3, 2, null.FloatFrom(20)),
+			},
+			want: []int{1, 2, 3},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rankAtRiskStudents(tt.students)
+
+			got := make([]int, 0, len(tt.students))
+			for _, s := range tt.students {
+				got = append(got, s.UserID)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("rankAtRiskStudents() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,174 @@
+package api
+
+import (
+	"canvas-admin/canvas"
+	"canvas-admin/supabase"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+const (
+	AttemptGranted    = "granted"
+	AttemptWouldGrant = "would_grant" // dry run
+	AttemptRefused    = "refused"
+	AttemptFailed     = "failed"
+
+	AuditGrantAttempts = "attempts.grant"
+)
+
+type AttemptGrant struct {
+	AssignmentID    int    `json:"assignment_id"`
+	AssignmentName  string `json:"assignment_name"`
+	UserID          int    `json:"user_id"`
+	Attempt         int    `json:"attempt"` // of the failed submission
+	Grade           string `json:"grade"`
+	AllowedAttempts int    `json:"allowed_attempts"` // of the student once granted
+	Status          string `json:"status"`
+	Message         string `json:"message,omitempty"`
+}
+
+type attemptTarget struct {
+	AssignmentID int `json:"assignment_id" validate:"required,gt=0"`
+	UserID       int `json:"user_id" validate:"required,gt=0"`
+}
+
+type grantAttemptsBody struct {
+	Attempts      []attemptTarget `json:"attempts" validate:"required,min=1,max=100,dive"`
+	ExtraAttempts int             `json:"extra_attempts" validate:"gte=1,lte=5"` // 1 by default
+	// scores below this share of the points possible failed, pass/fail
+	// assignments fail when incomplete
+	PassPercentage float64 `json:"pass_percentage" validate:"gte=0,lte=100"` // 50 by default
+	Reason         string  `json:"reason" validate:"required"`
+	DryRun         bool    `json:"dry_run"`
+}
+
+// GrantAdditionalAttempts gives students more submission attempts on
+// assignments of a course, e.g. those of the additional attempt report. Only
+// students whose last attempt was graded as failed are granted attempts.
+func (c *APIController) GrantAdditionalAttempts(w http.ResponseWriter, r *http.Request) (int, error) {
+	var req courseRequest
+	if err := bind(r, &req); err != nil {
+		return http.StatusBadRequest, err
+	}
+
+	body := grantAttemptsBody{ExtraAttempts: 1, PassPercentage: 50}
+	if err := bindJSON(r, &body); err != nil {
+		return http.StatusBadRequest, err
+	}
+
+	ctx, code, err := c.actAsStaff(r.Context())
+	if err != nil {
+		return code, err
+	}
+
+	results := make([]AttemptGrant, 0, len(body.Attempts))
+	logs := make([]supabase.AuditLog, 0)
+
+	for _, target := range body.Attempts {
+		grant := c.grantAttempts(ctx, req.CourseID, target, body)
+
+		if grant.Status == AttemptGranted {
+			logs = append(logs, supabase.AuditLog{
+				Action:       AuditGrantAttempts,
+				CourseID:     req.CourseID,
+				AssignmentID: target.AssignmentID,
+				UserIDs:      []int{target.UserID},
+				Details: map[string]any{
+					"extra_attempts":   body.ExtraAttempts,
+					"allowed_attempts": grant.AllowedAttempts,
+					"failed_attempt":   grant.Attempt,
+					"grade":            grant.Grade,
+					"reason":           body.Reason,
+				},
+			})
+		}
+
+		results = append(results, grant)
+	}
+
+	c.audit(ctx, logs...)
+
+	if err := json.NewEncoder(w).Encode(results); err != nil {
+		return http.StatusInternalServerError, err
+	}
+
+	return http.StatusOK, nil
+}
+
+func (c *APIController) grantAttempts(ctx context.Context, courseID int, target attemptTarget, body grantAttemptsBody) AttemptGrant {
+	grant := AttemptGrant{AssignmentID: target.AssignmentID, UserID: target.UserID}
+
+	submission, _, err := c.canvasClient.GetSubmission(ctx, courseID, target.AssignmentID, target.UserID)
+	if err != nil {
+		grant.Status, grant.Message = AttemptFailed, err.Error()
+		return grant
+	}
+
+	grant.AssignmentName = submission.Assignment.Name
+	grant.Attempt = int(submission.Attempt.Int64)
+	grant.Grade = submission.Grade.String
+
+	if submission.Assignment.AllowedAttempts < 0 {
+		grant.Status, grant.Message = AttemptRefused, "the assignment allows unlimited attempts"
+		return grant
+	}
+
+	if reason := failedAttempt(submission, body.PassPercentage); reason != "" {
+		grant.Status, grant.Message = AttemptRefused, reason
+		return grant
+	}
+
+	extraAttempts := int(submission.ExtraAttempts.Int64) + body.ExtraAttempts
+
+	grant.AllowedAttempts = submission.Assignment.AllowedAttempts + extraAttempts
+
+	if body.DryRun {
+		grant.Status = AttemptWouldGrant
+		return grant
+	}
+
+	if _, _, err := c.canvasClient.UpdateSubmissionExtraAttempts(ctx, courseID, target.AssignmentID, target.UserID, extraAttempts); err != nil {
+		grant.Status, grant.Message = AttemptFailed, err.Error()
+		return grant
+	}
+
+	grant.Status = AttemptGranted
+
+	return grant
+}
+
+// failedAttempt explains why the last attempt of a submission does not count
+// as failed, empty when it does.
+func failedAttempt(submission canvas.Submission, passPercentage float64) string {
+	switch {
+	case !submission.Attempt.Valid || submission.Attempt.Int64 == 0:
+		return "the student has not submitted"
+	case submission.Excused.Bool:
+		return "the submission is excused"
+	case submission.WorkflowState != string(canvas.GradedSubmissionWorkflowState) || !submission.GradeMatchesCurrentSubmission:
+		return "the last attempt is not graded yet"
+	}
+
+	if submission.Assignment.GradingType == "pass_fail" {
+		if strings.EqualFold(submission.Grade.String, "incomplete") {
+			return ""
+		}
+
+		return fmt.Sprintf("the last attempt passed with %s", submission.Grade.String)
+	}
+
+	pointsPossible := submission.Assignment.PointsPossible.Float64
+
+	if !submission.Score.Valid || pointsPossible <= 0 {
+		return "the last attempt has no score to fail"
+	}
+
+	if percentage := submission.Score.Float64 / pointsPossible * 100; percentage >= passPercentage {
+		return fmt.Sprintf("the last attempt passed with %s%%", formatPoints(percentage))
+	}
+
+	return ""
+}
@@ -0,0 +1,34 @@
+package api
+
+import (
+	"canvas-admin/supabase"
+	"context"
+	"log"
+)
+
+const (
+	AuditPostGrades = "grades.post"
+	AuditHideGrades = "grades.hide"
+
+	AuditGenerateStatementOfResults = "statement_of_results.generate"
+
+	// followed by the action of ChangeEnrollments, e.g. enrollments.conclude
+	auditEnrollmentsPrefix = "enrollments."
+)
+
+// audit records Canvas write actions, and access to student records, of the
+// principal of ctx. The actions are done by then, so a record that fails to
+// save is logged instead of failing the request.
+func (c *APIController) audit(ctx context.Context, logs ...supabase.AuditLog) {
+	actor := principalFromContext(ctx).actor()
+
+	for i := range logs {
+		logs[i].Actor = actor
+
+		log.Printf("%s %s: course %d, assignment %d, sections %v, users %v", actor, logs[i].Action, logs[i].CourseID, logs[i].AssignmentID, logs[i].SectionIDs, logs[i].UserIDs)
+	}
+
+	if err := c.supabaseClient.CreateAuditLogs(logs); err != nil {
+		log.Printf("error creating audit logs: %v", err)
+	}
+}
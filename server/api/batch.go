@@ -0,0 +1,229 @@
+package api
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+)
+
+const (
+	BatchEnrollmentsReport = "enrollments"
+	BatchAssignmentsReport = "assignments"
+	BatchUngradedReport    = "ungraded"
+
+	// students are reported this many at a time
+	batchConcurrency = 5
+
+	batchMaxSISIDs = 500
+)
+
+type BatchResults struct {
+	Enrollments         []EnrollmentResult                     `json:"enrollments"`
+	Assignments         []AssignmentResult                     `json:"assignments"`
+	UngradedAssignments []GetUngradedAssignmentsByUserResponse `json:"ungraded_assignments"`
+	Unresolved          []BatchUnresolved                      `json:"unresolved"`
+}
+
+type BatchUnresolved struct {
+	SISID  string `json:"sis_id"`
+	Reason string `json:"reason"`
+}
+
+type batchResultsRequest struct {
+	// all reports by default
+	Reports []string `query:"reports" validate:"dive,oneof=enrollments assignments ungraded"`
+}
+
+type batchResultsBody struct {
+	SISIDs []string `json:"sis_ids" validate:"required,min=1,dive,required"`
+}
+
+// batchStudent is the reports of a student, kept apart to return the students
+// in the order of the list.
+type batchStudent struct {
+	enrollments []EnrollmentResult
+	assignments []AssignmentResult
+	ungraded    []GetUngradedAssignmentsByUserResponse
+	unresolved  *BatchUnresolved
+}
+
+// GetBatchResultsBySISIDs runs the student reports for a list of SIS ids, sent
+// as JSON or as a CSV with the ids in the first column. Ids without a Canvas
+// user, or whose reports fail, are returned as unresolved.
+func (c *APIController) GetBatchResultsBySISIDs(w http.ResponseWriter, r *http.Request) (int, error) {
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+
+	var req batchResultsRequest
+	if err := bind(r, &req); err != nil {
+		return http.StatusBadRequest, err
+	}
+
+	if len(req.Reports) == 0 {
+		req.Reports = []string{BatchEnrollmentsReport, BatchAssignmentsReport, BatchUngradedReport}
+	}
+
+	sisIDs, err := bindSISIDs(r)
+	if err != nil {
+		return http.StatusBadRequest, err
+	}
+
+	students := make([]batchStudent, len(sisIDs))
+
+	var wg sync.WaitGroup
+
+	sem := make(chan struct{}, batchConcurrency)
+
+	for i, sisID := range sisIDs {
+		wg.Add(1)
+
+		go func(i int, sisID string) {
+			defer wg.Done()
+
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			if ctx.Err() != nil {
+				return
+			}
+
+			students[i] = c.batchStudent(ctx, sisID, req.Reports)
+		}(i, sisID)
+	}
+
+	wg.Wait()
+
+	if err := ctx.Err(); err != nil {
+		return http.StatusRequestTimeout, err
+	}
+
+	results := BatchResults{
+		Enrollments:         make([]EnrollmentResult, 0),
+		Assignments:         make([]AssignmentResult, 0),
+		UngradedAssignments: make([]GetUngradedAssignmentsByUserResponse, 0),
+		Unresolved:          make([]BatchUnresolved, 0),
+	}
+
+	for _, s := range students {
+		if s.unresolved != nil {
+			results.Unresolved = append(results.Unresolved, *s.unresolved)
+			continue
+		}
+
+		results.Enrollments = append(results.Enrollments, s.enrollments...)
+		results.Assignments = append(results.Assignments, s.assignments...)
+		results.UngradedAssignments = append(results.UngradedAssignments, s.ungraded...)
+	}
+
+	log.Printf("%s ran batch reports %v of %d students, %d unresolved", principalFromContext(ctx).actor(), req.Reports, len(sisIDs), len(results.Unresolved))
+
+	if err := json.NewEncoder(w).Encode(results); err != nil {
+		return http.StatusInternalServerError, err
+	}
+
+	return http.StatusOK, nil
+}
+
+func (c *APIController) batchStudent(ctx context.Context, sisID string, reports []string) batchStudent {
+	var s batchStudent
+
+	user, code, err := c.canvasClient.GetUserBySisID(ctx, sisID)
+	if code == http.StatusNotFound {
+		s.unresolved = &BatchUnresolved{SISID: sisID, Reason: "no Canvas user with this SIS id"}
+		return s
+	}
+	if err != nil {
+		s.unresolved = &BatchUnresolved{SISID: sisID, Reason: err.Error()}
+		return s
+	}
+
+	if slices.Contains(reports, BatchEnrollmentsReport) {
+		if s.enrollments, _, err = c.enrollmentsResultsOfUser(ctx, user); err != nil {
+			return batchStudent{unresolved: &BatchUnresolved{SISID: sisID, Reason: err.Error()}}
+		}
+	}
+
+	if slices.Contains(reports, BatchAssignmentsReport) {
+		if s.assignments, _, err = c.assignmentsResultsOfUser(ctx, user); err != nil {
+			return batchStudent{unresolved: &BatchUnresolved{SISID: sisID, Reason: err.Error()}}
+		}
+	}
+
+	if slices.Contains(reports, BatchUngradedReport) {
+		if s.ungraded, _, err = c.ungradedAssignmentsOfUser(ctx, user); err != nil {
+			return batchStudent{unresolved: &BatchUnresolved{SISID: sisID, Reason: err.Error()}}
+		}
+	}
+
+	return s
+}
+
+// bindSISIDs reads the SIS ids of a JSON or CSV body, trimmed and without
+// duplicates. A first CSV row naming the column, e.g. "SIS ID", is skipped.
+func bindSISIDs(r *http.Request) ([]string, error) {
+	var ids []string
+
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+
+	if mediaType == csvContentType {
+		reader := csv.NewReader(r.Body)
+		reader.FieldsPerRecord = -1
+
+		for row := 0; ; row++ {
+			record, err := reader.Read()
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			if err != nil {
+				return nil, &bindError{Fields: []fieldError{{Field: "body", Message: fmt.Sprintf("must be valid CSV: %v", err)}}}
+			}
+
+			if len(record) == 0 {
+				continue
+			}
+
+			if row == 0 && strings.Contains(strings.ToLower(record[0]), "sis") {
+				continue
+			}
+
+			ids = append(ids, record[0])
+		}
+	} else {
+		var body batchResultsBody
+		if err := bindJSON(r, &body); err != nil {
+			return nil, err
+		}
+
+		ids = body.SISIDs
+	}
+
+	results := make([]string, 0, len(ids))
+
+	for _, id := range ids {
+		// spreadsheets often keep a byte order mark
+		id = strings.TrimSpace(strings.TrimPrefix(id, "\ufeff"))
+
+		if id != "" && !slices.Contains(results, id) {
+			results = append(results, id)
+		}
+	}
+
+	if len(results) == 0 {
+		return nil, &bindError{Fields: []fieldError{{Field: "sis_ids", Message: "must have at least 1 SIS id"}}}
+	}
+
+	if len(results) > batchMaxSISIDs {
+		return nil, &bindError{Fields: []fieldError{{Field: "sis_ids", Message: fmt.Sprintf("must have at most %d SIS ids", batchMaxSISIDs)}}}
+	}
+
+	return results, nil
+}
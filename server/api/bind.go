@@ -0,0 +1,304 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-playground/validator/v10"
+)
+
+// Request structs describe the parameters of a handler with struct tags:
+//
+//	type getCoursesRequest struct {
+//		AccountID  int    `path:"account_id" validate:"required,gt=0"`
+//		SearchTerm string `query:"search_term" validate:"omitempty,min=2"`
+//	}
+//
+// "path" fields are read from chi URL params and "query" fields from the
+// query string, including those of embedded structs. Slice fields accept
+// repeated keys (ids=1&ids=2), the Canvas style "ids[]" keys and comma
+// separated values (ids=1,2).
+//
+// Request bodies are plain JSON structs bound with bindJSON.
+
+const (
+	pathTag  = "path"
+	queryTag = "query"
+
+	// Date format sent by the web client, e.g. "Mon Jan 2 2006".
+	clientDateLayout = "Mon Jan 2 2006"
+)
+
+var validate = newValidator()
+
+type fieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+type bindError struct {
+	Fields []fieldError
+}
+
+func (e *bindError) Error() string {
+	messages := make([]string, 0, len(e.Fields))
+
+	for _, f := range e.Fields {
+		messages = append(messages, fmt.Sprintf("%s %s", f.Field, f.Message))
+	}
+
+	return fmt.Sprintf("invalid request: %s", strings.Join(messages, "; "))
+}
+
+func newValidator() *validator.Validate {
+	v := validator.New(validator.WithRequiredStructEnabled())
+
+	v.RegisterTagNameFunc(func(f reflect.StructField) string {
+		if name, ok := paramName(f); ok {
+			return name
+		}
+
+		return f.Name
+	})
+
+	v.RegisterValidation("clientdate", func(fl validator.FieldLevel) bool {
+		_, err := time.Parse(clientDateLayout, fl.Field().String())
+		return err == nil
+	})
+
+	return v
+}
+
+func paramName(f reflect.StructField) (string, bool) {
+	if name := f.Tag.Get(pathTag); name != "" {
+		return name, true
+	}
+
+	if name := f.Tag.Get(queryTag); name != "" {
+		return name, true
+	}
+
+	if name, _, _ := strings.Cut(f.Tag.Get("json"), ","); name != "" && name != "-" {
+		return name, true
+	}
+
+	return "", false
+}
+
+// bind fills dst, a pointer to a request struct, from the path and query
+// parameters of r and validates it. The returned error is a *bindError.
+func bind(r *http.Request, dst any) error {
+	v := reflect.ValueOf(dst)
+	if v.Kind() != reflect.Pointer || v.Elem().Kind() != reflect.Struct {
+		panic(fmt.Sprintf("bind: expected pointer to struct, got %T", dst))
+	}
+
+	v = v.Elem()
+	t := v.Type()
+
+	query := r.URL.Query()
+
+	fields := make([]fieldError, 0)
+	failed := make(map[string]bool)
+
+	// VisibleFields includes the fields of embedded structs, so parameters
+	// shared by several requests can be declared once
+	for _, f := range reflect.VisibleFields(t) {
+		var values []string
+
+		name := f.Tag.Get(pathTag)
+		if name != "" {
+			if value := chi.URLParam(r, name); value != "" {
+				values = []string{value}
+			}
+		} else if name = f.Tag.Get(queryTag); name != "" {
+			values = queryValues(query, name)
+		} else {
+			continue
+		}
+
+		if len(values) == 0 {
+			continue
+		}
+
+		if err := setField(v.FieldByIndex(f.Index), values); err != nil {
+			fields = append(fields, fieldError{Field: name, Message: err.Error()})
+			failed[name] = true
+		}
+	}
+
+	return validateRequest(dst, fields, failed)
+}
+
+// bindJSON decodes the JSON body of r into dst, a pointer to a struct, and
+// validates it. The returned error is a *bindError.
+func bindJSON(r *http.Request, dst any) error {
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+
+	if err := decoder.Decode(dst); err != nil {
+		return &bindError{Fields: []fieldError{{Field: "body", Message: fmt.Sprintf("must be valid JSON: %v", err)}}}
+	}
+
+	return validateRequest(dst, make([]fieldError, 0), nil)
+}
+
+// validateRequest appends the validation errors of dst to fields, skipping
+// the fields that already failed to bind.
+func validateRequest(dst any, fields []fieldError, failed map[string]bool) error {
+	if err := validate.Struct(dst); err != nil {
+		var verrs validator.ValidationErrors
+		if !errors.As(err, &verrs) {
+			return err
+		}
+
+		t := reflect.TypeOf(dst).Elem()
+
+		// embedded struct names are not part of the parameter names
+		prefixes := []string{t.Name() + "."}
+
+		for _, f := range reflect.VisibleFields(t) {
+			if f.Anonymous {
+				prefixes = append(prefixes, f.Name+".")
+			}
+		}
+
+		for _, ve := range verrs {
+			field := ve.Namespace()
+
+			for _, prefix := range prefixes {
+				field = strings.TrimPrefix(field, prefix)
+			}
+
+			if failed[field] {
+				continue
+			}
+
+			fields = append(fields, fieldError{Field: field, Message: validationMessage(ve)})
+		}
+	}
+
+	if len(fields) > 0 {
+		return &bindError{Fields: fields}
+	}
+
+	return nil
+}
+
+func queryValues(query map[string][]string, name string) []string {
+	values := make([]string, 0)
+
+	for _, value := range append(query[name], query[name+"[]"]...) {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+
+	return values
+}
+
+func setField(field reflect.Value, values []string) error {
+	if field.Kind() != reflect.Slice {
+		return setValue(field, values[0])
+	}
+
+	items := make([]string, 0, len(values))
+
+	for _, value := range values {
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+	}
+
+	slice := reflect.MakeSlice(field.Type(), len(items), len(items))
+
+	for i, item := range items {
+		if err := setValue(slice.Index(i), item); err != nil {
+			return err
+		}
+	}
+
+	field.Set(slice)
+
+	return nil
+}
+
+func setValue(field reflect.Value, value string) error {
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(value)
+	case reflect.Int, reflect.Int64:
+		n, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return fmt.Errorf("must be an integer")
+		}
+
+		field.SetInt(n)
+	case reflect.Float64:
+		n, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return fmt.Errorf("must be a number")
+		}
+
+		field.SetFloat(n)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("must be true or false")
+		}
+
+		field.SetBool(b)
+	default:
+		panic(fmt.Sprintf("bind: unsupported field kind %s", field.Kind()))
+	}
+
+	return nil
+}
+
+func validationMessage(ve validator.FieldError) string {
+	switch ve.Tag() {
+	case "required":
+		return "is required"
+	case "gt":
+		return fmt.Sprintf("must be greater than %s", ve.Param())
+	case "gte":
+		return fmt.Sprintf("must be at least %s", ve.Param())
+	case "lte":
+		return fmt.Sprintf("must be at most %s", ve.Param())
+	case "min":
+		if ve.Kind() == reflect.Slice {
+			return fmt.Sprintf("must contain at least %s items", ve.Param())
+		}
+
+		if ve.Kind() == reflect.String {
+			return fmt.Sprintf("must be at least %s characters", ve.Param())
+		}
+
+		return fmt.Sprintf("must be at least %s", ve.Param())
+	case "max":
+		if ve.Kind() == reflect.Slice {
+			return fmt.Sprintf("must contain at most %s items", ve.Param())
+		}
+
+		return fmt.Sprintf("must be at most %s", ve.Param())
+	case "oneof":
+		return fmt.Sprintf("must be one of: %s", strings.ReplaceAll(ve.Param(), " ", ", "))
+	case "uuid":
+		return "must be a UUID"
+	case "datetime":
+		return fmt.Sprintf("must be a date and time like %s", ve.Param())
+	case "clientdate":
+		return fmt.Sprintf(`must be a date like "%s"`, clientDateLayout)
+	default:
+		return fmt.Sprintf("failed on the '%s' rule", ve.Tag())
+	}
+}
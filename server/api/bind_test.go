@@ -0,0 +1,242 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type bindTestTerm struct {
+	TermID int `query:"term_id" validate:"gte=0"`
+}
+
+type bindTestRequest struct {
+	AccountID  int      `path:"account_id" validate:"required,gt=0"`
+	SearchTerm string   `query:"search_term" validate:"omitempty,min=2"`
+	IDs        []int    `query:"ids" validate:"dive,gt=0"`
+	States     []string `query:"state" validate:"dive,oneof=active completed"`
+	Published  bool     `query:"published"`
+	Score      float64  `query:"score"`
+	Date       string   `query:"date" validate:"omitempty,clientdate"`
+	bindTestTerm
+}
+
+type bindTestBody struct {
+	Name string `json:"name" validate:"required,max=5"`
+	IDs  []int  `json:"ids" validate:"dive,gt=0"`
+}
+
+// newBindRequest is a request of target with the url params of its route.
+func newBindRequest(target string, params map[string]string, body string) *http.Request {
+	r := httptest.NewRequest(http.MethodPost, target, strings.NewReader(body))
+
+	rctx := chi.NewRouteContext()
+	for key, value := range params {
+		rctx.URLParams.Add(key, value)
+	}
+
+	return r.WithContext(context.WithValue(r.Context(), chi.RouteCtxKey, rctx))
+}
+
+// bindFields returns the fields of a *bindError, nil without an error.
+func bindFields(t *testing.T, err error) []fieldError {
+	t.Helper()
+
+	if err == nil {
+		return nil
+	}
+
+	var bindErr *bindError
+	if !errors.As(err, &bindErr) {
+		t.Fatalf("error = %v, want a *bindError", err)
+	}
+
+	return bindErr.Fields
+}
+
+func TestBind(t *testing.T) {
+	account := map[string]string{"account_id": "12"}
+
+	tests := []struct {
+		name       string
+		target     string
+		params     map[string]string
+		want       bindTestRequest
+		wantFields []fieldError
+	}{
+		{
+			name:   "path only",
+			target: "/accounts/12/courses",
+			params: account,
+			want:   bindTestRequest{AccountID: 12},
+		},
+		{
+			name:   "every kind",
+			target: "/?search_term=fitness&published=true&score=1.5&date=Mon+Oct+19+2026&term_id=3&state=active",
+			params: account,
+			want: bindTestRequest{
+				AccountID:    12,
+				SearchTerm:   "fitness",
+				States:       []string{"active"},
+				Published:    true,
+				Score:        1.5,
+				Date:         "Mon Oct 19 2026",
+				bindTestTerm: bindTestTerm{TermID: 3},
+			},
+		},
+		{
+			name:   "repeated, canvas style and comma separated slices",
+			target: "/?ids=1&ids=2&ids[]=3&ids=4,5,&state=active,completed",
+			params: account,
+			want:   bindTestRequest{AccountID: 12, IDs: []int{1, 2, 4, 5, 3}, States: []string{"active", "completed"}},
+		},
+		{
+			name:   "blank values are unset",
+			target: "/?search_term=+&ids=",
+			params: account,
+			want:   bindTestRequest{AccountID: 12},
+		},
+		{
+			name:       "missing path param",
+			target:     "/",
+			wantFields: []fieldError{{Field: "account_id", Message: "is required"}},
+		},
+		{
+			name:   "not an integer",
+			target: "/",
+			params: map[string]string{"account_id": "abc"},
+			// not reported as required too
+			wantFields: []fieldError{{Field: "account_id", Message: "must be an integer"}},
+		},
+		{
+			name:   "parse errors",
+			target: "/?ids=1,x&published=yes&score=high",
+			params: account,
+			wantFields: []fieldError{
+				{Field: "ids", Message: "must be an integer"},
+				{Field: "published", Message: "must be true or false"},
+				{Field: "score", Message: "must be a number"},
+			},
+		},
+		{
+			name:   "validation errors",
+			target: "/?search_term=f&ids=1,0&state=deleted&date=2026-10-19&term_id=-1",
+			params: map[string]string{"account_id": "-4"},
+			wantFields: []fieldError{
+				{Field: "account_id", Message: "must be greater than 0"},
+				{Field: "search_term", Message: "must be at least 2 characters"},
+				{Field: "ids[1]", Message: "must be greater than 0"},
+				{Field: "state[0]", Message: "must be one of: active, completed"},
+				{Field: "date", Message: `must be a date like "Mon Jan 2 2006"`},
+				{Field: "term_id", Message: "must be at least 0"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got bindTestRequest
+
+			fields := bindFields(t, bind(newBindRequest(tt.target, tt.params, ""), &got))
+
+			if !reflect.DeepEqual(fields, tt.wantFields) {
+				t.Fatalf("bind() fields = %+v, want %+v", fields, tt.wantFields)
+			}
+
+			if tt.wantFields == nil && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("bind() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBindPanicsWithoutStructPointer(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("bind() with a struct value did not panic")
+		}
+	}()
+
+	bind(newBindRequest("/", nil, ""), bindTestRequest{})
+}
+
+func TestBindJSON(t *testing.T) {
+	tests := []struct {
+		name       string
+		body       string
+		want       bindTestBody
+		wantFields []fieldError
+	}{
+		{
+			name: "valid",
+			body: `{"name": "T3", "ids": [1, 2]}`,
+			want: bindTestBody{Name: "T3", IDs: []int{1, 2}},
+		},
+		{
+			name:       "malformed",
+			body:       `{"name": "T3",`,
+			wantFields: []fieldError{{Field: "body", Message: "must be valid JSON: unexpected EOF"}},
+		},
+		{
+			name:       "empty",
+			body:       ``,
+			wantFields: []fieldError{{Field: "body", Message: "must be valid JSON: EOF"}},
+		},
+		{
+			name:       "unknown field",
+			body:       `{"name": "T3", "id": 1}`,
+			wantFields: []fieldError{{Field: "body", Message: `must be valid JSON: json: unknown field "id"`}},
+		},
+		{
+			name:       "wrong type",
+			body:       `{"name": 3}`,
+			wantFields: []fieldError{{Field: "body", Message: "must be valid JSON: json: cannot unmarshal number into Go struct field bindTestBody.name of type string"}},
+		},
+		{
+			name: "validation errors",
+			body: `{"ids": [1, -2]}`,
+			wantFields: []fieldError{
+				{Field: "name", Message: "is required"},
+				{Field: "ids[1]", Message: "must be greater than 0"},
+			},
+		},
+		{
+			name:       "too long",
+			body:       `{"name": "Term 3 2026"}`,
+			wantFields: []fieldError{{Field: "name", Message: "must be at most 5"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got bindTestBody
+
+			fields := bindFields(t, bindJSON(newBindRequest("/", nil, tt.body), &got))
+
+			if !reflect.DeepEqual(fields, tt.wantFields) {
+				t.Fatalf("bindJSON() fields = %+v, want %+v", fields, tt.wantFields)
+			}
+
+			if tt.wantFields == nil && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("bindJSON() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBindErrorMessage(t *testing.T) {
+	err := &bindError{Fields: []fieldError{
+		{Field: "account_id", Message: "is required"},
+		{Field: "ids[0]", Message: "must be greater than 0"},
+	}}
+
+	if got, want := err.Error(), "invalid request: account_id is required; ids[0] must be greater than 0"; got != want {
+		t.Errorf("Error() = %q, want %q", got, want)
+	}
+}
@@ -3,11 +3,10 @@ package api
 import (
 	"canvas-admin/canvas"
 	"encoding/json"
-	"fmt"
 	"net/http"
-	"strconv"
+	"sort"
+	"strings"
 
-	"github.com/go-chi/chi/v5"
 	"github.com/guregu/null/v5"
 )
 
@@ -22,15 +21,24 @@ type GradingStandardCourse struct {
 	EndAt             null.String `json:"end_at"`
 }
 
+type accountRequest struct {
+	AccountID int `path:"account_id" validate:"required,gt=0"`
+}
+
 func (c *APIController) GetCoursesByAccountID(w http.ResponseWriter, r *http.Request) (int, error) {
-	accountID, err := strconv.Atoi(chi.URLParam(r, "account_id"))
+	var req accountReportRequest
+	if err := bind(r, &req); err != nil {
+		return http.StatusBadRequest, err
+	}
+
+	filter, err := req.coursesFilter()
 	if err != nil {
-		return http.StatusBadRequest, fmt.Errorf("invalid account id")
+		return http.StatusBadRequest, err
 	}
 
 	types := []canvas.CourseEnrollmentType{canvas.StudentCourseEnrollment}
 
-	results, code, err := c.canvasClient.GetCoursesByAccountID(r.Context(), accountID, "", types)
+	results, code, err := c.canvasClient.GetCoursesByAccountID(r.Context(), req.AccountID, "", types, filter)
 	if err != nil {
 		return code, err
 	}
@@ -41,3 +49,173 @@ func (c *APIController) GetCoursesByAccountID(w http.ResponseWriter, r *http.Req
 
 	return http.StatusOK, nil
 }
+
+type CourseSearchResults struct {
+	Total   null.Int        `json:"total"` // courses matching the search over all pages, null when Canvas does not count them
+	Page    int             `json:"page"`
+	PerPage int             `json:"per_page"`
+	Courses []canvas.Course `json:"courses"`
+}
+
+type courseSearchRequest struct {
+	AccountID         int      `path:"account_id" validate:"required,gt=0"`
+	SearchTerm        string   `query:"search_term" validate:"omitempty,min=2"` // course name, code or SIS id
+	States            []string `query:"state" validate:"dive,oneof=created claimed available completed deleted"`
+	Published         string   `query:"published" validate:"omitempty,oneof=true false"`
+	Teacher           string   `query:"teacher" validate:"omitempty,min=2"`
+	SISCourseIDPrefix string   `query:"sis_course_id_prefix"`
+	Sort              string   `query:"sort" validate:"oneof=course_name sis_course_id teacher account_name start_at"`
+	Order             string   `query:"order" validate:"oneof=asc desc"`
+	Page              int      `query:"page" validate:"gte=1"`
+	PerPage           int      `query:"per_page" validate:"gte=1,lte=100"`
+	termFilter
+}
+
+// courseSearchDescription documents the searches Canvas cannot page itself.
+const courseSearchDescription = `Canvas filters, sorts and pages the courses, except in these cases where all the matching courses are listed, then filtered, sorted and paged here:
+- sort=start_at, Canvas cannot sort by start date.
+- sis_course_id_prefix is set, Canvas only searches SIS ids with search_term.
+- Both search_term and teacher are set. Canvas searches the courses by search_term, then only the courses with a teacher whose name contains teacher, case insensitively, are kept. With teacher alone Canvas searches the teachers.`
+
+// SearchCoursesByAccountID searches the courses of an account and its
+// sub-accounts. Canvas filters, sorts and pages them when it can, see
+// courseSearchDescription.
+func (c *APIController) SearchCoursesByAccountID(w http.ResponseWriter, r *http.Request) (int, error) {
+	req := courseSearchRequest{Sort: string(canvas.CourseNameSort), Order: "asc", Page: 1, PerPage: 25}
+	if err := bind(r, &req); err != nil {
+		return http.StatusBadRequest, err
+	}
+
+	filter, err := req.coursesFilter()
+	if err != nil {
+		return http.StatusBadRequest, err
+	}
+
+	for _, state := range req.States {
+		filter.States = append(filter.States, canvas.CourseWorkflowState(state))
+	}
+
+	if req.Published != "" {
+		filter.Published = null.BoolFrom(req.Published == "true")
+	}
+
+	filter.IncludeTeachers = true
+
+	searchTerm := req.SearchTerm
+
+	// Canvas searches either the courses or their teachers, the teacher is
+	// matched here when both are set
+	if searchTerm == "" && req.Teacher != "" {
+		searchTerm = req.Teacher
+		filter.SearchByTeacher = true
+	}
+
+	results := CourseSearchResults{
+		Page:    req.Page,
+		PerPage: req.PerPage,
+		Courses: make([]canvas.Course, 0),
+	}
+
+	inMemory := req.Sort == "start_at" || req.SISCourseIDPrefix != "" || (req.SearchTerm != "" && req.Teacher != "")
+
+	if !inMemory {
+		page, code, err := c.canvasClient.GetCoursesPageByAccountID(r.Context(), req.AccountID, searchTerm, filter, canvas.CoursesSort(req.Sort), req.Order == "desc", req.Page, req.PerPage)
+		if err != nil {
+			return code, err
+		}
+
+		results.Total = page.Total
+		results.Courses = page.Courses
+
+		if err := json.NewEncoder(w).Encode(results); err != nil {
+			return http.StatusInternalServerError, err
+		}
+
+		return http.StatusOK, nil
+	}
+
+	courses, code, err := c.canvasClient.GetCoursesByAccountID(r.Context(), req.AccountID, searchTerm, nil, filter)
+	if err != nil {
+		return code, err
+	}
+
+	matches := make([]canvas.Course, 0, len(courses))
+
+	for _, course := range courses {
+		if req.SISCourseIDPrefix != "" && !strings.HasPrefix(strings.ToLower(course.SISCourseID.String), strings.ToLower(req.SISCourseIDPrefix)) {
+			continue
+		}
+
+		if req.Teacher != "" && !courseHasTeacher(course, req.Teacher) {
+			continue
+		}
+
+		matches = append(matches, course)
+	}
+
+	sortCourses(matches, req.Sort, req.Order == "desc")
+
+	results.Total = null.IntFrom(int64(len(matches)))
+
+	if start := (req.Page - 1) * req.PerPage; start < len(matches) {
+		results.Courses = matches[start:min(start+req.PerPage, len(matches))]
+	}
+
+	if err := json.NewEncoder(w).Encode(results); err != nil {
+		return http.StatusInternalServerError, err
+	}
+
+	return http.StatusOK, nil
+}
+
+func courseHasTeacher(course canvas.Course, name string) bool {
+	name = strings.ToLower(name)
+
+	for _, teacher := range course.Teachers {
+		if strings.Contains(strings.ToLower(teacher.DisplayName), name) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// sortCourses sorts courses by a search sort key, ties in id order. Courses
+// without the key, e.g. no SIS id, go last in both orders.
+func sortCourses(courses []canvas.Course, by string, desc bool) {
+	key := func(course canvas.Course) string {
+		switch canvas.CoursesSort(by) {
+		case canvas.SISCourseIDSort:
+			return course.SISCourseID.String
+		case canvas.TeacherSort:
+			if len(course.Teachers) > 0 {
+				return course.Teachers[0].DisplayName
+			}
+
+			return ""
+		case canvas.AccountNameSort:
+			return course.Account.Name
+		case "start_at":
+			return course.StartAt.String
+		}
+
+		return course.Name
+	}
+
+	sort.SliceStable(courses, func(i, j int) bool {
+		a, b := strings.ToLower(key(courses[i])), strings.ToLower(key(courses[j]))
+
+		switch {
+		case a == b:
+			return courses[i].ID < courses[j].ID
+		case a == "":
+			return false
+		case b == "":
+			return true
+		case desc:
+			return a > b
+		}
+
+		return a < b
+	})
+}
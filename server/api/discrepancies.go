@@ -0,0 +1,297 @@
+package api
+
+import (
+	"canvas-admin/canvas"
+	"context"
+	"fmt"
+	"math"
+	"net/http"
+	"slices"
+	"sort"
+	"strconv"
+	"time"
+)
+
+type DiscrepancyCode string
+
+const (
+	ScoreExceedsPointsDiscrepancy      DiscrepancyCode = "score_exceeds_points"
+	ExcusedWithScoreDiscrepancy        DiscrepancyCode = "excused_with_score"
+	GradedWithoutSubmissionDiscrepancy DiscrepancyCode = "graded_without_submission"
+	SubmittedAfterLockDiscrepancy      DiscrepancyCode = "submitted_after_lock"
+	ZeroPointsPossibleDiscrepancy      DiscrepancyCode = "zero_points_possible"
+	CurrentFinalMismatchDiscrepancy    DiscrepancyCode = "current_final_mismatch"
+	GradingStandardMismatchDiscrepancy DiscrepancyCode = "grading_standard_mismatch"
+)
+
+// DiscrepancyError flags the rows with discrepancies, as before the rules.
+const DiscrepancyError string = "ERROR"
+
+type Discrepancy struct {
+	Code    DiscrepancyCode `json:"code"`
+	Message string          `json:"message"`
+}
+
+// assignmentRow is the result of a student for an assignment. Submission is the
+// zero value when Canvas has no submission record.
+type assignmentRow struct {
+	data       canvas.AssignmentData
+	submission canvas.Submission
+}
+
+// courseRow is the total of a student for a course. gradingStandard is nil
+// when the course has none. graded is true when every assignment of the
+// student has a score or is excused.
+type courseRow struct {
+	grades          canvas.Grades
+	gradingStandard *canvas.GradingStandard
+	graded          bool
+}
+
+// assignmentRule reports at most one discrepancy of a result row.
+type assignmentRule func(row assignmentRow) (Discrepancy, bool)
+
+// courseRule reports at most one discrepancy of a course total row.
+type courseRule func(row courseRow) (Discrepancy, bool)
+
+// assignmentRules and courseRules run in order, add rules to extend the checks.
+var (
+	assignmentRules = []assignmentRule{
+		scoreExceedsPointsRule,
+		excusedWithScoreRule,
+		gradedWithoutSubmissionRule,
+		submittedAfterLockRule,
+		zeroPointsPossibleRule,
+	}
+
+	courseRules = []courseRule{
+		currentFinalMismatchRule,
+		gradingStandardMismatchRule,
+	}
+)
+
+func checkAssignmentRow(row assignmentRow) []Discrepancy {
+	results := make([]Discrepancy, 0)
+
+	for _, rule := range assignmentRules {
+		if d, ok := rule(row); ok {
+			results = append(results, d)
+		}
+	}
+
+	return results
+}
+
+func checkCourseRow(row courseRow) []Discrepancy {
+	results := make([]Discrepancy, 0)
+
+	for _, rule := range courseRules {
+		if d, ok := rule(row); ok {
+			results = append(results, d)
+		}
+	}
+
+	return results
+}
+
+// discrepancyFlag is the discrepancy column of the exports, "ERROR" when a
+// rule reported a discrepancy. The codes are only in the discrepancies.
+func discrepancyFlag(discrepancies []Discrepancy) string {
+	if len(discrepancies) == 0 {
+		return ""
+	}
+
+	return DiscrepancyError
+}
+
+// score returns the score of the submission, or of the analytics when there is
+// no submission record.
+func (row assignmentRow) score() float64 {
+	if row.submission.Score.Valid {
+		return row.submission.Score.Float64
+	}
+
+	return row.data.Submission.Score.Float64
+}
+
+func (row assignmentRow) scored() bool {
+	return row.submission.Score.Valid || row.data.Submission.Score.Valid
+}
+
+func scoreExceedsPointsRule(row assignmentRow) (Discrepancy, bool) {
+	if !row.scored() || row.score() <= row.data.PointsPossible.Float64 {
+		return Discrepancy{}, false
+	}
+
+	return Discrepancy{
+		Code:    ScoreExceedsPointsDiscrepancy,
+		Message: fmt.Sprintf("score %s is more than the %s points possible", formatPoints(row.score()), formatPoints(row.data.PointsPossible.Float64)),
+	}, true
+}
+
+func excusedWithScoreRule(row assignmentRow) (Discrepancy, bool) {
+	if !row.submission.Excused.Bool || !row.scored() {
+		return Discrepancy{}, false
+	}
+
+	return Discrepancy{
+		Code:    ExcusedWithScoreDiscrepancy,
+		Message: fmt.Sprintf("excused but scored %s", formatPoints(row.score())),
+	}, true
+}
+
+// gradedWithoutSubmissionRule leaves out assignments marked without an online
+// submission, e.g. on paper.
+func gradedWithoutSubmissionRule(row assignmentRow) (Discrepancy, bool) {
+	if row.submission.WorkflowState != string(canvas.GradedSubmissionWorkflowState) || row.submission.Excused.Bool || !row.scored() {
+		return Discrepancy{}, false
+	}
+
+	if row.submission.SubmittedAt.Valid && row.submission.SubmittedAt.String != "" {
+		return Discrepancy{}, false
+	}
+
+	for _, t := range row.submission.Assignment.SubmissionTypes {
+		if t == "none" || t == "on_paper" || t == "external_tool" || t == "not_graded" {
+			return Discrepancy{}, false
+		}
+	}
+
+	return Discrepancy{
+		Code:    GradedWithoutSubmissionDiscrepancy,
+		Message: "graded but the student has not submitted",
+	}, true
+}
+
+// submittedAfterLockRule compares with the lock date of the assignment for
+// everyone, an override may have extended it for the student.
+func submittedAfterLockRule(row assignmentRow) (Discrepancy, bool) {
+	if !row.submission.Assignment.LockAt.Valid || !row.submission.SubmittedAt.Valid {
+		return Discrepancy{}, false
+	}
+
+	lockAt, err := time.Parse(time.RFC3339, row.submission.Assignment.LockAt.String)
+	if err != nil {
+		return Discrepancy{}, false
+	}
+
+	submittedAt, err := time.Parse(time.RFC3339, row.submission.SubmittedAt.String)
+	if err != nil || !submittedAt.After(lockAt) {
+		return Discrepancy{}, false
+	}
+
+	return Discrepancy{
+		Code:    SubmittedAfterLockDiscrepancy,
+		Message: fmt.Sprintf("submitted %s after the assignment locked %s", row.submission.SubmittedAt.String, row.submission.Assignment.LockAt.String),
+	}, true
+}
+
+func zeroPointsPossibleRule(row assignmentRow) (Discrepancy, bool) {
+	if row.data.PointsPossible.Float64 > 0 || !row.scored() || row.submission.Excused.Bool {
+		return Discrepancy{}, false
+	}
+
+	if row.submission.Assignment.GradingType == "not_graded" {
+		return Discrepancy{}, false
+	}
+
+	return Discrepancy{
+		Code:    ZeroPointsPossibleDiscrepancy,
+		Message: "graded assignment has no points possible",
+	}, true
+}
+
+// currentFinalMismatchRule compares the current score, of the graded
+// assignments, with the final score, counting ungraded ones as zero. They
+// differ while assignments are ungraded, so only graded students are checked.
+func currentFinalMismatchRule(row courseRow) (Discrepancy, bool) {
+	if !row.graded || !row.grades.CurrentScore.Valid || !row.grades.FinalScore.Valid {
+		return Discrepancy{}, false
+	}
+
+	if math.Abs(row.grades.CurrentScore.Float64-row.grades.FinalScore.Float64) < 0.01 && row.grades.CurrentGrade == row.grades.FinalGrade {
+		return Discrepancy{}, false
+	}
+
+	return Discrepancy{
+		Code: CurrentFinalMismatchDiscrepancy,
+		Message: fmt.Sprintf("current score %s (%s) does not match final score %s (%s) with every assignment graded",
+			formatPoints(row.grades.CurrentScore.Float64), row.grades.CurrentGrade.String,
+			formatPoints(row.grades.FinalScore.Float64), row.grades.FinalGrade.String),
+	}, true
+}
+
+func gradingStandardMismatchRule(row courseRow) (Discrepancy, bool) {
+	if row.gradingStandard == nil || len(row.gradingStandard.GradingScheme) == 0 {
+		return Discrepancy{}, false
+	}
+
+	if !row.grades.CurrentScore.Valid || !row.grades.CurrentGrade.Valid {
+		return Discrepancy{}, false
+	}
+
+	expected := gradingSchemeGrade(row.gradingStandard.GradingScheme, row.grades.CurrentScore.Float64)
+
+	if expected == row.grades.CurrentGrade.String {
+		return Discrepancy{}, false
+	}
+
+	return Discrepancy{
+		Code: GradingStandardMismatchDiscrepancy,
+		Message: fmt.Sprintf("current grade %s does not match %s for %s%% in %s",
+			row.grades.CurrentGrade.String, expected, formatPoints(row.grades.CurrentScore.Float64), row.gradingStandard.Title),
+	}, true
+}
+
+// courseGradingStandard returns the grading standard of a course, nil when it
+// has none or it is not visible, caching the standards by id as courses often
+// share their account's.
+func (c *APIController) courseGradingStandard(ctx context.Context, cache map[int]*canvas.GradingStandard, course canvas.Course) (*canvas.GradingStandard, int, error) {
+	if !course.GradingStandardID.Valid {
+		return nil, http.StatusOK, nil
+	}
+
+	id := int(course.GradingStandardID.Int64)
+
+	if gradingStandard, ok := cache[id]; ok {
+		return gradingStandard, http.StatusOK, nil
+	}
+
+	gradingStandard, code, err := c.canvasClient.GetGradingStandardByID(ctx, canvas.GradingStandardCourseContext, course.ID, id)
+	if code == http.StatusNotFound {
+		cache[id] = nil
+		return nil, http.StatusOK, nil
+	}
+	if err != nil {
+		return nil, code, err
+	}
+
+	cache[id] = &gradingStandard
+
+	return &gradingStandard, http.StatusOK, nil
+}
+
+// gradingSchemeGrade returns the name of the highest band a percentage
+// reaches. Values of the scheme are the lower bounds between 0 and 1.
+func gradingSchemeGrade(scheme []canvas.GradingScheme, percentage float64) string {
+	bands := slices.Clone(scheme)
+
+	sort.SliceStable(bands, func(i, j int) bool {
+		return bands[i].Value > bands[j].Value
+	})
+
+	// Canvas compares the score rounded to 2 decimals
+	percentage = math.Round(percentage*100) / 100
+
+	for _, band := range bands {
+		if percentage/100 >= float64(band.Value)-1e-6 {
+			return band.Name
+		}
+	}
+
+	return bands[len(bands)-1].Name
+}
+
+func formatPoints(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
@@ -0,0 +1,286 @@
+package api
+
+import (
+	"canvas-admin/canvas"
+	"reflect"
+	"testing"
+
+	"github.com/guregu/null/v5"
+)
+
+func discrepancyCodesOf(discrepancies []Discrepancy) []DiscrepancyCode {
+	codes := make([]DiscrepancyCode, 0, len(discrepancies))
+
+	for _, d := range discrepancies {
+		codes = append(codes, d.Code)
+	}
+
+	return codes
+}
+
+func TestCheckAssignmentRow(t *testing.T) {
+	// row is an online assignment of 10 points with the given submission
+	row := func(edit func(s *canvas.Submission)) assignmentRow {
+		r := assignmentRow{data: canvas.AssignmentData{AssignmentID: 1, PointsPossible: null.FloatFrom(10)}}
+
+		r.submission.Assignment.SubmissionTypes = []string{"online_upload"}
+		r.submission.Assignment.GradingType = "points"
+
+		if edit != nil {
+			edit(&r.submission)
+		}
+
+		return r
+	}
+
+	graded := func(score float64) func(s *canvas.Submission) {
+		return func(s *canvas.Submission) {
+			s.WorkflowState = string(canvas.GradedSubmissionWorkflowState)
+			s.Score = null.FloatFrom(score)
+			s.SubmittedAt = null.StringFrom("2026-10-01T10:00:00Z")
+		}
+	}
+
+	tests := []struct {
+		name string
+		row  assignmentRow
+		want []DiscrepancyCode
+	}{
+		{
+			name: "no submission",
+			row:  row(nil),
+			want: []DiscrepancyCode{},
+		},
+		{
+			name: "score of the points possible",
+			row:  row(graded(10)),
+			want: []DiscrepancyCode{},
+		},
+		{
+			name: "score above the points possible",
+			row:  row(graded(10.5)),
+			want: []DiscrepancyCode{ScoreExceedsPointsDiscrepancy},
+		},
+		{
+			name: "score of the analytics without a submission record",
+			row: assignmentRow{data: canvas.AssignmentData{
+				PointsPossible: null.FloatFrom(10),
+				Submission:     canvas.AssignmentDataSubmission{Score: null.FloatFrom(12)},
+			}},
+			want: []DiscrepancyCode{ScoreExceedsPointsDiscrepancy},
+		},
+		{
+			name: "excused with a score",
+			row: row(func(s *canvas.Submission) {
+				graded(8)(s)
+				s.Excused = null.BoolFrom(true)
+			}),
+			want: []DiscrepancyCode{ExcusedWithScoreDiscrepancy},
+		},
+		{
+			name: "excused without a score",
+			row:  row(func(s *canvas.Submission) { s.Excused = null.BoolFrom(true) }),
+			want: []DiscrepancyCode{},
+		},
+		{
+			name: "graded without submitting",
+			row: row(func(s *canvas.Submission) {
+				graded(8)(s)
+				s.SubmittedAt = null.String{}
+			}),
+			want: []DiscrepancyCode{GradedWithoutSubmissionDiscrepancy},
+		},
+		{
+			name: "graded on paper",
+			row: row(func(s *canvas.Submission) {
+				graded(8)(s)
+				s.SubmittedAt = null.String{}
+				s.Assignment.SubmissionTypes = []string{"on_paper"}
+			}),
+			want: []DiscrepancyCode{},
+		},
+		{
+			name: "submitted after the lock",
+			row: row(func(s *canvas.Submission) {
+				graded(8)(s)
+				s.Assignment.LockAt = null.StringFrom("2026-10-01T09:59:59Z")
+			}),
+			want: []DiscrepancyCode{SubmittedAfterLockDiscrepancy},
+		},
+		{
+			name: "submitted at the lock",
+			row: row(func(s *canvas.Submission) {
+				graded(8)(s)
+				s.Assignment.LockAt = null.StringFrom("2026-10-01T10:00:00Z")
+			}),
+			want: []DiscrepancyCode{},
+		},
+		{
+			name: "unparsable lock date",
+			row: row(func(s *canvas.Submission) {
+				graded(8)(s)
+				s.Assignment.LockAt = null.StringFrom("soon")
+			}),
+			want: []DiscrepancyCode{},
+		},
+		{
+			name: "scored without points possible",
+			row: func() assignmentRow {
+				r := row(graded(0))
+				r.data.PointsPossible = null.FloatFrom(0)
+				return r
+			}(),
+			want: []DiscrepancyCode{ZeroPointsPossibleDiscrepancy},
+		},
+		{
+			name: "not graded without points possible",
+			row: func() assignmentRow {
+				r := row(graded(0))
+				r.data.PointsPossible = null.Float{}
+				r.submission.Assignment.GradingType = "not_graded"
+				return r
+			}(),
+			want: []DiscrepancyCode{},
+		},
+		{
+			name: "several rules in order",
+			row: row(func(s *canvas.Submission) {
+				graded(11)(s)
+				s.SubmittedAt = null.StringFrom("2026-10-02T10:00:00Z")
+				s.Excused = null.BoolFrom(true)
+				s.Assignment.LockAt = null.StringFrom("2026-10-01T10:00:00Z")
+			}),
+			want: []DiscrepancyCode{ScoreExceedsPointsDiscrepancy, ExcusedWithScoreDiscrepancy, SubmittedAfterLockDiscrepancy},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := discrepancyCodesOf(checkAssignmentRow(tt.row)); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("checkAssignmentRow() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckCourseRow(t *testing.T) {
+	standard := &canvas.GradingStandard{
+		Title: "Competency",
+		GradingScheme: []canvas.GradingScheme{
+			{Name: "C", Value: 0.5},
+			{Name: "HD", Value: 0.85},
+			{Name: "NYC", Value: 0},
+		},
+	}
+
+	grades := func(current, final float64, currentGrade, finalGrade string) canvas.Grades {
+		return canvas.Grades{
+			CurrentScore: null.FloatFrom(current),
+			FinalScore:   null.FloatFrom(final),
+			CurrentGrade: null.StringFrom(currentGrade),
+			FinalGrade:   null.StringFrom(finalGrade),
+		}
+	}
+
+	tests := []struct {
+		name string
+		row  courseRow
+		want []DiscrepancyCode
+	}{
+		{
+			name: "empty",
+			row:  courseRow{},
+			want: []DiscrepancyCode{},
+		},
+		{
+			name: "ungraded assignments lower the final score",
+			row:  courseRow{grades: grades(80, 40, "C", "NYC")},
+			want: []DiscrepancyCode{},
+		},
+		{
+			name: "every assignment graded",
+			row:  courseRow{grades: grades(80, 80.004, "C", "C"), graded: true},
+			want: []DiscrepancyCode{},
+		},
+		{
+			name: "scores differ with every assignment graded",
+			row:  courseRow{grades: grades(80, 70, "C", "C"), graded: true},
+			want: []DiscrepancyCode{CurrentFinalMismatchDiscrepancy},
+		},
+		{
+			name: "grades differ with every assignment graded",
+			row:  courseRow{grades: grades(80, 80, "C", "HD"), graded: true},
+			want: []DiscrepancyCode{CurrentFinalMismatchDiscrepancy},
+		},
+		{
+			name: "grade of the standard",
+			row:  courseRow{grades: grades(85, 85, "HD", "HD"), gradingStandard: standard},
+			want: []DiscrepancyCode{},
+		},
+		{
+			name: "grade below the standard",
+			row:  courseRow{grades: grades(84.99, 84.99, "HD", "HD"), gradingStandard: standard},
+			want: []DiscrepancyCode{GradingStandardMismatchDiscrepancy},
+		},
+		{
+			name: "standard without a scheme",
+			row:  courseRow{grades: grades(10, 10, "HD", "HD"), gradingStandard: &canvas.GradingStandard{}},
+			want: []DiscrepancyCode{},
+		},
+		{
+			name: "both rules",
+			row:  courseRow{grades: grades(30, 60, "C", "C"), gradingStandard: standard, graded: true},
+			want: []DiscrepancyCode{CurrentFinalMismatchDiscrepancy, GradingStandardMismatchDiscrepancy},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := discrepancyCodesOf(checkCourseRow(tt.row)); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("checkCourseRow() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGradingSchemeGrade(t *testing.T) {
+	scheme := []canvas.GradingScheme{
+		{Name: "P", Value: 0.5},
+		{Name: "D", Value: 0.75},
+		{Name: "F", Value: 0},
+	}
+
+	tests := []struct {
+		name       string
+		scheme     []canvas.GradingScheme
+		percentage float64
+		want       string
+	}{
+		{name: "single band", scheme: []canvas.GradingScheme{{Name: "S", Value: 0}}, percentage: 42, want: "S"},
+		{name: "top band", scheme: scheme, percentage: 100, want: "D"},
+		{name: "lower bound", scheme: scheme, percentage: 75, want: "D"},
+		{name: "below the lower bound", scheme: scheme, percentage: 74.99, want: "P"},
+		{name: "rounded to the lower bound", scheme: scheme, percentage: 74.996, want: "D"},
+		{name: "lowest band", scheme: scheme, percentage: 0, want: "F"},
+		{name: "below every band", scheme: []canvas.GradingScheme{{Name: "P", Value: 0.5}, {Name: "D", Value: 0.75}}, percentage: 10, want: "P"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := gradingSchemeGrade(tt.scheme, tt.percentage); got != tt.want {
+				t.Errorf("gradingSchemeGrade(%v) = %q, want %q", tt.percentage, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDiscrepancyFlag(t *testing.T) {
+	if got := discrepancyFlag([]Discrepancy{}); got != "" {
+		t.Errorf("discrepancyFlag() without discrepancies = %q, want empty", got)
+	}
+
+	got := discrepancyFlag([]Discrepancy{{Code: ScoreExceedsPointsDiscrepancy}, {Code: SubmittedAfterLockDiscrepancy}})
+	if got != DiscrepancyError {
+		t.Errorf("discrepancyFlag() = %q, want %q", got, DiscrepancyError)
+	}
+}
@@ -2,12 +2,10 @@ package api
 
 import (
 	"canvas-admin/canvas"
+	"context"
 	"encoding/json"
-	"fmt"
 	"net/http"
-	"strconv"
 
-	"github.com/go-chi/chi/v5"
 	"github.com/guregu/null/v5"
 )
 
@@ -25,25 +23,17 @@ type EnrollmentResult struct {
 	GradesURL       string      `json:"grades_url"`
 }
 
+type enrollmentResultsByCourseRequest struct {
+	CourseID            int    `path:"course_id" validate:"required,gt=0"`
+	CourseName          string `query:"course_name" validate:"required"`
+	AccountName         string `query:"account_name" validate:"required"`
+	CourseWorkflowState string `query:"course_workflow_state" validate:"required"`
+}
+
 // StudentEnrollment only
 func (c *APIController) GetEnrollmentResultsByCourse(w http.ResponseWriter, r *http.Request) (int, error) {
-	courseName := r.URL.Query().Get("course_name")
-	if courseName == "" {
-		return http.StatusBadRequest, fmt.Errorf("missing course_name query paramater")
-	}
-
-	accountName := r.URL.Query().Get("account_name")
-	if accountName == "" {
-		return http.StatusBadRequest, fmt.Errorf("missing account_name query parameter")
-	}
-
-	courseWorkflowState := r.URL.Query().Get("course_workflow_state")
-	if courseWorkflowState == "" {
-		return http.StatusBadRequest, fmt.Errorf("missing course_workflow_state query parameter")
-	}
-
-	courseID, err := strconv.Atoi(chi.URLParam(r, "course_id"))
-	if err != nil {
+	var req enrollmentResultsByCourseRequest
+	if err := bind(r, &req); err != nil {
 		return http.StatusBadRequest, err
 	}
 
@@ -53,7 +43,7 @@ func (c *APIController) GetEnrollmentResultsByCourse(w http.ResponseWriter, r *h
 
 	types := []canvas.EnrollmentType{canvas.StudentEnrollment}
 
-	enrollments, code, err := c.canvasClient.GetEnrollmentsByCourseID(r.Context(), courseID, states, types)
+	enrollments, code, err := c.canvasClient.GetEnrollmentsByCourseID(r.Context(), req.CourseID, states, types)
 	if err != nil {
 		return code, err
 	}
@@ -68,9 +58,9 @@ func (c *APIController) GetEnrollmentResultsByCourse(w http.ResponseWriter, r *h
 			EnrollmentState: enrollment.EnrollmentState,
 			EnrollmentRole:  enrollment.Role,
 			Section:         enrollment.SISSectionID,
-			Account:         accountName,
-			CourseName:      courseName,
-			CourseState:     courseWorkflowState,
+			Account:         req.AccountName,
+			CourseName:      req.CourseName,
+			CourseState:     req.CourseWorkflowState,
 		}
 
 		results = append(results, result)
@@ -84,20 +74,33 @@ func (c *APIController) GetEnrollmentResultsByCourse(w http.ResponseWriter, r *h
 }
 
 func (c *APIController) GetEnrollmentsResultsByUser(w http.ResponseWriter, r *http.Request, user canvas.User) (int, error) {
+	results, code, err := c.enrollmentsResultsOfUser(r.Context(), user)
+	if err != nil {
+		return code, err
+	}
+
+	if err := json.NewEncoder(w).Encode(results); err != nil {
+		return http.StatusInternalServerError, err
+	}
+
+	return http.StatusOK, nil
+}
+
+func (c *APIController) enrollmentsResultsOfUser(ctx context.Context, user canvas.User) ([]EnrollmentResult, int, error) {
 	states := []canvas.EnrollmentState{canvas.ActiveEnrollment, canvas.CompletedEnrollment}
 
 	results := make([]EnrollmentResult, 0)
 
-	enrollments, code, err := c.canvasClient.GetEnrollmentsByUserID(r.Context(), user.ID, states)
+	enrollments, code, err := c.canvasClient.GetEnrollmentsByUserID(ctx, user.ID, states)
 	if err != nil {
-		return code, err
+		return nil, code, err
 	}
 
 	coursesMap := make(map[int]canvas.Course, len(enrollments))
 
-	courses, code, err := c.canvasClient.GetCoursesByUserID(r.Context(), user.ID)
+	courses, code, err := c.canvasClient.GetCoursesByUserID(ctx, user.ID)
 	if err != nil {
-		return code, err
+		return nil, code, err
 	}
 
 	for _, course := range courses {
@@ -122,9 +125,9 @@ func (c *APIController) GetEnrollmentsResultsByUser(w http.ResponseWriter, r *ht
 			result.Account = course.Account.Name
 
 		} else {
-			course, code, err := c.canvasClient.GetCourseByID(r.Context(), enrollment.CourseID)
+			course, code, err := c.canvasClient.GetCourseByID(ctx, enrollment.CourseID)
 			if err != nil {
-				return code, err
+				return nil, code, err
 			}
 
 			coursesMap[enrollment.CourseID] = course
@@ -135,9 +138,9 @@ func (c *APIController) GetEnrollmentsResultsByUser(w http.ResponseWriter, r *ht
 		}
 
 		if result.Section == "" {
-			section, code, err := c.canvasClient.GetSectionByID(r.Context(), enrollment.CourseSectionID)
+			section, code, err := c.canvasClient.GetSectionByID(ctx, enrollment.CourseSectionID)
 			if err != nil {
-				return code, err
+				return nil, code, err
 			}
 
 			result.Section = section.Name
@@ -146,9 +149,5 @@ func (c *APIController) GetEnrollmentsResultsByUser(w http.ResponseWriter, r *ht
 		results = append(results, result)
 	}
 
-	if err := json.NewEncoder(w).Encode(results); err != nil {
-		return http.StatusInternalServerError, err
-	}
-
-	return http.StatusOK, nil
+	return results, http.StatusOK, nil
 }
@@ -0,0 +1,227 @@
+package api
+
+import (
+	"canvas-admin/canvas"
+	"context"
+	"encoding/json"
+	"net/http"
+	"slices"
+
+	"github.com/guregu/null/v5"
+)
+
+type ExploredAssignment struct {
+	Account           string                  `json:"account"`
+	CourseID          int                     `json:"course_id"`
+	CourseName        string                  `json:"course_name"`
+	AssignmentID      int                     `json:"assignment_id"`
+	Name              string                  `json:"name"`
+	Published         bool                    `json:"published"`
+	GradingType       string                  `json:"grading_type"`
+	PointsPossible    null.Float              `json:"points_possible"`
+	NeedsGradingCount int                     `json:"needs_grading_count"`
+	DueAt             null.String             `json:"due_at"` // of everyone without an override
+	UnlockAt          null.String             `json:"unlock_at"`
+	LockAt            null.String             `json:"lock_at"`
+	Dates             []canvas.AssignmentDate `json:"dates"` // the base dates and those of each override
+	HtmlUrl           string                  `json:"html_url"`
+	Classification    string                  `json:"classification"` // of the first matching assignment rule, empty without one
+	Excluded          bool                    `json:"excluded"`       // left out of the reports by the rule
+}
+
+// assignmentFilter selects the assignments of the explorer. The bucket and
+// search term are applied by Canvas, the rest here.
+type assignmentFilter struct {
+	Bucket       string   `query:"bucket" validate:"oneof=all past overdue undated ungraded unsubmitted upcoming future"`
+	SearchTerm   string   `query:"search_term" validate:"omitempty,min=2"`
+	Published    string   `query:"published" validate:"omitempty,oneof=true false"`
+	GradingTypes []string `query:"grading_type" validate:"dive,oneof=pass_fail percent letter_grade gpa_scale points not_graded"`
+}
+
+type assignmentExplorerByCourseRequest struct {
+	courseRequest
+	assignmentFilter
+}
+
+type assignmentExplorerByAccountRequest struct {
+	accountReportRequest
+	assignmentFilter
+}
+
+func (f assignmentFilter) match(assignment canvas.Assignment) bool {
+	if f.Published != "" && assignment.Published != (f.Published == "true") {
+		return false
+	}
+
+	if len(f.GradingTypes) > 0 && !slices.Contains(f.GradingTypes, assignment.GradingType) {
+		return false
+	}
+
+	return true
+}
+
+// GetAssignmentExplorerByCourse lists the assignments of a course in a Canvas
+// bucket with the dates of each section.
+func (c *APIController) GetAssignmentExplorerByCourse(w http.ResponseWriter, r *http.Request) (int, error) {
+	req := assignmentExplorerByCourseRequest{assignmentFilter: assignmentFilter{Bucket: string(canvas.AllBucket)}}
+	if err := bind(r, &req); err != nil {
+		return http.StatusBadRequest, err
+	}
+
+	course, code, err := c.canvasClient.GetCourseByID(r.Context(), req.CourseID)
+	if err != nil {
+		return code, err
+	}
+
+	results, code, err := c.exploredAssignments(r.Context(), course, req.assignmentFilter)
+	if err != nil {
+		return code, err
+	}
+
+	if err := json.NewEncoder(w).Encode(results); err != nil {
+		return http.StatusInternalServerError, err
+	}
+
+	return http.StatusOK, nil
+}
+
+// GetAssignmentExplorerByAccount lists the assignments in a Canvas bucket of
+// the courses with students of an account, e.g. the overdue or undated
+// assessments of a qualification.
+func (c *APIController) GetAssignmentExplorerByAccount(w http.ResponseWriter, r *http.Request) (int, error) {
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+
+	req := assignmentExplorerByAccountRequest{assignmentFilter: assignmentFilter{Bucket: string(canvas.AllBucket)}}
+	if err := bind(r, &req); err != nil {
+		return http.StatusBadRequest, err
+	}
+
+	filter, err := req.coursesFilter()
+	if err != nil {
+		return http.StatusBadRequest, err
+	}
+
+	types := []canvas.CourseEnrollmentType{canvas.StudentCourseEnrollment}
+
+	courses, code, err := c.canvasClient.GetCoursesByAccountID(ctx, req.AccountID, "", types, filter)
+	if err != nil {
+		return code, err
+	}
+
+	results := make([]ExploredAssignment, 0)
+
+	for _, course := range courses {
+		select {
+		case <-ctx.Done():
+			return http.StatusRequestTimeout, ctx.Err()
+		default:
+			{
+				assignments, code, err := c.exploredAssignments(ctx, course, req.assignmentFilter)
+				if err != nil {
+					return code, err
+				}
+
+				results = append(results, assignments...)
+			}
+		}
+	}
+
+	if err := json.NewEncoder(w).Encode(results); err != nil {
+		return http.StatusInternalServerError, err
+	}
+
+	return http.StatusOK, nil
+}
+
+func (c *APIController) exploredAssignments(ctx context.Context, course canvas.Course, filter assignmentFilter) ([]ExploredAssignment, int, error) {
+	assignments, code, err := c.canvasClient.GetAssignmentsByCourseID(ctx, course.ID, filter.SearchTerm, canvas.AssignmentBucket(filter.Bucket), true)
+	if err != nil {
+		return nil, code, err
+	}
+
+	rules := c.classificationRules(ctx)
+
+	results := make([]ExploredAssignment, 0, len(assignments))
+
+	for _, assignment := range assignments {
+		if !filter.match(assignment) {
+			continue
+		}
+
+		dates, code, err := c.assignmentDates(ctx, assignment)
+		if err != nil {
+			return nil, code, err
+		}
+
+		explored := ExploredAssignment{
+			Account:           course.Account.Name,
+			CourseID:          course.ID,
+			CourseName:        course.Name,
+			AssignmentID:      assignment.ID,
+			Name:              assignment.Name,
+			Published:         assignment.Published,
+			GradingType:       assignment.GradingType,
+			PointsPossible:    assignment.PointsPossible,
+			NeedsGradingCount: assignment.NeedsGradingCount,
+			DueAt:             assignment.DueAt,
+			UnlockAt:          assignment.UnlockAt,
+			LockAt:            assignment.LockAt,
+			Dates:             dates,
+			HtmlUrl:           assignment.HtmlUrl,
+		}
+
+		if rule := rules.match(assignmentTarget(assignment, course.AccountID)); rule != nil {
+			explored.Classification, explored.Excluded = rule.Classification, rule.Exclude
+		}
+
+		results = append(results, explored)
+	}
+
+	return results, http.StatusOK, nil
+}
+
+// assignmentDates returns the dates of an assignment for everyone and for each
+// override. Canvas leaves out all_dates of assignments with many overrides,
+// those are read from the overrides.
+func (c *APIController) assignmentDates(ctx context.Context, assignment canvas.Assignment) ([]canvas.AssignmentDate, int, error) {
+	if len(assignment.AllDates) > 0 {
+		return assignment.AllDates, http.StatusOK, nil
+	}
+
+	withOverrides, code, err := c.canvasClient.GetAssignmentByID(ctx, assignment.ID, assignment.CourseID, true)
+	if err != nil {
+		return nil, code, err
+	}
+
+	dates := []canvas.AssignmentDate{{
+		DueAt:    assignment.DueAt.String,
+		UnlockAt: assignment.UnlockAt.String,
+		LockAt:   assignment.LockAt.String,
+		Base:     true,
+	}}
+
+	for _, o := range withOverrides.Overrides {
+		date := canvas.AssignmentDate{
+			ID:       null.IntFrom(int64(o.ID)),
+			DueAt:    o.DueAt.String,
+			UnlockAt: o.UnlockAt.String,
+			LockAt:   o.LockAt.String,
+			Title:    o.Title,
+			SetType:  "ADHOC",
+		}
+
+		switch {
+		case o.CourseSectionID.Valid:
+			date.SetType = "CourseSection"
+			date.SetID = o.CourseSectionID
+		case o.GroupID.Valid:
+			date.SetType = "Group"
+			date.SetID = o.GroupID
+		}
+
+		dates = append(dates, date)
+	}
+
+	return dates, http.StatusOK, nil
+}
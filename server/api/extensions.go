@@ -0,0 +1,399 @@
+package api
+
+import (
+	"canvas-admin/canvas"
+	"canvas-admin/supabase"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/guregu/null/v5"
+)
+
+const (
+	ExtensionCreated = "created"
+	ExtensionUpdated = "updated"
+	ExtensionRefused = "refused"
+	ExtensionFailed  = "failed"
+
+	AuditGrantExtension  = "extensions.grant"
+	AuditRemoveExtension = "extensions.remove"
+)
+
+type Extension struct {
+	CourseID        int         `json:"course_id"`
+	AssignmentID    int         `json:"assignment_id"`
+	AssignmentName  string      `json:"assignment_name"`
+	AssignmentDueAt null.String `json:"assignment_due_at"` // of everyone else
+	UserID          int         `json:"user_id"`
+	UserName        string      `json:"user_name"`
+	SISUserID       string      `json:"sis_user_id"`
+	Section         string      `json:"section"`
+	OverrideID      int         `json:"override_id"`
+	DueAt           null.String `json:"due_at"`
+	LockAt          null.String `json:"lock_at"`
+	// empty for overrides made in Canvas
+	Reason     string      `json:"reason"`
+	ApprovedBy string      `json:"approved_by"`
+	GrantedBy  string      `json:"granted_by"`
+	GrantedAt  null.String `json:"granted_at"`
+}
+
+type ExtensionChange struct {
+	UserID     int    `json:"user_id"`
+	OverrideID int    `json:"override_id"` // 0 unless created or updated
+	Status     string `json:"status"`
+	Message    string `json:"message,omitempty"`
+}
+
+type grantExtensionsBody struct {
+	AssignmentID int    `json:"assignment_id" validate:"required,gt=0"`
+	UserIDs      []int  `json:"user_ids" validate:"required,min=1,max=50,dive,gt=0"`
+	DueAt        string `json:"due_at" validate:"required,datetime=2006-01-02T15:04:05Z07:00"`
+	LockAt       string `json:"lock_at" validate:"omitempty,datetime=2006-01-02T15:04:05Z07:00"` // the lock date of the assignment by default
+	Reason       string `json:"reason" validate:"required"`
+	ApprovedBy   string `json:"approved_by" validate:"required"`
+}
+
+type extensionsRequest struct {
+	CourseID       int  `path:"course_id" validate:"required,gt=0"`
+	IncludeExpired bool `query:"include_expired"` // past their lock date, or due date without one
+}
+
+type extensionRequest struct {
+	CourseID     int `path:"course_id" validate:"required,gt=0"`
+	AssignmentID int `path:"assignment_id" validate:"required,gt=0"`
+	UserID       int `path:"user_id" validate:"required,gt=0"`
+}
+
+// GetExtensionsByCourse lists the active students with their own dates on the
+// assignments of a course, with the reason and approver of the extensions
+// granted through this server. Expired extensions are left out unless
+// include_expired is set.
+func (c *APIController) GetExtensionsByCourse(w http.ResponseWriter, r *http.Request) (int, error) {
+	ctx := r.Context()
+
+	var req extensionsRequest
+	if err := bind(r, &req); err != nil {
+		return http.StatusBadRequest, err
+	}
+
+	assignments, code, err := c.canvasClient.GetAssignmentsByCourseID(ctx, req.CourseID, "", canvas.AllBucket, false)
+	if err != nil {
+		return code, err
+	}
+
+	records, err := c.supabaseClient.GetExtensionsByCourseID(req.CourseID)
+	if err != nil {
+		return http.StatusInternalServerError, err
+	}
+
+	recordsMap := make(map[[2]int]supabase.Extension, len(records))
+
+	for _, record := range records {
+		recordsMap[[2]int{record.OverrideID, record.UserID}] = record
+	}
+
+	var students map[int]canvas.Enrollment
+	var sectionNames map[int]string
+
+	now := time.Now()
+
+	results := make([]Extension, 0)
+
+	for _, assignment := range assignments {
+		if !assignment.HasOverrides {
+			continue
+		}
+
+		overrides, code, err := c.canvasClient.GetAssignmentOverrides(ctx, req.CourseID, assignment.ID)
+		if err != nil {
+			return code, err
+		}
+
+		for _, override := range overrides {
+			if !req.IncludeExpired && overrideExpired(override, now) {
+				continue
+			}
+
+			for _, userID := range override.StudentIDs {
+				// most courses have no extensions, only then fetch the students
+				if students == nil {
+					students, sectionNames, code, err = c.courseStudents(ctx, req.CourseID)
+					if err != nil {
+						return code, err
+					}
+				}
+
+				// concluded, inactive and removed students keep their overrides
+				enrollment, ok := students[userID]
+				if !ok || enrollment.EnrollmentState != string(canvas.ActiveEnrollment) {
+					continue
+				}
+
+				extension := Extension{
+					CourseID:        req.CourseID,
+					AssignmentID:    assignment.ID,
+					AssignmentName:  assignment.Name,
+					AssignmentDueAt: assignment.DueAt,
+					UserID:          userID,
+					UserName:        enrollment.User.Name,
+					SISUserID:       enrollment.User.SISUserID,
+					Section:         enrollmentSection(enrollment, sectionNames),
+					OverrideID:      override.ID,
+					DueAt:           override.DueAt,
+					LockAt:          override.LockAt,
+				}
+
+				if record, ok := recordsMap[[2]int{override.ID, userID}]; ok {
+					extension.Reason = record.Reason
+					extension.ApprovedBy = record.ApprovedBy
+					extension.GrantedBy = record.CreatedBy
+					extension.GrantedAt = null.StringFrom(record.UpdatedAt.Format(time.RFC3339))
+				}
+
+				results = append(results, extension)
+			}
+		}
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		if results[i].AssignmentName != results[j].AssignmentName {
+			return results[i].AssignmentName < results[j].AssignmentName
+		}
+
+		return results[i].UserName < results[j].UserName
+	})
+
+	if err := json.NewEncoder(w).Encode(results); err != nil {
+		return http.StatusInternalServerError, err
+	}
+
+	return http.StatusOK, nil
+}
+
+// overrideExpired tells if the students can no longer submit with the dates
+// of an override, after its lock date or without one its due date.
+func overrideExpired(override canvas.AssignmentOverride, now time.Time) bool {
+	end := override.LockAt
+	if !end.Valid || end.String == "" {
+		end = override.DueAt
+	}
+
+	if !end.Valid || end.String == "" {
+		return false
+	}
+
+	t, err := time.Parse(time.RFC3339, end.String)
+
+	return err == nil && t.Before(now)
+}
+
+// GrantExtensions gives students new due and lock dates on an assignment, in
+// an ADHOC override of their own. The override of a student is updated by
+// later extensions.
+func (c *APIController) GrantExtensions(w http.ResponseWriter, r *http.Request) (int, error) {
+	var req courseRequest
+	if err := bind(r, &req); err != nil {
+		return http.StatusBadRequest, err
+	}
+
+	var body grantExtensionsBody
+	if err := bindJSON(r, &body); err != nil {
+		return http.StatusBadRequest, err
+	}
+
+	// datetime validated the dates
+	dueAt, _ := time.Parse(time.RFC3339, body.DueAt)
+
+	lockAt := null.String{}
+
+	if body.LockAt != "" {
+		t, _ := time.Parse(time.RFC3339, body.LockAt)
+		if t.Before(dueAt) {
+			return http.StatusBadRequest, &bindError{Fields: []fieldError{{Field: "lock_at", Message: "must not be before due_at"}}}
+		}
+
+		lockAt = null.StringFrom(t.UTC().Format(time.RFC3339))
+	}
+
+	assignment, code, err := c.canvasClient.GetAssignmentByID(r.Context(), body.AssignmentID, req.CourseID, false)
+	if err != nil {
+		return code, err
+	}
+
+	if !lockAt.Valid && assignment.LockAt.Valid {
+		if t, err := time.Parse(time.RFC3339, assignment.LockAt.String); err == nil && t.Before(dueAt) {
+			return http.StatusBadRequest, &bindError{Fields: []fieldError{{Field: "lock_at", Message: "is required, the assignment locks before due_at"}}}
+		}
+	}
+
+	// a null lock date in an override unlocks the assignment for good
+	if !lockAt.Valid {
+		lockAt = assignment.LockAt
+	}
+
+	overrides, code, err := c.canvasClient.GetAssignmentOverrides(r.Context(), req.CourseID, assignment.ID)
+	if err != nil {
+		return code, err
+	}
+
+	ctx, code, err := c.actAsStaff(r.Context())
+	if err != nil {
+		return code, err
+	}
+
+	actor := principalFromContext(ctx).actor()
+
+	results := make([]ExtensionChange, 0, len(body.UserIDs))
+	logs := make([]supabase.AuditLog, 0)
+
+	for _, userID := range body.UserIDs {
+		change := ExtensionChange{UserID: userID}
+
+		dates := canvas.AdhocAssignmentOverride{
+			StudentIDs: []int{userID},
+			DueAt:      null.StringFrom(dueAt.UTC().Format(time.RFC3339)),
+			LockAt:     lockAt,
+		}
+
+		var override canvas.AssignmentOverride
+		var err error
+
+		existing := studentOverride(overrides, userID)
+
+		switch {
+		case existing == nil:
+			override, _, err = c.canvasClient.CreateAssignmentOverride(ctx, req.CourseID, assignment.ID, dates)
+			change.Status = ExtensionCreated
+		case len(existing.StudentIDs) == 1:
+			override, _, err = c.canvasClient.UpdateAssignmentOverride(ctx, req.CourseID, assignment.ID, existing.ID, dates)
+			change.Status = ExtensionUpdated
+		default:
+			change.Status, change.Message = ExtensionRefused, fmt.Sprintf("the student shares override %d with other students, remove them from it first", existing.ID)
+			results = append(results, change)
+
+			continue
+		}
+
+		if err != nil {
+			change.Status, change.Message = ExtensionFailed, err.Error()
+			results = append(results, change)
+
+			continue
+		}
+
+		change.OverrideID = override.ID
+
+		if err := c.supabaseClient.UpsertExtension(supabase.Extension{
+			OverrideID:   override.ID,
+			CourseID:     req.CourseID,
+			AssignmentID: assignment.ID,
+			UserID:       userID,
+			DueAt:        override.DueAt,
+			LockAt:       override.LockAt,
+			Reason:       body.Reason,
+			ApprovedBy:   body.ApprovedBy,
+			CreatedBy:    actor,
+			UpdatedAt:    time.Now(),
+		}); err != nil {
+			log.Printf("error recording extension of override %d: %v", override.ID, err)
+		}
+
+		logs = append(logs, supabase.AuditLog{
+			Action:       AuditGrantExtension,
+			CourseID:     req.CourseID,
+			AssignmentID: assignment.ID,
+			UserIDs:      []int{userID},
+			Details: map[string]any{
+				"override_id": override.ID,
+				"due_at":      override.DueAt,
+				"lock_at":     override.LockAt,
+				"reason":      body.Reason,
+				"approved_by": body.ApprovedBy,
+			},
+		})
+
+		results = append(results, change)
+	}
+
+	c.audit(ctx, logs...)
+
+	if err := json.NewEncoder(w).Encode(results); err != nil {
+		return http.StatusInternalServerError, err
+	}
+
+	return http.StatusOK, nil
+}
+
+// RemoveExtension takes a student out of their ADHOC override of an
+// assignment, back to the dates of everyone else. Overrides of several
+// students keep the others.
+func (c *APIController) RemoveExtension(w http.ResponseWriter, r *http.Request) (int, error) {
+	var req extensionRequest
+	if err := bind(r, &req); err != nil {
+		return http.StatusBadRequest, err
+	}
+
+	overrides, code, err := c.canvasClient.GetAssignmentOverrides(r.Context(), req.CourseID, req.AssignmentID)
+	if err != nil {
+		return code, err
+	}
+
+	override := studentOverride(overrides, req.UserID)
+	if override == nil {
+		return http.StatusNotFound, fmt.Errorf("no extension of user %d on assignment %d", req.UserID, req.AssignmentID)
+	}
+
+	ctx, code, err := c.actAsStaff(r.Context())
+	if err != nil {
+		return code, err
+	}
+
+	if len(override.StudentIDs) == 1 {
+		code, err = c.canvasClient.DeleteAssignmentOverride(ctx, req.CourseID, req.AssignmentID, override.ID)
+	} else {
+		others := slices.DeleteFunc(slices.Clone(override.StudentIDs), func(id int) bool { return id == req.UserID })
+
+		_, code, err = c.canvasClient.UpdateAssignmentOverride(ctx, req.CourseID, req.AssignmentID, override.ID, canvas.AdhocAssignmentOverride{
+			StudentIDs: others,
+			DueAt:      override.DueAt,
+			LockAt:     override.LockAt,
+		})
+	}
+	if err != nil {
+		return code, err
+	}
+
+	if len(override.StudentIDs) == 1 {
+		if err := c.supabaseClient.DeleteExtension(override.ID); err != nil {
+			log.Printf("error deleting extension of override %d: %v", override.ID, err)
+		}
+	}
+
+	c.audit(ctx, supabase.AuditLog{
+		Action:       AuditRemoveExtension,
+		CourseID:     req.CourseID,
+		AssignmentID: req.AssignmentID,
+		UserIDs:      []int{req.UserID},
+		Details:      map[string]any{"override_id": override.ID},
+	})
+
+	return http.StatusOK, nil
+}
+
+// studentOverride returns the ADHOC override of an assignment including a
+// student, nil without one. Canvas allows one per student and assignment.
+func studentOverride(overrides []canvas.AssignmentOverride, userID int) *canvas.AssignmentOverride {
+	for i := range overrides {
+		if slices.Contains(overrides[i].StudentIDs, userID) {
+			return &overrides[i]
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,431 @@
+package api
+
+import (
+	"canvas-admin/canvas"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/guregu/null/v5"
+	"github.com/xuri/excelize/v2"
+)
+
+const (
+	csvContentType  = "text/csv"
+	xlsxContentType = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+)
+
+type GradebookMatrix struct {
+	CourseID    int               `json:"course_id"`
+	CourseName  string            `json:"course_name"`
+	Assignments []GradebookColumn `json:"assignments"`
+	Students    []GradebookRow    `json:"students"`
+}
+
+type GradebookColumn struct {
+	ID             int         `json:"id"`
+	Name           string      `json:"name"`
+	PointsPossible null.Float  `json:"points_possible"`
+	DueAt          null.String `json:"due_at"`
+}
+
+type GradebookRow struct {
+	UserID       int             `json:"user_id"`
+	UserSisID    string          `json:"user_sis_id"`
+	Name         string          `json:"name"`
+	Section      string          `json:"section"`
+	CurrentScore null.Float      `json:"current_score"`
+	Cells        []GradebookCell `json:"cells"` // in the order of the assignments
+}
+
+type GradebookCell struct {
+	AssignmentID  int         `json:"assignment_id"`
+	Score         null.Float  `json:"score"`
+	Grade         null.String `json:"grade"`
+	WorkflowState string      `json:"workflow_state"` // unsubmitted, submitted, pending_review or graded
+	Late          bool        `json:"late"`
+	Missing       bool        `json:"missing"`
+	Excused       bool        `json:"excused"`
+}
+
+type gradebookRequest struct {
+	CourseID  int    `path:"course_id" validate:"required,gt=0"`
+	SectionID int    `query:"section_id" validate:"gte=0"`
+	Format    string `query:"format" validate:"omitempty,oneof=json csv xlsx"`
+}
+
+// String is the cell as shown in the CSV and XLSX exports.
+func (cell GradebookCell) String() string {
+	var value string
+
+	switch {
+	case cell.Excused:
+		return "EX"
+	case cell.Score.Valid:
+		value = strconv.FormatFloat(cell.Score.Float64, 'f', -1, 64)
+	case cell.Grade.Valid:
+		value = cell.Grade.String
+	case cell.WorkflowState == string(canvas.SubmittedSubmissionWorkflowState) || cell.WorkflowState == string(canvas.PendingReviewSubmissionWorkflowState):
+		value = "needs grading"
+	case cell.Missing:
+		return "missing"
+	}
+
+	if cell.Late {
+		value = strings.TrimSpace(value + " (late)")
+	}
+
+	return value
+}
+
+// GetGradebookByCourse returns the students × assignments matrix of a course,
+// or of one of its sections, like the Canvas gradebook export without the
+// assessment coversheets. format selects JSON (default), CSV or XLSX.
+func (c *APIController) GetGradebookByCourse(w http.ResponseWriter, r *http.Request) (int, error) {
+	var req gradebookRequest
+	if err := bind(r, &req); err != nil {
+		return http.StatusBadRequest, err
+	}
+
+	matrix, code, err := c.gradebookMatrix(r.Context(), req.CourseID, req.SectionID)
+	if err != nil {
+		return code, err
+	}
+
+	filename := fmt.Sprintf("gradebook-%d", req.CourseID)
+	if req.SectionID != 0 {
+		filename += fmt.Sprintf("-section-%d", req.SectionID)
+	}
+
+	switch req.Format {
+	case "csv":
+		w.Header().Set("Content-Type", csvContentType)
+		w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.csv"`, filename))
+
+		if err := writeGradebookCSV(w, matrix); err != nil {
+			return http.StatusInternalServerError, err
+		}
+	case "xlsx":
+		w.Header().Set("Content-Type", xlsxContentType)
+		w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.xlsx"`, filename))
+
+		if err := writeGradebookXLSX(w, matrix); err != nil {
+			return http.StatusInternalServerError, err
+		}
+	default:
+		if err := json.NewEncoder(w).Encode(matrix); err != nil {
+			return http.StatusInternalServerError, err
+		}
+	}
+
+	return http.StatusOK, nil
+}
+
+func (c *APIController) gradebookMatrix(ctx context.Context, courseID, sectionID int) (GradebookMatrix, int, error) {
+	matrix := GradebookMatrix{
+		CourseID:    courseID,
+		Assignments: make([]GradebookColumn, 0),
+		Students:    make([]GradebookRow, 0),
+	}
+
+	course, code, err := c.canvasClient.GetCourseByID(ctx, courseID)
+	if err != nil {
+		return matrix, code, err
+	}
+
+	matrix.CourseName = course.Name
+
+	assignments, code, err := c.canvasClient.GetAssignmentsByCourseID(ctx, courseID, "", canvas.AllBucket, false)
+	if err != nil {
+		return matrix, code, err
+	}
+
+	rules := c.classificationRules(ctx)
+
+	columns := make(map[int]int) // assignment id to column index
+
+	for _, assignment := range assignments {
+		if !assignment.Published || rules.excludes(assignmentTarget(assignment, course.AccountID)) {
+			continue
+		}
+
+		columns[assignment.ID] = len(matrix.Assignments)
+
+		matrix.Assignments = append(matrix.Assignments, GradebookColumn{
+			ID:             assignment.ID,
+			Name:           assignment.Name,
+			PointsPossible: assignment.PointsPossible,
+			DueAt:          assignment.DueAt,
+		})
+	}
+
+	sections, code, err := c.canvasClient.GetSectionsByCourseID(ctx, courseID)
+	if err != nil {
+		return matrix, code, err
+	}
+
+	sectionNames := make(map[int]string, len(sections))
+
+	for _, section := range sections {
+		sectionNames[section.ID] = section.Name
+	}
+
+	if _, ok := sectionNames[sectionID]; sectionID != 0 && !ok {
+		return matrix, http.StatusNotFound, fmt.Errorf("section %d is not in course %d", sectionID, courseID)
+	}
+
+	states := []canvas.EnrollmentState{canvas.ActiveEnrollment, canvas.CompletedEnrollment}
+
+	types := []canvas.EnrollmentType{canvas.StudentEnrollment}
+
+	var enrollments []canvas.Enrollment
+
+	if sectionID != 0 {
+		enrollments, code, err = c.canvasClient.GetEnrollmentsBySectionID(ctx, sectionID, states, types)
+	} else {
+		enrollments, code, err = c.canvasClient.GetEnrollmentsByCourseID(ctx, courseID, states, types)
+	}
+	if err != nil {
+		return matrix, code, err
+	}
+
+	rows := make(map[int]int) // user id to row index
+
+	for _, enrollment := range enrollments {
+		// students in many sections have a row for the first one
+		if _, ok := rows[enrollment.UserID]; ok {
+			continue
+		}
+
+		section := enrollment.SISSectionID
+		if section == "" {
+			section = sectionNames[enrollment.CourseSectionID]
+		}
+
+		cells := make([]GradebookCell, len(matrix.Assignments))
+
+		for i, column := range matrix.Assignments {
+			cells[i] = GradebookCell{
+				AssignmentID:  column.ID,
+				WorkflowState: string(canvas.UnsubmittedSubmissionWorkflowState),
+			}
+		}
+
+		rows[enrollment.UserID] = len(matrix.Students)
+
+		matrix.Students = append(matrix.Students, GradebookRow{
+			UserID:       enrollment.UserID,
+			UserSisID:    enrollment.User.SISUserID,
+			Name:         enrollment.User.Name,
+			Section:      section,
+			CurrentScore: enrollment.Grades.CurrentScore,
+			Cells:        cells,
+		})
+	}
+
+	submissions, code, err := c.canvasClient.GetSubmissionsByCourseID(ctx, courseID, canvas.AllStudents, "")
+	if err != nil {
+		return matrix, code, err
+	}
+
+	for _, submission := range submissions {
+		row, ok := rows[submission.UserID]
+		if !ok {
+			continue
+		}
+
+		column, ok := columns[submission.AssignmentID]
+		if !ok {
+			continue
+		}
+
+		matrix.Students[row].Cells[column] = GradebookCell{
+			AssignmentID:  submission.AssignmentID,
+			Score:         submission.Score,
+			Grade:         submission.Grade,
+			WorkflowState: submission.WorkflowState,
+			Late:          submission.Late,
+			Missing:       submission.Missing,
+			Excused:       submission.Excused.Bool,
+		}
+	}
+
+	return matrix, http.StatusOK, nil
+}
+
+func gradebookHeader(matrix GradebookMatrix) []string {
+	header := []string{"Student", "SIS User ID", "Section", "Current Score"}
+
+	for _, column := range matrix.Assignments {
+		if column.PointsPossible.Valid {
+			header = append(header, fmt.Sprintf("%s (%s)", column.Name, strconv.FormatFloat(column.PointsPossible.Float64, 'f', -1, 64)))
+		} else {
+			header = append(header, column.Name)
+		}
+	}
+
+	return header
+}
+
+// csvCell keeps spreadsheets from running text from Canvas as a formula,
+// e.g. an assignment named "=HYPERLINK(...)", by prefixing it with a quote.
+// Numbers, negative scores included, are left as they are.
+func csvCell(value string) string {
+	if value == "" || !strings.ContainsRune("=+-@\t\r", rune(value[0])) {
+		return value
+	}
+
+	if _, err := strconv.ParseFloat(value, 64); err == nil {
+		return value
+	}
+
+	return "'" + value
+}
+
+// writeCSVRecord writes a record with its cells escaped by csvCell.
+func writeCSVRecord(writer *csv.Writer, record []string) error {
+	cells := make([]string, 0, len(record))
+
+	for _, value := range record {
+		cells = append(cells, csvCell(value))
+	}
+
+	return writer.Write(cells)
+}
+
+func writeGradebookCSV(w http.ResponseWriter, matrix GradebookMatrix) error {
+	writer := csv.NewWriter(w)
+
+	if err := writeCSVRecord(writer, gradebookHeader(matrix)); err != nil {
+		return err
+	}
+
+	for _, student := range matrix.Students {
+		record := []string{student.Name, student.UserSisID, student.Section, ""}
+
+		if student.CurrentScore.Valid {
+			record[3] = strconv.FormatFloat(student.CurrentScore.Float64, 'f', -1, 64)
+		}
+
+		for _, cell := range student.Cells {
+			record = append(record, cell.String())
+		}
+
+		if err := writeCSVRecord(writer, record); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+
+	return writer.Error()
+}
+
+// writeGradebookXLSX writes scores as numbers and highlights late, missing
+// and excused cells.
+func writeGradebookXLSX(w http.ResponseWriter, matrix GradebookMatrix) error {
+	f := excelize.NewFile()
+	defer f.Close()
+
+	sheet := "Gradebook"
+
+	if err := f.SetSheetName("Sheet1", sheet); err != nil {
+		return err
+	}
+
+	header := gradebookHeader(matrix)
+
+	for i, value := range header {
+		if err := f.SetCellValue(sheet, cellName(i, 0), value); err != nil {
+			return err
+		}
+	}
+
+	bold, err := f.NewStyle(&excelize.Style{Font: &excelize.Font{Bold: true}})
+	if err != nil {
+		return err
+	}
+
+	if err := f.SetRowStyle(sheet, 1, 1, bold); err != nil {
+		return err
+	}
+
+	fills := map[string]string{
+		"late":    "FFE0B2",
+		"missing": "FFCDD2",
+		"excused": "E0E0E0",
+	}
+
+	styles := make(map[string]int, len(fills))
+
+	for name, color := range fills {
+		style, err := f.NewStyle(&excelize.Style{Fill: excelize.Fill{Type: "pattern", Pattern: 1, Color: []string{color}}})
+		if err != nil {
+			return err
+		}
+
+		styles[name] = style
+	}
+
+	for r, student := range matrix.Students {
+		row := r + 1
+
+		values := []any{student.Name, student.UserSisID, student.Section, nil}
+
+		if student.CurrentScore.Valid {
+			values[3] = student.CurrentScore.Float64
+		}
+
+		for i, value := range values {
+			if err := f.SetCellValue(sheet, cellName(i, row), value); err != nil {
+				return err
+			}
+		}
+
+		for i, cell := range student.Cells {
+			name := cellName(len(values)+i, row)
+
+			// late scores stay numbers, the fill marks them late
+			var value any = cell.String()
+			if cell.Score.Valid && !cell.Excused {
+				value = cell.Score.Float64
+			}
+
+			if err := f.SetCellValue(sheet, name, value); err != nil {
+				return err
+			}
+
+			style, ok := 0, false
+
+			switch {
+			case cell.Excused:
+				style, ok = styles["excused"], true
+			case cell.Missing && !cell.Score.Valid:
+				style, ok = styles["missing"], true
+			case cell.Late:
+				style, ok = styles["late"], true
+			}
+
+			if ok {
+				if err := f.SetCellStyle(sheet, name, name, style); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	if err := f.SetPanes(sheet, &excelize.Panes{Freeze: true, XSplit: 1, YSplit: 1, TopLeftCell: "B2", ActivePane: "bottomRight"}); err != nil {
+		return err
+	}
+
+	return f.Write(w)
+}
+
+func cellName(col, row int) string {
+	name, _ := excelize.CoordinatesToCellName(col+1, row+1)
+	return name
+}
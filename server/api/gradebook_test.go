@@ -0,0 +1,66 @@
+package api
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/guregu/null/v5"
+)
+
+func TestCSVCell(t *testing.T) {
+	tests := []struct {
+		value string
+		want  string
+	}{
+		{value: "", want: ""},
+		{value: "Jo Citizen", want: "Jo Citizen"},
+		{value: "=HYPERLINK(\"http://example.com\")", want: "'=HYPERLINK(\"http://example.com\")"},
+		{value: "+61 400 000 000", want: "'+61 400 000 000"},
+		{value: "-2+3", want: "'-2+3"},
+		{value: "@SUM(A1:A2)", want: "'@SUM(A1:A2)"},
+		{value: "\t=1+1", want: "'\t=1+1"},
+		{value: "B+", want: "B+"},
+		{value: "-2.5", want: "-2.5"},
+		{value: "7", want: "7"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			if got := csvCell(tt.value); got != tt.want {
+				t.Errorf("csvCell(%q) = %q, want %q", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWriteGradebookCSV(t *testing.T) {
+	matrix := GradebookMatrix{
+		Assignments: []GradebookColumn{
+			{ID: 1, Name: "=cmd|' /C calc'!A0", PointsPossible: null.FloatFrom(10)},
+			{ID: 2, Name: "Reflection"},
+		},
+		Students: []GradebookRow{{
+			Name:         "@Jo",
+			UserSisID:    "-123",
+			Section:      "+Evening",
+			CurrentScore: null.FloatFrom(-1.5),
+			Cells: []GradebookCell{
+				{AssignmentID: 1, Score: null.FloatFrom(-1.5)},
+				{AssignmentID: 2, Grade: null.StringFrom("=1"), Late: true},
+			},
+		}},
+	}
+
+	w := httptest.NewRecorder()
+
+	if err := writeGradebookCSV(w, matrix); err != nil {
+		t.Fatalf("writeGradebookCSV() error = %v", err)
+	}
+
+	want := "Student,SIS User ID,Section,Current Score,'=cmd|' /C calc'!A0 (10),Reflection\n" +
+		"'@Jo,-123,'+Evening,-1.5,-1.5,'=1 (late)\n"
+
+	if got := w.Body.String(); got != want {
+		t.Errorf("writeGradebookCSV() = %q, want %q", got, want)
+	}
+}
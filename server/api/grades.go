@@ -7,9 +7,7 @@ import (
 	"net/http"
 	"strconv"
 	"sync"
-	"time"
 
-	"github.com/go-chi/chi/v5"
 	"github.com/guregu/null/v5"
 )
 
@@ -45,23 +43,19 @@ type GradeChangeLogAssignment struct {
 	CourseID int    `json:"course_id"`
 }
 
-func (c *APIController) GetGradeChangeLogsByGraderID(w http.ResponseWriter, r *http.Request) (int, error) {
-	graderID, err := strconv.Atoi(chi.URLParam(r, "grader_id"))
-	if err != nil {
-		return http.StatusBadRequest, fmt.Errorf("invalid grader id")
-	}
-
-	startTime := r.URL.Query().Get("start_time")
-	if !isDateValue(startTime) {
-		return http.StatusBadRequest, fmt.Errorf("invalid start time")
-	}
+type gradeChangeLogsRequest struct {
+	GraderID  int    `path:"grader_id" validate:"required,gt=0"`
+	StartTime string `query:"start_time" validate:"required,clientdate"`
+	EndTime   string `query:"end_time" validate:"required,clientdate"`
+}
 
-	endTime := r.URL.Query().Get("end_time")
-	if !isDateValue(endTime) {
-		return http.StatusBadRequest, fmt.Errorf("invalid end time")
+func (c *APIController) GetGradeChangeLogsByGraderID(w http.ResponseWriter, r *http.Request) (int, error) {
+	var req gradeChangeLogsRequest
+	if err := bind(r, &req); err != nil {
+		return http.StatusBadRequest, err
 	}
 
-	results, code, err := c.canvasClient.GetGradeChangeLogsByGraderID(r.Context(), graderID, startTime, endTime)
+	results, code, err := c.canvasClient.GetGradeChangeLogsByGraderID(r.Context(), req.GraderID, req.StartTime, req.EndTime)
 	if err != nil {
 		return code, err
 	}
@@ -125,11 +119,6 @@ func (c *APIController) GetGradeChangeLogsByGraderID(w http.ResponseWriter, r *h
 	return http.StatusOK, nil
 }
 
-func isDateValue(date string) bool {
-	_, err := time.Parse("Mon Jan 2 2006", date)
-	return err == nil
-}
-
 func processCourses(wg *sync.WaitGroup, coursesCache map[int]*GradeChangeLogCourse, courses []canvas.GradeChangeLogCourse) {
 	defer wg.Done()
 
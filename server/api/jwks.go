@@ -0,0 +1,219 @@
+package api
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"log"
+	"math/big"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	// keys are refetched after jwksTTL so removed keys stop being accepted
+	jwksTTL = 10 * time.Minute
+
+	// an unknown kid triggers a refetch at most once per jwksMinRefresh,
+	// this picks up rotated keys without letting bad tokens flood the issuer
+	jwksMinRefresh = 30 * time.Second
+)
+
+type jwk struct {
+	Kid string `json:"kid"`
+	Kty string `json:"kty"`
+	Alg string `json:"alg"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+// jwks caches the public keys published at a JSON Web Key Set url.
+type jwks struct {
+	url        string
+	httpClient *http.Client
+
+	mu        sync.RWMutex
+	keys      map[string]any
+	fetchedAt time.Time
+	// closed once the fetch in flight is done, nil without one
+	fetching chan struct{}
+}
+
+func newJWKS(url string) *jwks {
+	return &jwks{
+		url: url,
+		httpClient: &http.Client{
+			Timeout: 5 * time.Second,
+		},
+		keys: make(map[string]any),
+	}
+}
+
+func (j *jwks) key(kid string) (any, error) {
+	j.mu.RLock()
+	key, ok := j.keys[kid]
+	fresh := time.Since(j.fetchedAt) < jwksTTL
+	j.mu.RUnlock()
+
+	if ok && fresh {
+		return key, nil
+	}
+
+	j.refresh()
+
+	j.mu.RLock()
+	key, ok = j.keys[kid]
+	j.mu.RUnlock()
+
+	if ok {
+		return key, nil
+	}
+
+	return nil, fmt.Errorf("unknown signing key: %s", kid)
+}
+
+// refresh fetches the keys unless they were fetched less than jwksMinRefresh
+// ago. The keys are fetched without holding mu, so requests with a cached key
+// are not held up by a slow issuer, and concurrent callers wait for the fetch
+// in flight instead of starting their own.
+func (j *jwks) refresh() {
+	j.mu.Lock()
+
+	if fetching := j.fetching; fetching != nil {
+		j.mu.Unlock()
+		<-fetching
+
+		return
+	}
+
+	if time.Since(j.fetchedAt) < jwksMinRefresh {
+		j.mu.Unlock()
+		return
+	}
+
+	fetching := make(chan struct{})
+	j.fetching = fetching
+	j.fetchedAt = time.Now()
+
+	j.mu.Unlock()
+
+	keys, err := j.fetch()
+
+	j.mu.Lock()
+
+	if err != nil {
+		// keep serving cached keys while the issuer is unreachable
+		log.Printf("error refreshing jwks: %v", err)
+	} else {
+		j.keys = keys
+	}
+
+	j.fetching = nil
+
+	j.mu.Unlock()
+
+	close(fetching)
+}
+
+func (j *jwks) fetch() (map[string]any, error) {
+	res, err := j.httpClient.Get(j.url)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unsuccessful request: %s", j.url)
+	}
+
+	var set struct {
+		Keys []jwk `json:"keys"`
+	}
+
+	if err := json.NewDecoder(res.Body).Decode(&set); err != nil {
+		return nil, err
+	}
+
+	keys := make(map[string]any, len(set.Keys))
+
+	for _, k := range set.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+
+		key, err := k.publicKey()
+		if err != nil {
+			log.Printf("skipping jwk %s: %v", k.Kid, err)
+			continue
+		}
+
+		keys[k.Kid] = key
+	}
+
+	return keys, nil
+}
+
+func (k jwk) publicKey() (any, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeBigInt(k.N)
+		if err != nil {
+			return nil, err
+		}
+
+		e, err := decodeBigInt(k.E)
+		if err != nil {
+			return nil, err
+		}
+
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve: %s", k.Crv)
+		}
+
+		x, err := decodeBigInt(k.X)
+		if err != nil {
+			return nil, err
+		}
+
+		y, err := decodeBigInt(k.Y)
+		if err != nil {
+			return nil, err
+		}
+
+		if !curve.IsOnCurve(x, y) {
+			return nil, fmt.Errorf("point is not on curve %s", k.Crv)
+		}
+
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	}
+
+	return nil, fmt.Errorf("unsupported key type: %s", k.Kty)
+}
+
+func decodeBigInt(s string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+
+	return new(big.Int).SetBytes(b), nil
+}
@@ -0,0 +1,273 @@
+package api
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// testKey is a locally generated signing key published by a jwksServer.
+type testKey struct {
+	kid     string
+	private any // *rsa.PrivateKey or *ecdsa.PrivateKey
+}
+
+func newRSAKey(t *testing.T, kid string) testKey {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return testKey{kid: kid, private: key}
+}
+
+func newECKey(t *testing.T, kid string) testKey {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return testKey{kid: kid, private: key}
+}
+
+func (k testKey) jwk() jwk {
+	encode := func(b []byte) string {
+		return base64.RawURLEncoding.EncodeToString(b)
+	}
+
+	switch key := k.private.(type) {
+	case *rsa.PrivateKey:
+		return jwk{
+			Kid: k.kid,
+			Kty: "RSA",
+			Alg: "RS256",
+			Use: "sig",
+			N:   encode(key.N.Bytes()),
+			E:   encode(big.NewInt(int64(key.E)).Bytes()),
+		}
+	case *ecdsa.PrivateKey:
+		size := (key.Curve.Params().BitSize + 7) / 8
+
+		return jwk{
+			Kid: k.kid,
+			Kty: "EC",
+			Alg: "ES256",
+			Use: "sig",
+			Crv: key.Curve.Params().Name,
+			X:   encode(key.X.FillBytes(make([]byte, size))),
+			Y:   encode(key.Y.FillBytes(make([]byte, size))),
+		}
+	}
+
+	panic("unsupported test key")
+}
+
+// jwksServer serves the public keys of its test keys, which can be rotated.
+type jwksServer struct {
+	*httptest.Server
+
+	mu      sync.Mutex
+	keys    []testKey
+	fetches atomic.Int32
+	// when set, requests wait for it to be closed
+	hold chan struct{}
+	// receives a value when a request is held
+	held chan struct{}
+}
+
+func newJWKSServer(t *testing.T, keys ...testKey) *jwksServer {
+	t.Helper()
+
+	s := &jwksServer{keys: keys, held: make(chan struct{}, 1)}
+
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.fetches.Add(1)
+
+		s.mu.Lock()
+		hold := s.hold
+		set := struct {
+			Keys []jwk `json:"keys"`
+		}{}
+
+		for _, k := range s.keys {
+			set.Keys = append(set.Keys, k.jwk())
+		}
+		s.mu.Unlock()
+
+		if hold != nil {
+			s.held <- struct{}{}
+			<-hold
+		}
+
+		if err := json.NewEncoder(w).Encode(set); err != nil {
+			t.Error(err)
+		}
+	}))
+
+	t.Cleanup(s.Close)
+
+	return s
+}
+
+func (s *jwksServer) rotate(keys ...testKey) {
+	s.mu.Lock()
+	s.keys = keys
+	s.mu.Unlock()
+}
+
+func TestJWKSKey(t *testing.T) {
+	rsaKey := newRSAKey(t, "rsa-1")
+	ecKey := newECKey(t, "ec-1")
+
+	server := newJWKSServer(t, rsaKey, ecKey)
+
+	j := newJWKS(server.URL)
+
+	tests := []struct {
+		kid     string
+		wantErr bool
+	}{
+		{kid: "rsa-1"},
+		{kid: "ec-1"},
+		{kid: "unknown", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.kid, func(t *testing.T) {
+			key, err := j.key(tt.kid)
+
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("key(%q) = %T, want an error", tt.kid, key)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("key(%q): %v", tt.kid, err)
+			}
+
+			switch key.(type) {
+			case *rsa.PublicKey, *ecdsa.PublicKey:
+			default:
+				t.Fatalf("key(%q) = %T, want a public key", tt.kid, key)
+			}
+		})
+	}
+
+	// the unknown kid was fetched within jwksMinRefresh of the first fetch
+	if got := server.fetches.Load(); got != 1 {
+		t.Errorf("fetches = %d, want 1", got)
+	}
+}
+
+func TestJWKSKeyRotation(t *testing.T) {
+	oldKey := newRSAKey(t, "old")
+	newKey := newECKey(t, "new")
+
+	server := newJWKSServer(t, oldKey)
+
+	j := newJWKS(server.URL)
+
+	if _, err := j.key("old"); err != nil {
+		t.Fatalf("key(old): %v", err)
+	}
+
+	server.rotate(newKey)
+
+	// unknown kids refetch at most once per jwksMinRefresh
+	if _, err := j.key("new"); err == nil {
+		t.Fatal("key(new) before jwksMinRefresh, want an error")
+	}
+
+	if got := server.fetches.Load(); got != 1 {
+		t.Fatalf("fetches = %d, want 1", got)
+	}
+
+	j.mu.Lock()
+	j.fetchedAt = time.Now().Add(-jwksMinRefresh)
+	j.mu.Unlock()
+
+	if _, err := j.key("new"); err != nil {
+		t.Fatalf("key(new) after rotation: %v", err)
+	}
+
+	if got := server.fetches.Load(); got != 2 {
+		t.Fatalf("fetches = %d, want 2", got)
+	}
+
+	// the rotated out key is no longer accepted
+	if _, err := j.key("old"); err == nil {
+		t.Fatal("key(old) after rotation, want an error")
+	}
+}
+
+func TestJWKSSlowFetchDoesNotBlockCachedKeys(t *testing.T) {
+	key := newRSAKey(t, "cached")
+
+	server := newJWKSServer(t, key)
+
+	j := newJWKS(server.URL)
+
+	if _, err := j.key("cached"); err != nil {
+		t.Fatalf("key(cached): %v", err)
+	}
+
+	hold := make(chan struct{})
+
+	server.mu.Lock()
+	server.hold = hold
+	server.mu.Unlock()
+
+	j.mu.Lock()
+	j.fetchedAt = time.Now().Add(-jwksMinRefresh)
+	j.mu.Unlock()
+
+	// an unknown kid refetches, the server holds the request
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+		j.key("unknown")
+	}()
+
+	select {
+	case <-server.held:
+	case <-time.After(5 * time.Second):
+		t.Fatal("the jwks was not refetched")
+	}
+
+	cached := make(chan error, 1)
+
+	go func() {
+		_, err := j.key("cached")
+		cached <- err
+	}()
+
+	select {
+	case err := <-cached:
+		if err != nil {
+			t.Errorf("key(cached) during a fetch: %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Error("key(cached) waited for the fetch in flight")
+	}
+
+	close(hold)
+	<-done
+}
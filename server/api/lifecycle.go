@@ -0,0 +1,280 @@
+package api
+
+import (
+	"canvas-admin/canvas"
+	"canvas-admin/supabase"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+)
+
+const (
+	EnrollmentConclude   = "conclude"
+	EnrollmentDeactivate = "deactivate"
+	EnrollmentDelete     = "delete"
+	EnrollmentReactivate = "reactivate"
+
+	EnrollmentChanged     = "changed"
+	EnrollmentWouldChange = "would_change" // dry run
+	EnrollmentUnchanged   = "unchanged"    // already in the target state
+	EnrollmentRefused     = "refused"      // failed validation, see force
+	EnrollmentFailed      = "failed"
+
+	// enrollments are changed this many at a time
+	enrollmentChangesConcurrency = 5
+)
+
+// enrollmentTargetStates are the states of the enrollments after each action.
+var enrollmentTargetStates = map[string]canvas.EnrollmentState{
+	EnrollmentConclude:   canvas.CompletedEnrollment,
+	EnrollmentDeactivate: canvas.InactiveEnrollment,
+	EnrollmentDelete:     canvas.DeletedEnrollment,
+	EnrollmentReactivate: canvas.ActiveEnrollment,
+}
+
+type EnrollmentChange struct {
+	CourseID     int    `json:"course_id"`
+	UserID       int    `json:"user_id"`
+	EnrollmentID int    `json:"enrollment_id"` // 0 when the student has no enrollment in the course
+	SISUserID    string `json:"sis_user_id"`
+	Name         string `json:"name"`
+	Section      string `json:"section"`
+	FromState    string `json:"from_state"`
+	ToState      string `json:"to_state"`
+	Status       string `json:"status"`
+	Message      string `json:"message,omitempty"`
+}
+
+type EnrollmentChangesResult struct {
+	Action  string             `json:"action"`
+	DryRun  bool               `json:"dry_run"`
+	Changed int                `json:"changed"`
+	Refused int                `json:"refused"`
+	Failed  int                `json:"failed"`
+	Changes []EnrollmentChange `json:"changes"`
+}
+
+type enrollmentTarget struct {
+	CourseID int `json:"course_id" validate:"required,gt=0"`
+	UserID   int `json:"user_id" validate:"required,gt=0"`
+}
+
+type enrollmentChangesBody struct {
+	Action      string             `json:"action" validate:"required,oneof=conclude deactivate delete reactivate"`
+	Enrollments []enrollmentTarget `json:"enrollments" validate:"required,min=1,max=500,dive"`
+	Reason      string             `json:"reason" validate:"required"`
+	// conclude students with ungraded submissions, and delete enrollments,
+	// Superadmins only
+	Force  bool `json:"force"`
+	DryRun bool `json:"dry_run"` // only validate the changes
+}
+
+// ChangeEnrollments concludes, deactivates, deletes or reactivates the student
+// enrollments of users in courses, e.g. of withdrawn students. Every student
+// enrollment of a user in a course is changed, each one audited.
+func (c *APIController) ChangeEnrollments(w http.ResponseWriter, r *http.Request) (int, error) {
+	var body enrollmentChangesBody
+	if err := bindJSON(r, &body); err != nil {
+		return http.StatusBadRequest, err
+	}
+
+	if body.Force && !principalFromContext(r.Context()).hasRole(superadminAppRole) {
+		return http.StatusForbidden, fmt.Errorf("force requires the %s role", superadminAppRole)
+	}
+
+	ctx, code, err := c.actAsStaff(r.Context())
+	if err != nil {
+		return code, err
+	}
+
+	changes := make([][]EnrollmentChange, len(body.Enrollments))
+
+	var wg sync.WaitGroup
+
+	sem := make(chan struct{}, enrollmentChangesConcurrency)
+
+	for i, target := range body.Enrollments {
+		wg.Add(1)
+
+		go func(i int, target enrollmentTarget) {
+			defer wg.Done()
+
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			changes[i] = c.changeEnrollments(ctx, target, body)
+		}(i, target)
+	}
+
+	wg.Wait()
+
+	result := EnrollmentChangesResult{
+		Action:  body.Action,
+		DryRun:  body.DryRun,
+		Changes: make([]EnrollmentChange, 0, len(body.Enrollments)),
+	}
+
+	logs := make([]supabase.AuditLog, 0)
+
+	for _, targetChanges := range changes {
+		for _, change := range targetChanges {
+			switch change.Status {
+			case EnrollmentChanged:
+				result.Changed++
+
+				logs = append(logs, supabase.AuditLog{
+					Action:   auditEnrollmentsPrefix + body.Action,
+					CourseID: change.CourseID,
+					UserIDs:  []int{change.UserID},
+					Details: map[string]any{
+						"enrollment_id": change.EnrollmentID,
+						"from_state":    change.FromState,
+						"to_state":      change.ToState,
+						"reason":        body.Reason,
+						"forced":        body.Force,
+					},
+				})
+			case EnrollmentRefused:
+				result.Refused++
+			case EnrollmentFailed:
+				result.Failed++
+			}
+
+			result.Changes = append(result.Changes, change)
+		}
+	}
+
+	c.audit(ctx, logs...)
+
+	if err := json.NewEncoder(w).Encode(result); err != nil {
+		return http.StatusInternalServerError, err
+	}
+
+	return http.StatusOK, nil
+}
+
+// changeEnrollments validates and applies the action to the student
+// enrollments of a user in a course.
+func (c *APIController) changeEnrollments(ctx context.Context, target enrollmentTarget, body enrollmentChangesBody) []EnrollmentChange {
+	toState := enrollmentTargetStates[body.Action]
+
+	failed := func(status, message string) []EnrollmentChange {
+		return []EnrollmentChange{{
+			CourseID: target.CourseID,
+			UserID:   target.UserID,
+			ToState:  string(toState),
+			Status:   status,
+			Message:  message,
+		}}
+	}
+
+	states := []canvas.EnrollmentState{canvas.ActiveEnrollment, canvas.InvitedEnrollment, canvas.InactiveEnrollment, canvas.CompletedEnrollment}
+
+	userEnrollments, _, err := c.canvasClient.GetEnrollmentsByUserID(ctx, target.UserID, states)
+	if err != nil {
+		return failed(EnrollmentFailed, err.Error())
+	}
+
+	enrollments := make([]canvas.Enrollment, 0)
+
+	for _, enrollment := range userEnrollments {
+		if enrollment.CourseID == target.CourseID && enrollment.Type == string(canvas.StudentEnrollment) {
+			enrollments = append(enrollments, enrollment)
+		}
+	}
+
+	if len(enrollments) == 0 {
+		return failed(EnrollmentRefused, "no student enrollment of the user in the course")
+	}
+
+	// the validation of the student applies to all their enrollments
+	refusal := ""
+
+	switch {
+	case body.Action == EnrollmentDelete && !body.Force:
+		refusal = "deleting an enrollment loses its grades, set force to delete"
+	case body.Action == EnrollmentConclude && !body.Force:
+		ungraded, err := c.ungradedSubmissionCount(ctx, target)
+		if err != nil {
+			return failed(EnrollmentFailed, err.Error())
+		}
+
+		if ungraded > 0 {
+			refusal = fmt.Sprintf("the student has %d ungraded submissions, set force to conclude", ungraded)
+		}
+	}
+
+	results := make([]EnrollmentChange, 0, len(enrollments))
+
+	for _, enrollment := range enrollments {
+		change := EnrollmentChange{
+			CourseID:     target.CourseID,
+			UserID:       target.UserID,
+			EnrollmentID: enrollment.ID,
+			SISUserID:    enrollment.User.SISUserID,
+			Name:         enrollment.User.Name,
+			Section:      enrollment.SISSectionID,
+			FromState:    enrollment.EnrollmentState,
+			ToState:      string(toState),
+		}
+
+		switch {
+		case enrollment.EnrollmentState == string(toState):
+			change.Status = EnrollmentUnchanged
+		case refusal != "":
+			change.Status, change.Message = EnrollmentRefused, refusal
+		case body.Action == EnrollmentReactivate && enrollment.EnrollmentState != string(canvas.InactiveEnrollment):
+			change.Status, change.Message = EnrollmentRefused, fmt.Sprintf("only deactivated enrollments can be reactivated, not %s ones", enrollment.EnrollmentState)
+		case body.DryRun:
+			change.Status = EnrollmentWouldChange
+		default:
+			var err error
+
+			if body.Action == EnrollmentReactivate {
+				_, _, err = c.canvasClient.ReactivateEnrollment(ctx, target.CourseID, enrollment.ID)
+			} else {
+				_, _, err = c.canvasClient.EndEnrollment(ctx, target.CourseID, enrollment.ID, canvas.EnrollmentTask(body.Action))
+			}
+
+			if err != nil {
+				change.Status, change.Message = EnrollmentFailed, err.Error()
+			} else {
+				change.Status = EnrollmentChanged
+			}
+		}
+
+		results = append(results, change)
+	}
+
+	return results
+}
+
+// ungradedSubmissionCount counts the submissions of a student in a course
+// awaiting grading, assignments excluded by the rules aside.
+func (c *APIController) ungradedSubmissionCount(ctx context.Context, target enrollmentTarget) (int, error) {
+	course, _, err := c.canvasClient.GetCourseByID(ctx, target.CourseID)
+	if err != nil {
+		return 0, err
+	}
+
+	rules := c.classificationRules(ctx)
+
+	count := 0
+
+	for _, state := range []canvas.SubmissionWorkflowState{canvas.SubmittedSubmissionWorkflowState, canvas.PendingReviewSubmissionWorkflowState} {
+		submissions, _, err := c.canvasClient.GetSubmissionsByCourseID(ctx, target.CourseID, target.UserID, state)
+		if err != nil {
+			return 0, err
+		}
+
+		for _, submission := range submissions {
+			if !rules.excludes(submissionTarget(submission, course.AccountID)) {
+				count++
+			}
+		}
+	}
+
+	return count, nil
+}
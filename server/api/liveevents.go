@@ -0,0 +1,568 @@
+package api
+
+import (
+	"canvas-admin/canvas"
+	"canvas-admin/supabase"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/guregu/null/v5"
+)
+
+const (
+	LiveEventStored    = "stored"
+	LiveEventDuplicate = "duplicate" // stored before, e.g. delivered again
+	LiveEventIgnored   = "ignored"   // not used by the reports
+
+	// larger events are refused
+	maxLiveEventSize = 1 << 20
+
+	// ids of Live Events are global, the shard id times this plus the local id
+	canvasShardFactor = 10_000_000_000_000
+
+	// counts changed by events received by another instance are served after
+	// at most this long
+	liveUngradedTTL = time.Minute
+)
+
+// liveEventNames are the events stored, others are acknowledged and dropped.
+var liveEventNames = []string{"submission_created", "submission_updated", "grade_change", "enrollment_updated"}
+
+// enrollments in these states have nothing left to grade
+var endedEnrollmentStates = []string{"completed", "inactive", "deleted", "rejected"}
+
+// LiveEventsConfig verifies the signature and claims of Canvas Live Events,
+// every event is refused when both keys are empty or the claims are unset.
+type LiveEventsConfig struct {
+	Secret   []byte // HMAC secret of HS256 signed events
+	JWKSURL  string // public keys of RS256 and ES256 signed events
+	Issuer   string // expected iss claim, required
+	Audience string // expected aud claim, required
+}
+
+type LiveEventReceipt struct {
+	EventName string `json:"event_name"`
+	Status    string `json:"status"`
+}
+
+type LiveUngradedCount struct {
+	AssignmentID      int         `json:"assignment_id"`
+	AssignmentName    string      `json:"assignment_name"`
+	NeedsGradingCount int         `json:"needs_grading_count"`
+	OldestSubmittedAt null.String `json:"oldest_submitted_at"`
+}
+
+// canvasID is an id of a Live Event, a string or a number, converted from a
+// global id to the local one used by the REST API.
+type canvasID int
+
+func (id *canvasID) UnmarshalJSON(b []byte) error {
+	s := strings.Trim(string(b), `"`)
+
+	if s == "" || s == "null" {
+		*id = 0
+		return nil
+	}
+
+	n, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid canvas id: %s", s)
+	}
+
+	*id = canvasID(n % canvasShardFactor)
+
+	return nil
+}
+
+// liveEventClaims is the payload of a signed Live Event.
+type liveEventClaims struct {
+	Metadata json.RawMessage `json:"metadata"`
+	Body     json.RawMessage `json:"body"`
+	jwt.RegisteredClaims
+}
+
+type liveEventMetadata struct {
+	EventName   string   `json:"event_name"`
+	EventTime   string   `json:"event_time"`
+	ContextType string   `json:"context_type"`
+	ContextID   canvasID `json:"context_id"`
+}
+
+type submissionEventBody struct {
+	SubmissionID  canvasID    `json:"submission_id"`
+	AssignmentID  canvasID    `json:"assignment_id"`
+	UserID        canvasID    `json:"user_id"`
+	SubmittedAt   null.String `json:"submitted_at"`
+	WorkflowState string      `json:"workflow_state"`
+}
+
+type gradeChangeEventBody struct {
+	SubmissionID    canvasID    `json:"submission_id"`
+	AssignmentID    canvasID    `json:"assignment_id"`
+	StudentID       canvasID    `json:"student_id"`
+	Grade           null.String `json:"grade"`
+	Score           null.Float  `json:"score"`
+	GradingComplete null.Bool   `json:"grading_complete"`
+}
+
+type enrollmentEventBody struct {
+	EnrollmentID  canvasID `json:"enrollment_id"`
+	CourseID      canvasID `json:"course_id"`
+	UserID        canvasID `json:"user_id"`
+	Type          string   `json:"type"`
+	WorkflowState string   `json:"workflow_state"`
+}
+
+// ungradedChange is what an event changes in the ungraded submissions, the
+// zero value changes nothing. Both are skipped by Supabase when older than
+// what is stored, events can arrive out of order.
+type ungradedChange struct {
+	submission *supabase.UngradedSubmission
+	// the submissions of the student in the course from before no longer
+	// await grading
+	endedEnrollment *supabase.EndedEnrollment
+}
+
+// liveEvent is a validated Live Event.
+type liveEvent struct {
+	supabase.LiveEvent
+	change ungradedChange
+	// of submission events, 0 otherwise
+	assignmentID int
+}
+
+// IngestLiveEvent stores a Canvas Live Event sent as a signed JWT, e.g. by a
+// Canvas Data Services subscription, and applies it to the ungraded
+// submissions read by GetLiveUngradedCountsByCourse. An event delivered again
+// is only stored and applied once.
+func (c *APIController) IngestLiveEvent(w http.ResponseWriter, r *http.Request) (int, error) {
+	raw, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxLiveEventSize))
+	if err != nil {
+		var maxErr *http.MaxBytesError
+		if errors.As(err, &maxErr) {
+			return http.StatusRequestEntityTooLarge, fmt.Errorf("event larger than %d bytes", maxErr.Limit)
+		}
+
+		return http.StatusBadRequest, err
+	}
+
+	claims, err := c.liveEvents.verifyLiveEvent(strings.TrimSpace(string(raw)))
+	if err != nil {
+		return http.StatusUnauthorized, err
+	}
+
+	event, err := newLiveEvent(claims)
+	if err != nil {
+		return http.StatusBadRequest, err
+	}
+
+	receipt := LiveEventReceipt{EventName: event.EventName, Status: LiveEventIgnored}
+
+	if slices.Contains(liveEventNames, event.EventName) {
+		receipt.Status, err = c.storeLiveEvent(event)
+		if err != nil {
+			return http.StatusInternalServerError, err
+		}
+	}
+
+	if err := json.NewEncoder(w).Encode(receipt); err != nil {
+		return http.StatusInternalServerError, err
+	}
+
+	return http.StatusOK, nil
+}
+
+// verifyLiveEvent checks the signature, expiry and issue time of an event,
+// and that it was issued for the Live Events of this server.
+func (a *auther) verifyLiveEvent(token string) (liveEventClaims, error) {
+	var claims liveEventClaims
+
+	// the parser skips the iss and aud checks when they are empty
+	if a.issuer == "" || a.audience == "" {
+		return claims, fmt.Errorf("error validating event: missing issuer or audience config")
+	}
+
+	opts := []jwt.ParserOption{
+		jwt.WithValidMethods([]string{"HS256", "RS256", "ES256"}),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+		jwt.WithIssuer(a.issuer),
+		jwt.WithAudience(a.audience),
+	}
+
+	if _, err := jwt.ParseWithClaims(token, &claims, a.keyFunc, opts...); err != nil {
+		return claims, fmt.Errorf("error validating event: %w", err)
+	}
+
+	return claims, nil
+}
+
+// storeLiveEvent stores an event and applies it, unless it was stored before.
+func (c *APIController) storeLiveEvent(event liveEvent) (string, error) {
+	err := c.supabaseClient.CreateLiveEvent(event.LiveEvent)
+	if errors.Is(err, supabase.ErrDuplicate) {
+		return LiveEventDuplicate, nil
+	}
+	if err != nil {
+		return "", err
+	}
+
+	if err := c.applyUngradedChange(event.change); err != nil {
+		// forget the event so Canvas delivers it again
+		if err := c.supabaseClient.DeleteLiveEvent(event.EventKey); err != nil {
+			log.Printf("error deleting live event %s: %v", event.EventKey, err)
+		}
+
+		return "", err
+	}
+
+	if event.CourseID.Valid {
+		c.liveUngraded.invalidate(int(event.CourseID.Int64), event.assignmentID)
+	}
+
+	return LiveEventStored, nil
+}
+
+// newLiveEvent validates the metadata and body of an event. Only the name of
+// events not used by the reports is read.
+func newLiveEvent(claims liveEventClaims) (liveEvent, error) {
+	var event liveEvent
+	var metadata liveEventMetadata
+
+	if err := json.Unmarshal(claims.Metadata, &metadata); err != nil {
+		return event, &bindError{Fields: []fieldError{{Field: "metadata", Message: fmt.Sprintf("must be valid JSON: %v", err)}}}
+	}
+
+	event.EventName = metadata.EventName
+
+	if !slices.Contains(liveEventNames, metadata.EventName) {
+		return event, nil
+	}
+
+	eventTime, err := time.Parse(time.RFC3339, metadata.EventTime)
+	if err != nil {
+		return event, &bindError{Fields: []fieldError{{Field: "metadata.event_time", Message: fmt.Sprintf("must be a date and time like %s", time.RFC3339)}}}
+	}
+
+	required := func(field string) error {
+		return &bindError{Fields: []fieldError{{Field: field, Message: "is required"}}}
+	}
+
+	courseID := 0
+	if metadata.ContextType == "Course" {
+		courseID = int(metadata.ContextID)
+	}
+
+	userID := 0
+
+	switch metadata.EventName {
+	case "submission_created", "submission_updated":
+		var body submissionEventBody
+		if err := json.Unmarshal(claims.Body, &body); err != nil {
+			return event, &bindError{Fields: []fieldError{{Field: "body", Message: fmt.Sprintf("must be valid JSON: %v", err)}}}
+		}
+
+		switch {
+		case body.SubmissionID == 0:
+			return event, required("body.submission_id")
+		case body.AssignmentID == 0:
+			return event, required("body.assignment_id")
+		case body.UserID == 0:
+			return event, required("body.user_id")
+		case courseID == 0:
+			return event, required("metadata.context_id")
+		}
+
+		userID, event.assignmentID = int(body.UserID), int(body.AssignmentID)
+
+		event.change.submission = &supabase.UngradedSubmission{
+			SubmissionID:    int(body.SubmissionID),
+			CourseID:        courseID,
+			AssignmentID:    int(body.AssignmentID),
+			UserID:          userID,
+			SubmittedAt:     body.SubmittedAt,
+			UpdatedAt:       eventTime,
+			AwaitingGrading: body.WorkflowState == string(canvas.SubmittedSubmissionWorkflowState) || body.WorkflowState == string(canvas.PendingReviewSubmissionWorkflowState),
+		}
+	case "grade_change":
+		var body gradeChangeEventBody
+		if err := json.Unmarshal(claims.Body, &body); err != nil {
+			return event, &bindError{Fields: []fieldError{{Field: "body", Message: fmt.Sprintf("must be valid JSON: %v", err)}}}
+		}
+
+		switch {
+		case body.SubmissionID == 0:
+			return event, required("body.submission_id")
+		case body.AssignmentID == 0:
+			return event, required("body.assignment_id")
+		case body.StudentID == 0:
+			return event, required("body.student_id")
+		case courseID == 0:
+			return event, required("metadata.context_id")
+		}
+
+		userID, event.assignmentID = int(body.StudentID), int(body.AssignmentID)
+
+		// a removed grade leaves the submission as it was
+		if (body.Grade.Valid || body.Score.Valid) && (!body.GradingComplete.Valid || body.GradingComplete.Bool) {
+			event.change.submission = &supabase.UngradedSubmission{
+				SubmissionID: int(body.SubmissionID),
+				CourseID:     courseID,
+				AssignmentID: int(body.AssignmentID),
+				UserID:       userID,
+				UpdatedAt:    eventTime,
+			}
+		}
+	case "enrollment_updated":
+		var body enrollmentEventBody
+		if err := json.Unmarshal(claims.Body, &body); err != nil {
+			return event, &bindError{Fields: []fieldError{{Field: "body", Message: fmt.Sprintf("must be valid JSON: %v", err)}}}
+		}
+
+		switch {
+		case body.CourseID == 0:
+			return event, required("body.course_id")
+		case body.UserID == 0:
+			return event, required("body.user_id")
+		}
+
+		courseID, userID = int(body.CourseID), int(body.UserID)
+
+		if body.Type == string(canvas.StudentEnrollment) && slices.Contains(endedEnrollmentStates, body.WorkflowState) {
+			event.change.endedEnrollment = &supabase.EndedEnrollment{
+				CourseID: courseID,
+				UserID:   userID,
+				EndedAt:  eventTime,
+			}
+		}
+	}
+
+	payload, err := json.Marshal(map[string]json.RawMessage{
+		"metadata": claims.Metadata,
+		"body":     claims.Body,
+	})
+	if err != nil {
+		return event, err
+	}
+
+	key := sha256.Sum256(slices.Concat(claims.Metadata, []byte("\n"), claims.Body))
+
+	event.LiveEvent = supabase.LiveEvent{
+		EventKey:  hex.EncodeToString(key[:]),
+		EventName: metadata.EventName,
+		EventTime: eventTime,
+		Payload:   payload,
+	}
+
+	if courseID > 0 {
+		event.CourseID = null.IntFrom(int64(courseID))
+	}
+
+	if userID > 0 {
+		event.UserID = null.IntFrom(int64(userID))
+	}
+
+	return event, nil
+}
+
+// applyUngradedChange updates the ungraded submissions. Submissions no longer
+// awaiting grading are kept, Supabase compares the event time of a change
+// with the stored one in the same statement and skips older changes.
+func (c *APIController) applyUngradedChange(change ungradedChange) error {
+	switch {
+	case change.submission != nil:
+		return c.supabaseClient.UpsertUngradedSubmission(*change.submission)
+	case change.endedEnrollment != nil:
+		return c.supabaseClient.UpsertEndedEnrollment(*change.endedEnrollment)
+	}
+
+	return nil
+}
+
+// liveUngradedCache holds the live ungraded counts of courses with their
+// assignments. Events of a course clear its counts, and its assignments when
+// the event is of an assignment not seen before.
+type liveUngradedCache struct {
+	mu      sync.Mutex
+	courses map[int]*liveUngradedEntry
+	// incremented by clear, with the version of an entry a result computed
+	// before a change is not stored
+	epoch int
+}
+
+type liveUngradedEntry struct {
+	version       int
+	counts        []LiveUngradedCount // nil when cleared
+	countsAt      time.Time
+	accountID     int
+	assignments   map[int]canvas.Assignment // nil when cleared
+	assignmentsAt time.Time
+}
+
+// liveUngradedVersion identifies the state of an entry when it was read.
+type liveUngradedVersion struct {
+	epoch, version int
+}
+
+func newLiveUngradedCache() *liveUngradedCache {
+	return &liveUngradedCache{courses: make(map[int]*liveUngradedEntry)}
+}
+
+func (lc *liveUngradedCache) entry(courseID int) *liveUngradedEntry {
+	entry, ok := lc.courses[courseID]
+	if !ok {
+		entry = &liveUngradedEntry{}
+		lc.courses[courseID] = entry
+	}
+
+	return entry
+}
+
+// get returns the fresh counts and assignments of a course, nil when unknown,
+// and the version to store them with.
+func (lc *liveUngradedCache) get(courseID int) ([]LiveUngradedCount, map[int]canvas.Assignment, int, liveUngradedVersion) {
+	lc.mu.Lock()
+	defer lc.mu.Unlock()
+
+	entry := lc.entry(courseID)
+	version := liveUngradedVersion{epoch: lc.epoch, version: entry.version}
+
+	var counts []LiveUngradedCount
+	if entry.counts != nil && time.Since(entry.countsAt) < liveUngradedTTL {
+		counts = entry.counts
+	}
+
+	var assignments map[int]canvas.Assignment
+	if entry.assignments != nil && time.Since(entry.assignmentsAt) < liveUngradedTTL {
+		assignments = entry.assignments
+	}
+
+	return counts, assignments, entry.accountID, version
+}
+
+// set stores the counts and assignments of a course unless it changed since
+// version.
+func (lc *liveUngradedCache) set(courseID int, version liveUngradedVersion, counts []LiveUngradedCount, accountID int, assignments map[int]canvas.Assignment) {
+	lc.mu.Lock()
+	defer lc.mu.Unlock()
+
+	entry := lc.entry(courseID)
+	if lc.epoch != version.epoch || entry.version != version.version {
+		return
+	}
+
+	now := time.Now()
+
+	entry.counts, entry.countsAt = counts, now
+
+	if assignments != nil {
+		entry.accountID = accountID
+		entry.assignments, entry.assignmentsAt = assignments, now
+	}
+}
+
+// invalidate clears the counts of a course after an event, and its
+// assignments when assignmentID is not one of them, e.g. a new assignment.
+func (lc *liveUngradedCache) invalidate(courseID, assignmentID int) {
+	lc.mu.Lock()
+	defer lc.mu.Unlock()
+
+	entry := lc.entry(courseID)
+	entry.version++
+	entry.counts = nil
+
+	if _, ok := entry.assignments[assignmentID]; assignmentID != 0 && !ok {
+		entry.assignments = nil
+	}
+}
+
+// clear forgets every course, e.g. once the rules changed.
+func (lc *liveUngradedCache) clear() {
+	lc.mu.Lock()
+	lc.courses = make(map[int]*liveUngradedEntry)
+	lc.epoch++
+	lc.mu.Unlock()
+}
+
+// GetLiveUngradedCountsByCourse counts the submissions awaiting grading of
+// each assignment of a course from the Live Events, without polling Canvas for
+// the submissions. Assignments excluded by the rules are left out. The counts
+// are cached until an event of the course is received.
+func (c *APIController) GetLiveUngradedCountsByCourse(w http.ResponseWriter, r *http.Request) (int, error) {
+	var req courseRequest
+	if err := bind(r, &req); err != nil {
+		return http.StatusBadRequest, err
+	}
+
+	results, assignments, accountID, version := c.liveUngraded.get(req.CourseID)
+
+	if results == nil {
+		counts, err := c.supabaseClient.GetUngradedCountsByCourseID(req.CourseID)
+		if err != nil {
+			return http.StatusInternalServerError, err
+		}
+
+		results = make([]LiveUngradedCount, 0, len(counts))
+
+		if len(counts) > 0 && assignments == nil {
+			course, code, err := c.canvasClient.GetCourseByID(r.Context(), req.CourseID)
+			if err != nil {
+				return code, err
+			}
+
+			list, code, err := c.canvasClient.GetAssignmentsByCourseID(r.Context(), req.CourseID, "", canvas.AllBucket, false)
+			if err != nil {
+				return code, err
+			}
+
+			accountID = course.AccountID
+			assignments = make(map[int]canvas.Assignment, len(list))
+
+			for _, assignment := range list {
+				assignments[assignment.ID] = assignment
+			}
+		}
+
+		rules := c.classificationRules(r.Context())
+
+		for _, count := range counts {
+			// deleted assignments have nothing to grade
+			assignment, ok := assignments[count.AssignmentID]
+			if !ok || rules.excludes(assignmentTarget(assignment, accountID)) {
+				continue
+			}
+
+			results = append(results, LiveUngradedCount{
+				AssignmentID:      assignment.ID,
+				AssignmentName:    assignment.Name,
+				NeedsGradingCount: count.NeedsGradingCount,
+				OldestSubmittedAt: count.OldestSubmittedAt,
+			})
+		}
+
+		sort.Slice(results, func(i, j int) bool {
+			return results[i].AssignmentName < results[j].AssignmentName
+		})
+
+		c.liveUngraded.set(req.CourseID, version, results, accountID, assignments)
+	}
+
+	if err := json.NewEncoder(w).Encode(results); err != nil {
+		return http.StatusInternalServerError, err
+	}
+
+	return http.StatusOK, nil
+}
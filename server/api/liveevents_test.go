@@ -0,0 +1,485 @@
+package api
+
+import (
+	"canvas-admin/canvas"
+	"canvas-admin/supabase"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/guregu/null/v5"
+)
+
+// readLiveEvent reads a Live Event recorded from Canvas in testdata.
+func readLiveEvent(t *testing.T, name string) liveEventClaims {
+	t.Helper()
+
+	raw, err := os.ReadFile(filepath.Join("testdata", "live_events", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var claims liveEventClaims
+	if err := json.Unmarshal(raw, &claims); err != nil {
+		t.Fatal(err)
+	}
+
+	return claims
+}
+
+func TestCanvasIDUnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		json    string
+		want    canvasID
+		wantErr bool
+	}{
+		{json: `"10000000000123"`, want: 123},
+		{json: `10000000000123`, want: 123},
+		{json: `"20000000000045"`, want: 45},
+		{json: `"123"`, want: 123},
+		{json: `123`, want: 123},
+		// the local id of a shard fills the 13 digits
+		{json: `"19999999999999"`, want: 9999999999999},
+		{json: `""`, want: 0},
+		{json: `null`, want: 0},
+		{json: `"12a"`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		var id canvasID
+
+		err := json.Unmarshal([]byte(tt.json), &id)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("Unmarshal(%s) = %v, want error %v", tt.json, err, tt.wantErr)
+			continue
+		}
+
+		if id != tt.want {
+			t.Errorf("Unmarshal(%s) = %d, want %d", tt.json, id, tt.want)
+		}
+	}
+}
+
+func TestNewLiveEvent(t *testing.T) {
+	at := func(value string) time.Time {
+		t.Helper()
+
+		parsed, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return parsed
+	}
+
+	tests := []struct {
+		file         string
+		wantName     string
+		wantTime     time.Time
+		wantCourseID null.Int
+		wantUserID   null.Int
+		wantChange   ungradedChange
+	}{
+		{
+			file:         "submission_created.json",
+			wantName:     "submission_created",
+			wantTime:     at("2026-10-19T01:02:03.456Z"),
+			wantCourseID: null.IntFrom(12),
+			wantUserID:   null.IntFrom(56),
+			wantChange: ungradedChange{submission: &supabase.UngradedSubmission{
+				SubmissionID:    101,
+				CourseID:        12,
+				AssignmentID:    34,
+				UserID:          56,
+				SubmittedAt:     null.StringFrom("2026-10-19T01:02:03Z"),
+				UpdatedAt:       at("2026-10-19T01:02:03.456Z"),
+				AwaitingGrading: true,
+			}},
+		},
+		{
+			file:         "submission_updated_pending_review.json",
+			wantName:     "submission_updated",
+			wantTime:     at("2026-10-19T02:00:00Z"),
+			wantCourseID: null.IntFrom(12),
+			wantUserID:   null.IntFrom(56),
+			wantChange: ungradedChange{submission: &supabase.UngradedSubmission{
+				SubmissionID:    101,
+				CourseID:        12,
+				AssignmentID:    34,
+				UserID:          56,
+				SubmittedAt:     null.StringFrom("2026-10-19T01:02:03Z"),
+				UpdatedAt:       at("2026-10-19T02:00:00Z"),
+				AwaitingGrading: true,
+			}},
+		},
+		{
+			file:         "submission_updated_graded.json",
+			wantName:     "submission_updated",
+			wantTime:     at("2026-10-20T03:04:05Z"),
+			wantCourseID: null.IntFrom(12),
+			wantUserID:   null.IntFrom(56),
+			wantChange: ungradedChange{submission: &supabase.UngradedSubmission{
+				SubmissionID: 101,
+				CourseID:     12,
+				AssignmentID: 34,
+				UserID:       56,
+				SubmittedAt:  null.StringFrom("2026-10-19T01:02:03Z"),
+				UpdatedAt:    at("2026-10-20T03:04:05Z"),
+			}},
+		},
+		{
+			file:         "grade_change.json",
+			wantName:     "grade_change",
+			wantTime:     at("2026-10-20T03:04:05Z"),
+			wantCourseID: null.IntFrom(12),
+			wantUserID:   null.IntFrom(56),
+			wantChange: ungradedChange{submission: &supabase.UngradedSubmission{
+				SubmissionID: 101,
+				CourseID:     12,
+				AssignmentID: 34,
+				UserID:       56,
+				UpdatedAt:    at("2026-10-20T03:04:05Z"),
+			}},
+		},
+		{
+			file:         "grade_change_removed.json",
+			wantName:     "grade_change",
+			wantTime:     at("2026-10-21T09:00:00Z"),
+			wantCourseID: null.IntFrom(12),
+			wantUserID:   null.IntFrom(56),
+		},
+		{
+			file:         "enrollment_updated_concluded.json",
+			wantName:     "enrollment_updated",
+			wantTime:     at("2026-10-22T00:30:00Z"),
+			wantCourseID: null.IntFrom(12),
+			wantUserID:   null.IntFrom(56),
+			wantChange: ungradedChange{endedEnrollment: &supabase.EndedEnrollment{
+				CourseID: 12,
+				UserID:   56,
+				EndedAt:  at("2026-10-22T00:30:00Z"),
+			}},
+		},
+		{
+			file:         "enrollment_updated_teacher.json",
+			wantName:     "enrollment_updated",
+			wantTime:     at("2026-10-22T00:30:00Z"),
+			wantCourseID: null.IntFrom(12),
+			wantUserID:   null.IntFrom(78),
+		},
+		{
+			file:     "course_updated.json",
+			wantName: "course_updated",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			claims := readLiveEvent(t, tt.file)
+
+			event, err := newLiveEvent(claims)
+			if err != nil {
+				t.Fatalf("newLiveEvent(): %v", err)
+			}
+
+			if event.EventName != tt.wantName {
+				t.Errorf("EventName = %q, want %q", event.EventName, tt.wantName)
+			}
+
+			if !event.EventTime.Equal(tt.wantTime) {
+				t.Errorf("EventTime = %s, want %s", event.EventTime, tt.wantTime)
+			}
+
+			if event.CourseID != tt.wantCourseID || event.UserID != tt.wantUserID {
+				t.Errorf("CourseID, UserID = %v, %v, want %v, %v", event.CourseID, event.UserID, tt.wantCourseID, tt.wantUserID)
+			}
+
+			if !reflect.DeepEqual(event.change, tt.wantChange) {
+				t.Errorf("change = %s, want %s", formatChange(event.change), formatChange(tt.wantChange))
+			}
+
+			// ignored events are not stored
+			if tt.wantTime.IsZero() {
+				if event.EventKey != "" {
+					t.Errorf("EventKey = %q, want none", event.EventKey)
+				}
+
+				return
+			}
+
+			if len(event.EventKey) != 64 || !json.Valid(event.Payload) {
+				t.Errorf("EventKey = %q, Payload = %s", event.EventKey, event.Payload)
+			}
+
+			// the same event delivered again has the same key
+			again, err := newLiveEvent(readLiveEvent(t, tt.file))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if again.EventKey != event.EventKey {
+				t.Errorf("EventKey = %q, then %q", event.EventKey, again.EventKey)
+			}
+		})
+	}
+}
+
+func TestNewLiveEventInvalid(t *testing.T) {
+	tests := []struct {
+		name      string
+		file      string
+		edit      func(metadata, body map[string]any)
+		wantField string
+	}{
+		{
+			name: "event time",
+			file: "submission_created.json",
+			edit: func(metadata, body map[string]any) {
+				metadata["event_time"] = "19/10/2026"
+			},
+			wantField: "metadata.event_time",
+		},
+		{
+			name: "submission without id",
+			file: "submission_created.json",
+			edit: func(metadata, body map[string]any) {
+				delete(body, "submission_id")
+			},
+			wantField: "body.submission_id",
+		},
+		{
+			name: "submission outside a course",
+			file: "submission_updated_graded.json",
+			edit: func(metadata, body map[string]any) {
+				metadata["context_type"] = "Account"
+			},
+			wantField: "metadata.context_id",
+		},
+		{
+			name: "grade change without student",
+			file: "grade_change.json",
+			edit: func(metadata, body map[string]any) {
+				body["student_id"] = nil
+			},
+			wantField: "body.student_id",
+		},
+		{
+			name: "enrollment without user",
+			file: "enrollment_updated_concluded.json",
+			edit: func(metadata, body map[string]any) {
+				delete(body, "user_id")
+			},
+			wantField: "body.user_id",
+		},
+		{
+			name: "invalid id",
+			file: "grade_change.json",
+			edit: func(metadata, body map[string]any) {
+				body["submission_id"] = "sub-101"
+			},
+			wantField: "body",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims := readLiveEvent(t, tt.file)
+
+			var metadata, body map[string]any
+
+			if err := json.Unmarshal(claims.Metadata, &metadata); err != nil {
+				t.Fatal(err)
+			}
+
+			if err := json.Unmarshal(claims.Body, &body); err != nil {
+				t.Fatal(err)
+			}
+
+			tt.edit(metadata, body)
+
+			var err error
+
+			if claims.Metadata, err = json.Marshal(metadata); err != nil {
+				t.Fatal(err)
+			}
+
+			if claims.Body, err = json.Marshal(body); err != nil {
+				t.Fatal(err)
+			}
+
+			_, err = newLiveEvent(claims)
+
+			var bindErr *bindError
+			if !errors.As(err, &bindErr) || len(bindErr.Fields) != 1 || bindErr.Fields[0].Field != tt.wantField {
+				t.Errorf("newLiveEvent() = %v, want an error on %s", err, tt.wantField)
+			}
+		})
+	}
+}
+
+func TestVerifyLiveEvent(t *testing.T) {
+	const (
+		issuer   = "https://canvas.example.edu"
+		audience = "canvas-admin/live-events"
+	)
+
+	recorded := readLiveEvent(t, "submission_created.json")
+
+	sign := func(edit func(c *liveEventClaims)) string {
+		c := liveEventClaims{
+			Metadata: recorded.Metadata,
+			Body:     recorded.Body,
+			RegisteredClaims: jwt.RegisteredClaims{
+				Issuer:    issuer,
+				Audience:  jwt.ClaimStrings{audience},
+				IssuedAt:  jwt.NewNumericDate(time.Now()),
+				ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Minute)),
+			},
+		}
+
+		if edit != nil {
+			edit(&c)
+		}
+
+		token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, c).SignedString(testSecret)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return token
+	}
+
+	a := newAuther(AuthConfig(LiveEventsConfig{Secret: testSecret, Issuer: issuer, Audience: audience}))
+
+	tests := []struct {
+		name    string
+		auther  *auther
+		token   string
+		wantErr bool
+	}{
+		{name: "valid", auther: a, token: sign(nil)},
+		{
+			name:   "wrong issuer",
+			auther: a,
+			token: sign(func(c *liveEventClaims) {
+				c.Issuer = testIssuer
+			}),
+			wantErr: true,
+		},
+		{
+			name:   "access token audience",
+			auther: a,
+			token: sign(func(c *liveEventClaims) {
+				c.Audience = jwt.ClaimStrings{testAudience}
+			}),
+			wantErr: true,
+		},
+		{
+			name:   "missing exp",
+			auther: a,
+			token: sign(func(c *liveEventClaims) {
+				c.ExpiresAt = nil
+			}),
+			wantErr: true,
+		},
+		{
+			name:   "issued in the future",
+			auther: a,
+			token: sign(func(c *liveEventClaims) {
+				c.IssuedAt = jwt.NewNumericDate(time.Now().Add(time.Hour))
+			}),
+			wantErr: true,
+		},
+		{
+			name:    "missing config",
+			auther:  newAuther(AuthConfig(LiveEventsConfig{Secret: testSecret})),
+			token:   sign(nil),
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims, err := tt.auther.verifyLiveEvent(tt.token)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("verifyLiveEvent() = %v, want error %v", err, tt.wantErr)
+			}
+
+			if !tt.wantErr {
+				if _, err := newLiveEvent(claims); err != nil {
+					t.Errorf("newLiveEvent() of the verified claims: %v", err)
+				}
+			}
+		})
+	}
+}
+
+func TestLiveUngradedCache(t *testing.T) {
+	counts := []LiveUngradedCount{{AssignmentID: 34, AssignmentName: "Assessment 2", NeedsGradingCount: 3}}
+	assignments := map[int]canvas.Assignment{34: {ID: 34, Name: "Assessment 2"}}
+
+	lc := newLiveUngradedCache()
+
+	_, _, _, version := lc.get(12)
+	lc.set(12, version, counts, 7, assignments)
+
+	if got, gotAssignments, accountID, _ := lc.get(12); len(got) != 1 || len(gotAssignments) != 1 || accountID != 7 {
+		t.Fatalf("get() = %v, %v, %d after set()", got, gotAssignments, accountID)
+	}
+
+	// an event of a known assignment only clears the counts
+	lc.invalidate(12, 34)
+
+	if got, gotAssignments, _, _ := lc.get(12); got != nil || len(gotAssignments) != 1 {
+		t.Errorf("get() = %v, %v after an event of a known assignment", got, gotAssignments)
+	}
+
+	// an event of a new assignment clears the assignments too
+	lc.invalidate(12, 56)
+
+	if got, gotAssignments, _, _ := lc.get(12); got != nil || gotAssignments != nil {
+		t.Errorf("get() = %v, %v after an event of a new assignment", got, gotAssignments)
+	}
+
+	// counts computed before an event are not stored
+	_, _, _, version = lc.get(12)
+	lc.invalidate(12, 0)
+	lc.set(12, version, counts, 7, assignments)
+
+	if got, _, _, _ := lc.get(12); got != nil {
+		t.Errorf("get() = %v, stored after an event", got)
+	}
+
+	// nor computed before the rules changed
+	_, _, _, version = lc.get(12)
+	lc.clear()
+	lc.set(12, version, counts, 7, assignments)
+
+	if got, _, _, _ := lc.get(12); got != nil {
+		t.Errorf("get() = %v, stored after clear()", got)
+	}
+
+	// other courses are left as they are
+	_, _, _, version = lc.get(13)
+	lc.set(13, version, counts, 7, assignments)
+	lc.invalidate(12, 34)
+
+	if got, _, _, _ := lc.get(13); len(got) != 1 {
+		t.Errorf("get() = %v, cleared by an event of another course", got)
+	}
+}
+
+// formatChange shows the changes behind the pointers of an ungradedChange.
+func formatChange(change ungradedChange) string {
+	b, _ := json.Marshal(map[string]any{
+		"submission":       change.submission,
+		"ended_enrollment": change.endedEnrollment,
+	})
+
+	return string(b)
+}
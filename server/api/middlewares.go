@@ -2,19 +2,21 @@ package api
 
 import (
 	"canvas-admin/canvas"
+	"canvas-admin/supabase"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
-	"strconv"
 	"strings"
 
-	"github.com/go-chi/chi/v5"
 	"github.com/golang-jwt/jwt/v5"
 )
 
 type errorResponse struct {
-	Error string `json:"error"`
+	Error  string       `json:"error"`
+	Fields []fieldError `json:"fields,omitempty"`
 }
 
 func withError(next func(w http.ResponseWriter, r *http.Request) (int, error)) http.HandlerFunc {
@@ -25,6 +27,12 @@ func withError(next func(w http.ResponseWriter, r *http.Request) (int, error)) h
 				Error: err.Error(),
 			}
 
+			var bindErr *bindError
+			if errors.As(err, &bindErr) {
+				errResponse.Error = http.StatusText(http.StatusBadRequest)
+				errResponse.Fields = bindErr.Fields
+			}
+
 			if code == http.StatusInternalServerError {
 				log.Printf("%v", err)
 				errResponse.Error = http.StatusText(http.StatusInternalServerError)
@@ -46,46 +54,182 @@ func withError(next func(w http.ResponseWriter, r *http.Request) (int, error)) h
 	return fn
 }
 
+type AuthConfig struct {
+	Secret   []byte // HMAC secret of HS256 tokens
+	JWKSURL  string // public keys of RS256 and ES256 tokens, optional
+	Issuer   string // expected iss claim, required
+	Audience string // expected aud claim, required
+}
+
+// Validate checks the config enforces the iss and aud claims, tokens of any
+// issuer or audience would be accepted otherwise.
+func (config AuthConfig) Validate() error {
+	if config.Issuer == "" {
+		return fmt.Errorf("missing auth config: issuer")
+	}
+
+	if config.Audience == "" {
+		return fmt.Errorf("missing auth config: audience")
+	}
+
+	return nil
+}
+
+const authenticatedRole = "authenticated"
+
 type auther struct {
-	secret []byte
+	secret   []byte
+	jwks     *jwks
+	issuer   string
+	audience string
 }
 
-func newAuther(secret []byte) *auther {
-	return &auther{
-		secret: secret,
+func newAuther(config AuthConfig) *auther {
+	a := &auther{
+		secret:   config.Secret,
+		issuer:   config.Issuer,
+		audience: config.Audience,
 	}
+
+	if config.JWKSURL != "" {
+		a.jwks = newJWKS(config.JWKSURL)
+	}
+
+	return a
 }
 
 type claims struct {
 	Email       string `json:"email"`
+	Role        string `json:"role"`
 	AppMetaData struct {
 		AppRole string `json:"app_role"`
 	} `json:"app_metadata"`
 	jwt.RegisteredClaims
 }
 
-func (a *auther) verifyAccessToken(token string) (email string, err error) {
-	t, err := jwt.ParseWithClaims(token, &claims{}, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+func (a *auther) keyFunc(token *jwt.Token) (interface{}, error) {
+	switch token.Method.(type) {
+	case *jwt.SigningMethodHMAC:
+		if len(a.secret) == 0 {
+			return nil, fmt.Errorf("hmac signed tokens are not accepted")
 		}
 
 		return a.secret, nil
-	})
+	case *jwt.SigningMethodRSA, *jwt.SigningMethodECDSA:
+		if a.jwks == nil {
+			return nil, fmt.Errorf("asymmetric signed tokens are not accepted")
+		}
+
+		kid, _ := token.Header["kid"].(string)
+		if kid == "" {
+			return nil, fmt.Errorf("missing kid header")
+		}
+
+		return a.jwks.key(kid)
+	}
+
+	return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+}
+
+func (a *auther) verifyAccessToken(token string) (*claims, error) {
+	// the parser skips the iss and aud checks when they are empty
+	if a.issuer == "" || a.audience == "" {
+		return nil, fmt.Errorf("error validating token: missing issuer or audience config")
+	}
 
+	opts := []jwt.ParserOption{
+		jwt.WithValidMethods([]string{"HS256", "RS256", "ES256"}),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuer(a.issuer),
+		jwt.WithAudience(a.audience),
+	}
+
+	t, err := jwt.ParseWithClaims(token, &claims{}, a.keyFunc, opts...)
 	if err != nil {
-		return "", fmt.Errorf("error validating token: %w", err)
+		return nil, fmt.Errorf("error validating token: %w", err)
+	}
+
+	claims, ok := t.Claims.(*claims)
+	if !ok {
+		return nil, fmt.Errorf("error validating token")
+	}
+
+	if claims.Role != authenticatedRole {
+		return nil, fmt.Errorf("error validating token: unexpected role: %s", claims.Role)
+	}
+
+	return claims, nil
+}
+
+type appRole string
+
+const (
+	superadminAppRole      appRole = "Superadmin"
+	adminAppRole           appRole = "Admin"
+	complianceAppRole      appRole = "Compliance"
+	studentServicesAppRole appRole = "Student Services"
+)
+
+// A role has access to its value and lower values, same as the web client.
+var appRoleValues = map[appRole]int{
+	superadminAppRole:      4,
+	adminAppRole:           3,
+	complianceAppRole:      2,
+	studentServicesAppRole: 1,
+}
+
+// principal is who made the request, a staff member signed in with Supabase
+// or a service using an API key.
+type principal struct {
+	email   string
+	appRole appRole
+	apiKey  *supabase.APIKey
+}
+
+type contextKey string
+
+const (
+	principalContextKey contextKey = "principal"
+	operationContextKey contextKey = "operation"
+)
+
+// actor names the principal in logs and audit records.
+func (p principal) actor() string {
+	if p.apiKey != nil {
+		return fmt.Sprintf("api key %s (%s)", p.apiKey.Name, p.apiKey.Prefix)
 	}
 
-	if claims, ok := t.Claims.(*claims); ok {
-		return claims.Email, nil
+	return p.email
+}
+
+func principalFromContext(ctx context.Context) principal {
+	p, _ := ctx.Value(principalContextKey).(principal)
+	return p
+}
+
+// withOperation stores the route operation id for the API key scope check.
+func withOperation(operation string, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		next(w, r.WithContext(context.WithValue(r.Context(), operationContextKey, operation)))
 	}
+}
 
-	return "", fmt.Errorf("error validating token")
+func operationFromContext(ctx context.Context) string {
+	operation, _ := ctx.Value(operationContextKey).(string)
+	return operation
 }
 
 func withAuth(c *APIController, next func(w http.ResponseWriter, r *http.Request) (int, error)) func(w http.ResponseWriter, r *http.Request) (int, error) {
 	fn := func(w http.ResponseWriter, r *http.Request) (int, error) {
+		if key := r.Header.Get(apiKeyHeader); key != "" {
+			p, code, err := c.authenticateAPIKey(r, key)
+			if err != nil {
+				return code, err
+			}
+
+			return next(w, r.WithContext(context.WithValue(r.Context(), principalContextKey, p)))
+		}
+
 		authHeader := r.Header.Get("Authorization")
 
 		if authHeader == "" {
@@ -103,25 +247,56 @@ func withAuth(c *APIController, next func(w http.ResponseWriter, r *http.Request
 			return http.StatusUnauthorized, fmt.Errorf("%s", http.StatusText(http.StatusUnauthorized))
 		}
 
-		_, err := c.auther.verifyAccessToken(token)
+		claims, err := c.auther.verifyAccessToken(token)
 		if err != nil {
 			return http.StatusUnauthorized, fmt.Errorf("%s", http.StatusText(http.StatusUnauthorized))
 		}
 
+		p := principal{
+			email:   claims.Email,
+			appRole: appRole(claims.AppMetaData.AppRole),
+		}
+
+		return next(w, r.WithContext(context.WithValue(r.Context(), principalContextKey, p)))
+	}
+
+	return fn
+}
+
+// hasRole tells if the principal is signed in staff with at least the given
+// app role.
+func (p principal) hasRole(role appRole) bool {
+	return p.apiKey == nil && appRoleValues[p.appRole] >= appRoleValues[role]
+}
+
+// withRole only lets signed in staff with at least the given app role through,
+// API keys are rejected. Must be wrapped by withAuth.
+func withRole(role appRole, next func(w http.ResponseWriter, r *http.Request) (int, error)) func(w http.ResponseWriter, r *http.Request) (int, error) {
+	fn := func(w http.ResponseWriter, r *http.Request) (int, error) {
+		p := principalFromContext(r.Context())
+
+		if !p.hasRole(role) {
+			return http.StatusForbidden, fmt.Errorf("%s", http.StatusText(http.StatusForbidden))
+		}
+
 		return next(w, r)
 	}
 
 	return fn
 }
 
+type courseRequest struct {
+	CourseID int `path:"course_id" validate:"required,gt=0"`
+}
+
 func withCourse(c *APIController, next func(w http.ResponseWriter, r *http.Request, course canvas.Course) (int, error)) func(w http.ResponseWriter, r *http.Request) (int, error) {
 	fn := func(w http.ResponseWriter, r *http.Request) (int, error) {
-		courseID, err := strconv.Atoi(chi.URLParam(r, "course_id"))
-		if err != nil {
-			return http.StatusBadRequest, fmt.Errorf("invalid course id")
+		var req courseRequest
+		if err := bind(r, &req); err != nil {
+			return http.StatusBadRequest, err
 		}
 
-		course, code, err := c.canvasClient.GetCourseByID(r.Context(), courseID)
+		course, code, err := c.canvasClient.GetCourseByID(r.Context(), req.CourseID)
 		if err != nil {
 			return code, err
 		}
@@ -132,14 +307,18 @@ func withCourse(c *APIController, next func(w http.ResponseWriter, r *http.Reque
 	return fn
 }
 
+type userRequest struct {
+	UserID int `path:"user_id" validate:"required,gt=0"`
+}
+
 func withUser(c *APIController, next func(w http.ResponseWriter, r *http.Request, user canvas.User) (int, error)) func(w http.ResponseWriter, r *http.Request) (int, error) {
 	fn := func(w http.ResponseWriter, r *http.Request) (int, error) {
-		userID, err := strconv.Atoi(chi.URLParam(r, "user_id"))
-		if err != nil {
-			return http.StatusBadRequest, fmt.Errorf("invalid user id")
+		var req userRequest
+		if err := bind(r, &req); err != nil {
+			return http.StatusBadRequest, err
 		}
 
-		user, code, err := c.canvasClient.GetUserByID(r.Context(), userID)
+		user, code, err := c.canvasClient.GetUserByID(r.Context(), req.UserID)
 		if err != nil {
 			return code, err
 		}
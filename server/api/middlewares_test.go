@@ -0,0 +1,329 @@
+package api
+
+import (
+	"canvas-admin/supabase"
+	"context"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	testIssuer   = "https://example.supabase.co/auth/v1"
+	testAudience = "authenticated"
+)
+
+var testSecret = []byte("super-secret-jwt-token-with-at-least-32-characters")
+
+// signToken signs claims with a test key, or the test secret without one.
+func signToken(t *testing.T, key *testKey, c claims) string {
+	t.Helper()
+
+	var (
+		token   *jwt.Token
+		signKey any = testSecret
+	)
+
+	switch {
+	case key == nil:
+		token = jwt.NewWithClaims(jwt.SigningMethodHS256, c)
+	default:
+		switch k := key.private.(type) {
+		case *rsa.PrivateKey:
+			token = jwt.NewWithClaims(jwt.SigningMethodRS256, c)
+			signKey = k
+		case *ecdsa.PrivateKey:
+			token = jwt.NewWithClaims(jwt.SigningMethodES256, c)
+			signKey = k
+		}
+
+		token.Header["kid"] = key.kid
+	}
+
+	signed, err := token.SignedString(signKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return signed
+}
+
+func validClaims() claims {
+	c := claims{
+		Email: "staff@example.edu",
+		Role:  authenticatedRole,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    testIssuer,
+			Audience:  jwt.ClaimStrings{testAudience},
+			Subject:   "b7d8f1d6-1f0f-4f4b-9d8e-6f1c1a2b3c4d",
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+		},
+	}
+	c.AppMetaData.AppRole = string(adminAppRole)
+
+	return c
+}
+
+func TestVerifyAccessToken(t *testing.T) {
+	rsaKey := newRSAKey(t, "rsa-1")
+	ecKey := newECKey(t, "ec-1")
+	// signs with a kid published by the jwks, but not with its key
+	forged := newRSAKey(t, "rsa-1")
+
+	server := newJWKSServer(t, rsaKey, ecKey)
+
+	a := newAuther(AuthConfig{
+		Secret:   testSecret,
+		JWKSURL:  server.URL,
+		Issuer:   testIssuer,
+		Audience: testAudience,
+	})
+
+	tests := []struct {
+		name    string
+		key     *testKey
+		claims  func(c *claims)
+		token   func(token string) string
+		wantErr bool
+	}{
+		{
+			name: "rs256",
+			key:  &rsaKey,
+		},
+		{
+			name: "es256",
+			key:  &ecKey,
+		},
+		{
+			name: "hs256",
+		},
+		{
+			name:    "bad signature",
+			key:     &forged,
+			wantErr: true,
+		},
+		{
+			name: "tampered payload",
+			key:  &ecKey,
+			token: func(token string) string {
+				// swaps the claims of another token under the signature
+				other := signToken(t, &ecKey, claims{Role: authenticatedRole})
+				parts, otherParts := strings.Split(token, "."), strings.Split(other, ".")
+				return parts[0] + "." + otherParts[1] + "." + parts[2]
+			},
+			wantErr: true,
+		},
+		{
+			name:    "unknown kid",
+			key:     &testKey{kid: "unknown", private: rsaKey.private},
+			wantErr: true,
+		},
+		{
+			name: "expired",
+			key:  &rsaKey,
+			claims: func(c *claims) {
+				c.ExpiresAt = jwt.NewNumericDate(time.Now().Add(-time.Minute))
+			},
+			wantErr: true,
+		},
+		{
+			name: "missing exp",
+			key:  &rsaKey,
+			claims: func(c *claims) {
+				c.ExpiresAt = nil
+			},
+			wantErr: true,
+		},
+		{
+			name: "wrong issuer",
+			key:  &rsaKey,
+			claims: func(c *claims) {
+				c.Issuer = "https://attacker.example.com/auth/v1"
+			},
+			wantErr: true,
+		},
+		{
+			name: "wrong audience",
+			key:  &ecKey,
+			claims: func(c *claims) {
+				c.Audience = jwt.ClaimStrings{"anon"}
+			},
+			wantErr: true,
+		},
+		{
+			name: "anon role",
+			key:  &rsaKey,
+			claims: func(c *claims) {
+				c.Role = "anon"
+			},
+			wantErr: true,
+		},
+		{
+			name: "service role",
+			claims: func(c *claims) {
+				c.Role = "service_role"
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := validClaims()
+			if tt.claims != nil {
+				tt.claims(&c)
+			}
+
+			token := signToken(t, tt.key, c)
+			if tt.token != nil {
+				token = tt.token(token)
+			}
+
+			got, err := a.verifyAccessToken(token)
+
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("verifyAccessToken() = %+v, want an error", got)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("verifyAccessToken(): %v", err)
+			}
+
+			if got.Email != c.Email || got.AppMetaData.AppRole != c.AppMetaData.AppRole {
+				t.Errorf("verifyAccessToken() = %+v, want %+v", got, c)
+			}
+		})
+	}
+}
+
+func TestVerifyAccessTokenRotatedKey(t *testing.T) {
+	oldKey := newRSAKey(t, "old")
+	newKey := newECKey(t, "new")
+
+	server := newJWKSServer(t, oldKey)
+
+	a := newAuther(AuthConfig{
+		JWKSURL:  server.URL,
+		Issuer:   testIssuer,
+		Audience: testAudience,
+	})
+
+	if _, err := a.verifyAccessToken(signToken(t, &oldKey, validClaims())); err != nil {
+		t.Fatalf("verifyAccessToken() with the old key: %v", err)
+	}
+
+	server.rotate(oldKey, newKey)
+
+	a.jwks.mu.Lock()
+	a.jwks.fetchedAt = time.Now().Add(-jwksMinRefresh)
+	a.jwks.mu.Unlock()
+
+	if _, err := a.verifyAccessToken(signToken(t, &newKey, validClaims())); err != nil {
+		t.Fatalf("verifyAccessToken() with the rotated key: %v", err)
+	}
+
+	if got := server.fetches.Load(); got != 2 {
+		t.Errorf("fetches = %d, want 2", got)
+	}
+}
+
+func TestVerifyAccessTokenHMACWithoutSecret(t *testing.T) {
+	server := newJWKSServer(t, newRSAKey(t, "rsa-1"))
+
+	a := newAuther(AuthConfig{
+		JWKSURL:  server.URL,
+		Issuer:   testIssuer,
+		Audience: testAudience,
+	})
+
+	if _, err := a.verifyAccessToken(signToken(t, nil, validClaims())); err == nil {
+		t.Fatal("verifyAccessToken() accepted an hs256 token without a secret")
+	}
+}
+
+func TestVerifyAccessTokenMissingConfig(t *testing.T) {
+	// a token that would pass the checks of a configured auther
+	token := signToken(t, nil, validClaims())
+
+	for _, config := range []AuthConfig{
+		{Secret: testSecret, Audience: testAudience},
+		{Secret: testSecret, Issuer: testIssuer},
+	} {
+		if _, err := newAuther(config).verifyAccessToken(token); err == nil {
+			t.Errorf("verifyAccessToken() with %+v, want an error", config)
+		}
+	}
+}
+
+func TestAuthConfigValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  AuthConfig
+		wantErr bool
+	}{
+		{
+			name:   "valid",
+			config: AuthConfig{Secret: testSecret, Issuer: testIssuer, Audience: testAudience},
+		},
+		{
+			name:    "missing issuer",
+			config:  AuthConfig{Secret: testSecret, Audience: testAudience},
+			wantErr: true,
+		},
+		{
+			name:    "missing audience",
+			config:  AuthConfig{Secret: testSecret, Issuer: testIssuer},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.config.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() = %v, want error %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestWithRole(t *testing.T) {
+	tests := []struct {
+		name      string
+		principal principal
+		want      int
+	}{
+		{name: "superadmin", principal: principal{appRole: superadminAppRole}, want: http.StatusOK},
+		{name: "admin", principal: principal{appRole: adminAppRole}, want: http.StatusOK},
+		{name: "compliance", principal: principal{appRole: complianceAppRole}, want: http.StatusForbidden},
+		{name: "student services", principal: principal{appRole: studentServicesAppRole}, want: http.StatusForbidden},
+		{name: "no role", principal: principal{}, want: http.StatusForbidden},
+		{name: "api key", principal: principal{appRole: superadminAppRole, apiKey: &supabase.APIKey{}}, want: http.StatusForbidden},
+	}
+
+	handler := withRole(adminAppRole, func(w http.ResponseWriter, r *http.Request) (int, error) {
+		return http.StatusOK, nil
+	})
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, "/enrollments/changes", nil)
+			r = r.WithContext(context.WithValue(r.Context(), principalContextKey, tt.principal))
+
+			if got, _ := handler(httptest.NewRecorder(), r); got != tt.want {
+				t.Errorf("withRole() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
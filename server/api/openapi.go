@@ -0,0 +1,152 @@
+package api
+
+import (
+	"canvas-admin/openapi"
+	"encoding/json"
+	"log"
+	"net/http"
+	"reflect"
+	"strings"
+)
+
+const (
+	bearerAuth = "bearerAuth"
+	apiKeyAuth = "apiKeyAuth"
+)
+
+// OpenAPI returns the OpenAPI document of the routes served by NewRouter.
+func OpenAPI() *openapi.Document {
+	return newOpenAPIDocument((&APIController{}).routes())
+}
+
+func newOpenAPIDocument(routes []route) *openapi.Document {
+	doc := openapi.NewDocument("Canvas Report API", "1.0.0")
+
+	doc.Components.SecuritySchemes[bearerAuth] = openapi.SecurityScheme{
+		Type:         "http",
+		Scheme:       "bearer",
+		BearerFormat: "JWT",
+	}
+
+	doc.Components.SecuritySchemes[apiKeyAuth] = openapi.SecurityScheme{
+		Type: "apiKey",
+		In:   "header",
+		Name: apiKeyHeader,
+	}
+
+	errorSchema := doc.SchemaOf(reflect.TypeOf(errorResponse{}))
+
+	errorContent := map[string]openapi.MediaType{
+		openapi.JSON: {Schema: errorSchema},
+	}
+
+	for _, rt := range routes {
+		op := openapi.Operation{
+			OperationID: rt.operation,
+			Summary:     rt.summary,
+			Description: rt.description,
+			Responses: map[string]openapi.Response{
+				"500": {Description: http.StatusText(http.StatusInternalServerError), Content: errorContent},
+			},
+		}
+
+		op.Responses["200"] = openapi.Response{Description: http.StatusText(http.StatusOK)}
+
+		if rt.response != nil || len(rt.downloads) > 0 {
+			content := make(map[string]openapi.MediaType)
+
+			if rt.response != nil {
+				content[openapi.JSON] = openapi.MediaType{Schema: doc.SchemaOf(reflect.TypeOf(rt.response))}
+			}
+
+			for _, contentType := range rt.downloads {
+				content[contentType] = openapi.MediaType{Schema: &openapi.Schema{Type: "string", Format: "binary"}}
+			}
+
+			op.Responses["200"] = openapi.Response{Description: http.StatusText(http.StatusOK), Content: content}
+		}
+
+		if rt.request != nil {
+			op.Parameters = requestParameters(doc, reflect.TypeOf(rt.request))
+			op.Responses["400"] = openapi.Response{Description: http.StatusText(http.StatusBadRequest), Content: errorContent}
+		}
+
+		if rt.body != nil {
+			op.RequestBody = &openapi.RequestBody{
+				Required: true,
+				Content: map[string]openapi.MediaType{
+					openapi.JSON: {Schema: doc.SchemaOf(reflect.TypeOf(rt.body))},
+				},
+			}
+			op.Responses["400"] = openapi.Response{Description: http.StatusText(http.StatusBadRequest), Content: errorContent}
+		}
+
+		if !rt.public {
+			op.Security = []map[string][]string{{bearerAuth: {}}}
+			if !rt.userOnly {
+				op.Security = append(op.Security, map[string][]string{apiKeyAuth: {}})
+			}
+
+			op.Responses["401"] = openapi.Response{Description: http.StatusText(http.StatusUnauthorized), Content: errorContent}
+			op.Responses["403"] = openapi.Response{Description: http.StatusText(http.StatusForbidden), Content: errorContent}
+		}
+
+		doc.AddOperation(rt.method, rt.pattern, op)
+	}
+
+	return doc
+}
+
+// requestParameters describes the path and query fields of a bind request struct.
+func requestParameters(doc *openapi.Document, t reflect.Type) []openapi.Parameter {
+	params := make([]openapi.Parameter, 0, t.NumField())
+
+	for _, f := range reflect.VisibleFields(t) {
+		var p openapi.Parameter
+
+		if name := f.Tag.Get(pathTag); name != "" {
+			p.Name = name
+			p.In = "path"
+			p.Required = true
+		} else if name := f.Tag.Get(queryTag); name != "" {
+			p.Name = name
+			p.In = "query"
+		} else {
+			continue
+		}
+
+		p.Schema = doc.SchemaOf(f.Type)
+
+		for _, rule := range strings.Split(f.Tag.Get("validate"), ",") {
+			if rule == "required" {
+				p.Required = true
+			}
+
+			if values, ok := strings.CutPrefix(rule, "oneof="); ok {
+				schema := p.Schema
+				if schema.Items != nil {
+					schema = schema.Items
+				}
+
+				schema.Enum = strings.Fields(values)
+			}
+		}
+
+		params = append(params, p)
+	}
+
+	return params
+}
+
+func openAPIHandler(routes []route) http.HandlerFunc {
+	doc, err := json.Marshal(newOpenAPIDocument(routes))
+	if err != nil {
+		log.Panicf("error encoding openapi document: %v", err)
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write(doc)
+	}
+}
@@ -0,0 +1,277 @@
+package api
+
+import (
+	"canvas-admin/supabase"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"sort"
+	"time"
+)
+
+const (
+	PostingPosted    = "posted"
+	PostingWouldPost = "would_post" // dry run
+	PostingFailed    = "failed"
+	PostingHidden    = "hidden"
+	PostingWouldHide = "would_hide"
+)
+
+type GradePosting struct {
+	AssignmentID    int                  `json:"assignment_id"`
+	AssignmentTitle string               `json:"assignment_title"`
+	SectionIDs      []int                `json:"section_ids"` // empty for the whole assignment
+	Status          string               `json:"status"`
+	Error           string               `json:"error,omitempty"`
+	ProgressID      string               `json:"progress_id,omitempty"` // of the Canvas job
+	Students        []UnpostedSubmission `json:"students"`              // posted, empty when hiding
+}
+
+type GradePostingResult struct {
+	DryRun      bool           `json:"dry_run"`
+	Posted      int            `json:"posted"` // submissions of the assignments posted
+	Failed      int            `json:"failed"` // assignments failed
+	Assignments []GradePosting `json:"assignments"`
+}
+
+type postGradesBody struct {
+	AssignmentIDs []int `json:"assignment_ids" validate:"dive,gt=0"` // all by default
+	SectionIDs    []int `json:"section_ids" validate:"dive,gt=0"`    // all by default
+	DryRun        bool  `json:"dry_run"`                             // only list the submissions
+}
+
+type hideGradesRequest struct {
+	CourseID     int `path:"course_id" validate:"required,gt=0"`
+	AssignmentID int `path:"assignment_id" validate:"required,gt=0"`
+}
+
+type hideGradesBody struct {
+	SectionIDs []int `json:"section_ids" validate:"dive,gt=0"` // all by default
+	DryRun     bool  `json:"dry_run"`
+}
+
+// PostGradesByCourse posts the graded but hidden submissions of a course to
+// the students, assignment by assignment, limited to some assignments or
+// sections when set. Each posted assignment is audited.
+func (c *APIController) PostGradesByCourse(w http.ResponseWriter, r *http.Request) (int, error) {
+	var req courseRequest
+	if err := bind(r, &req); err != nil {
+		return http.StatusBadRequest, err
+	}
+
+	var body postGradesBody
+	if err := bindJSON(r, &body); err != nil {
+		return http.StatusBadRequest, err
+	}
+
+	course, code, err := c.canvasClient.GetCourseByID(r.Context(), req.CourseID)
+	if err != nil {
+		return code, err
+	}
+
+	unposted, code, err := c.unpostedSubmissions(r.Context(), course, time.Now())
+	if err != nil {
+		return code, err
+	}
+
+	postings := make(map[int]*GradePosting)
+
+	for _, s := range unposted {
+		if len(body.AssignmentIDs) > 0 && !slices.Contains(body.AssignmentIDs, s.AssignmentID) {
+			continue
+		}
+
+		if len(body.SectionIDs) > 0 && !slices.Contains(body.SectionIDs, s.SectionID) {
+			continue
+		}
+
+		posting, ok := postings[s.AssignmentID]
+		if !ok {
+			posting = &GradePosting{
+				AssignmentID:    s.AssignmentID,
+				AssignmentTitle: s.AssignmentTitle,
+				SectionIDs:      make([]int, 0),
+				Students:        make([]UnpostedSubmission, 0),
+			}
+
+			postings[s.AssignmentID] = posting
+		}
+
+		// without a section filter the whole assignment is posted
+		if len(body.SectionIDs) > 0 && !slices.Contains(posting.SectionIDs, s.SectionID) {
+			posting.SectionIDs = append(posting.SectionIDs, s.SectionID)
+		}
+
+		posting.Students = append(posting.Students, s)
+	}
+
+	result := GradePostingResult{
+		DryRun:      body.DryRun,
+		Assignments: make([]GradePosting, 0, len(postings)),
+	}
+
+	for _, posting := range postings {
+		sort.Ints(posting.SectionIDs)
+
+		result.Assignments = append(result.Assignments, *posting)
+	}
+
+	sort.Slice(result.Assignments, func(i, j int) bool {
+		return result.Assignments[i].AssignmentID < result.Assignments[j].AssignmentID
+	})
+
+	if body.DryRun {
+		for i := range result.Assignments {
+			result.Assignments[i].Status = PostingWouldPost
+		}
+	} else {
+		ctx, code, err := c.actAsStaff(r.Context())
+		if err != nil {
+			return code, err
+		}
+
+		logs := make([]supabase.AuditLog, 0, len(result.Assignments))
+
+		for i := range result.Assignments {
+			posting := &result.Assignments[i]
+
+			progress, _, err := c.canvasClient.PostAssignmentGrades(ctx, posting.AssignmentID, posting.SectionIDs)
+			if err != nil {
+				posting.Status, posting.Error = PostingFailed, err.Error()
+				result.Failed++
+
+				continue
+			}
+
+			posting.Status, posting.ProgressID = PostingPosted, progress.ID
+			result.Posted += len(posting.Students)
+
+			// Canvas posts every graded submission of the assignment or
+			// sections, the students listed here are only the ones found
+			// unposted beforehand
+			unpostedUserIDs := make([]int, 0, len(posting.Students))
+
+			for _, s := range posting.Students {
+				unpostedUserIDs = append(unpostedUserIDs, s.UserID)
+			}
+
+			logs = append(logs, supabase.AuditLog{
+				Action:       AuditPostGrades,
+				CourseID:     course.ID,
+				AssignmentID: posting.AssignmentID,
+				SectionIDs:   posting.SectionIDs,
+				Details: map[string]any{
+					"progress_id":       progress.ID,
+					"scope":             postingScope(posting.SectionIDs),
+					"unposted_user_ids": unpostedUserIDs,
+				},
+			})
+		}
+
+		c.audit(ctx, logs...)
+	}
+
+	if err := json.NewEncoder(w).Encode(result); err != nil {
+		return http.StatusInternalServerError, err
+	}
+
+	return http.StatusOK, nil
+}
+
+// HideGradesByAssignment hides the grades of an assignment from the students,
+// of some sections when set, e.g. grades posted by mistake.
+func (c *APIController) HideGradesByAssignment(w http.ResponseWriter, r *http.Request) (int, error) {
+	var req hideGradesRequest
+	if err := bind(r, &req); err != nil {
+		return http.StatusBadRequest, err
+	}
+
+	var body hideGradesBody
+	if err := bindJSON(r, &body); err != nil {
+		return http.StatusBadRequest, err
+	}
+
+	assignment, code, err := c.canvasClient.GetAssignmentByID(r.Context(), req.AssignmentID, req.CourseID, false)
+	if err != nil {
+		return code, err
+	}
+
+	if len(body.SectionIDs) > 0 {
+		sections, code, err := c.canvasClient.GetSectionsByCourseID(r.Context(), req.CourseID)
+		if err != nil {
+			return code, err
+		}
+
+		courseSections := make(map[int]bool, len(sections))
+
+		for _, section := range sections {
+			courseSections[section.ID] = true
+		}
+
+		fields := make([]fieldError, 0)
+
+		for i, sectionID := range body.SectionIDs {
+			if !courseSections[sectionID] {
+				fields = append(fields, fieldError{Field: fmt.Sprintf("section_ids[%d]", i), Message: "is not a section of the course"})
+			}
+		}
+
+		if len(fields) > 0 {
+			return http.StatusBadRequest, &bindError{Fields: fields}
+		}
+	}
+
+	result := GradePosting{
+		AssignmentID:    assignment.ID,
+		AssignmentTitle: assignment.Name,
+		SectionIDs:      body.SectionIDs,
+		Status:          PostingWouldHide,
+		Students:        make([]UnpostedSubmission, 0),
+	}
+
+	if result.SectionIDs == nil {
+		result.SectionIDs = make([]int, 0)
+	}
+
+	if !body.DryRun {
+		ctx, code, err := c.actAsStaff(r.Context())
+		if err != nil {
+			return code, err
+		}
+
+		progress, code, err := c.canvasClient.HideAssignmentGrades(ctx, assignment.ID, body.SectionIDs)
+		if err != nil {
+			return code, err
+		}
+
+		result.Status, result.ProgressID = PostingHidden, progress.ID
+
+		c.audit(ctx, supabase.AuditLog{
+			Action:       AuditHideGrades,
+			CourseID:     req.CourseID,
+			AssignmentID: assignment.ID,
+			SectionIDs:   body.SectionIDs,
+			Details: map[string]any{
+				"progress_id": progress.ID,
+				"scope":       postingScope(body.SectionIDs),
+			},
+		})
+	}
+
+	if err := json.NewEncoder(w).Encode(result); err != nil {
+		return http.StatusInternalServerError, err
+	}
+
+	return http.StatusOK, nil
+}
+
+// postingScope is the students a posting or hiding applies to for the audit
+// log, Canvas does not list them.
+func postingScope(sectionIDs []int) string {
+	if len(sectionIDs) > 0 {
+		return "students of the sections"
+	}
+
+	return "all students"
+}
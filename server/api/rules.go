@@ -0,0 +1,492 @@
+package api
+
+import (
+	"canvas-admin/canvas"
+	"canvas-admin/supabase"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"regexp"
+	"sync"
+	"time"
+
+	"github.com/guregu/null/v5"
+)
+
+const (
+	AuditCreateAssignmentRule = "assignment_rules.create"
+	AuditUpdateAssignmentRule = "assignment_rules.update"
+	AuditDeleteAssignmentRule = "assignment_rules.delete"
+
+	// rules edited on another instance apply after at most this long
+	classificationRulesTTL = 5 * time.Minute
+)
+
+// defaultAssignmentRules apply until the rules are loaded from Supabase.
+var defaultAssignmentRules = []supabase.AssignmentRule{{
+	Name:           "Assessment coversheets",
+	Classification: "coversheet",
+	Exclude:        true,
+	NamePattern:    null.StringFrom(AssessmentCoversheet),
+}}
+
+type assignmentRuleBody struct {
+	Name           string `json:"name" validate:"required,max=100"`
+	Classification string `json:"classification" validate:"required,max=50"`
+	Exclude        bool   `json:"exclude"` // left out of the reports
+	// the conditions, at least one, all of them must match
+	NamePattern       string `json:"name_pattern" validate:"max=200"` // case insensitive regular expression
+	AssignmentGroupID int    `json:"assignment_group_id" validate:"gte=0"`
+	GradingType       string `json:"grading_type" validate:"omitempty,oneof=pass_fail percent letter_grade gpa_scale points not_graded"`
+	AccountID         int    `json:"account_id" validate:"gte=0"` // of the course, or an account above it
+	Priority          int    `json:"priority" validate:"gte=0"`   // 100 by default, lowest first
+}
+
+type assignmentRuleRequest struct {
+	RuleID int `path:"rule_id" validate:"required,gt=0"`
+}
+
+// ruleTarget is what assignment rules match an assignment on.
+type ruleTarget struct {
+	name              string
+	assignmentGroupID int
+	gradingType       string
+	accountID         int
+}
+
+func assignmentTarget(assignment canvas.Assignment, accountID int) ruleTarget {
+	return ruleTarget{
+		name:              assignment.Name,
+		assignmentGroupID: assignment.AssignmentGroupID,
+		gradingType:       assignment.GradingType,
+		accountID:         accountID,
+	}
+}
+
+func submissionTarget(submission canvas.Submission, accountID int) ruleTarget {
+	return ruleTarget{
+		name:              submission.Assignment.Name,
+		assignmentGroupID: submission.Assignment.AssignmentGroupID,
+		gradingType:       submission.Assignment.GradingType,
+		accountID:         accountID,
+	}
+}
+
+type classificationRule struct {
+	supabase.AssignmentRule
+	pattern *regexp.Regexp
+	// the account of the rule and its sub-accounts, nil when they could not
+	// be listed and only the account itself matches
+	accountIDs map[int]bool
+}
+
+// inAccount tells if a course account is the account of the rule or one of
+// its sub-accounts.
+func (rule classificationRule) inAccount(accountID int) bool {
+	if rule.accountIDs == nil {
+		return int(rule.AccountID.Int64) == accountID
+	}
+
+	return rule.accountIDs[accountID]
+}
+
+func (rule classificationRule) match(t ruleTarget) bool {
+	switch {
+	case rule.pattern != nil && !rule.pattern.MatchString(t.name):
+		return false
+	case rule.AssignmentGroupID.Valid && int(rule.AssignmentGroupID.Int64) != t.assignmentGroupID:
+		return false
+	case rule.GradingType.Valid && rule.GradingType.String != t.gradingType:
+		return false
+	case rule.AccountID.Valid && !rule.inAccount(t.accountID):
+		return false
+	}
+
+	return true
+}
+
+// classificationRules classify the assignments of the reports, in the order
+// they apply.
+type classificationRules []classificationRule
+
+// compileNamePattern matches assignment names case insensitively.
+func compileNamePattern(pattern string) (*regexp.Regexp, error) {
+	return regexp.Compile("(?i)" + pattern)
+}
+
+// newClassificationRules compiles the name patterns of the rules, rules with
+// an invalid one are left out.
+func newClassificationRules(records []supabase.AssignmentRule) classificationRules {
+	rules := make(classificationRules, 0, len(records))
+
+	for _, record := range records {
+		rule := classificationRule{AssignmentRule: record}
+
+		if record.NamePattern.Valid && record.NamePattern.String != "" {
+			pattern, err := compileNamePattern(record.NamePattern.String)
+			if err != nil {
+				log.Printf("error compiling name pattern of assignment rule %d: %v", record.ID, err)
+				continue
+			}
+
+			rule.pattern = pattern
+		}
+
+		rules = append(rules, rule)
+	}
+
+	return rules
+}
+
+// match returns the first rule matching an assignment, nil without one.
+func (rules classificationRules) match(t ruleTarget) *classificationRule {
+	for i := range rules {
+		if rules[i].match(t) {
+			return &rules[i]
+		}
+	}
+
+	return nil
+}
+
+// excludes tells if an assignment is left out of the reports.
+func (rules classificationRules) excludes(t ruleTarget) bool {
+	rule := rules.match(t)
+	return rule != nil && rule.Exclude
+}
+
+// matchAccounts tells if a rule has an account condition.
+func (rules classificationRules) matchAccounts() bool {
+	for _, rule := range rules {
+		if rule.AccountID.Valid {
+			return true
+		}
+	}
+
+	return false
+}
+
+// courseAccountID returns the account of a course when the rules match on
+// accounts, 0 otherwise to save the request.
+func (c *APIController) courseAccountID(ctx context.Context, rules classificationRules, courseID int) (int, int, error) {
+	if !rules.matchAccounts() {
+		return 0, http.StatusOK, nil
+	}
+
+	course, code, err := c.canvasClient.GetCourseByID(ctx, courseID)
+	if err != nil {
+		return 0, code, err
+	}
+
+	return course.AccountID, http.StatusOK, nil
+}
+
+// excludedAssignments returns the ids of the assignments of a course left out
+// of the reports.
+func (rules classificationRules) excludedAssignments(assignments []canvas.Assignment, accountID int) map[int]bool {
+	excluded := make(map[int]bool)
+
+	for _, assignment := range assignments {
+		if rules.excludes(assignmentTarget(assignment, accountID)) {
+			excluded[assignment.ID] = true
+		}
+	}
+
+	return excluded
+}
+
+// classificationRulesCache holds the rules loaded from Supabase.
+type classificationRulesCache struct {
+	mu       sync.Mutex
+	rules    classificationRules
+	loadedAt time.Time
+	// closed once the load in flight is done, nil without one
+	loading chan struct{}
+	// a load was done, the default rules no longer apply
+	loaded bool
+	// incremented by invalidate, a load started before an edit is stale
+	generation int
+}
+
+func newClassificationRulesCache() *classificationRulesCache {
+	return &classificationRulesCache{
+		rules: newClassificationRules(defaultAssignmentRules),
+	}
+}
+
+// invalidate reloads the rules on next use, after an edit.
+func (rc *classificationRulesCache) invalidate() {
+	rc.mu.Lock()
+	rc.loadedAt = time.Time{}
+	rc.generation++
+	rc.mu.Unlock()
+}
+
+// classificationRules returns the rules of the reports. When Supabase fails the
+// rules last loaded apply, the default ones before the first load. While the
+// rules are reloaded the previous ones apply, only the first load is waited
+// for.
+func (c *APIController) classificationRules(ctx context.Context) classificationRules {
+	rc := c.classificationRulesCache
+
+	rc.mu.Lock()
+
+	if loading := rc.loading; loading != nil {
+		rules := rc.rules
+		loaded := rc.loaded
+		rc.mu.Unlock()
+
+		if !loaded {
+			// a cancelled request goes on with the default rules
+			select {
+			case <-loading:
+			case <-ctx.Done():
+				return rules
+			}
+
+			rc.mu.Lock()
+			rules = rc.rules
+			rc.mu.Unlock()
+		}
+
+		return rules
+	}
+
+	if !rc.loadedAt.IsZero() && time.Since(rc.loadedAt) < classificationRulesTTL {
+		rules := rc.rules
+		rc.mu.Unlock()
+
+		return rules
+	}
+
+	loading := make(chan struct{})
+	rc.loading = loading
+	generation := rc.generation
+	rc.mu.Unlock()
+
+	rules, err := c.loadClassificationRules(ctx)
+
+	rc.mu.Lock()
+
+	if err != nil {
+		log.Printf("error loading assignment rules: %v", err)
+	} else {
+		rc.rules = rules
+	}
+
+	// retried once the rules expire again, or on next use after an edit
+	if rc.generation == generation {
+		rc.loadedAt = time.Now()
+	}
+
+	rc.loading = nil
+	rc.loaded = true
+	rules = rc.rules
+	rc.mu.Unlock()
+
+	close(loading)
+
+	return rules
+}
+
+// loadClassificationRules loads the rules from Supabase with the sub-accounts
+// of their account conditions.
+func (c *APIController) loadClassificationRules(ctx context.Context) (classificationRules, error) {
+	records, err := c.supabaseClient.GetAssignmentRules()
+	if err != nil {
+		return nil, err
+	}
+
+	rules := newClassificationRules(records)
+
+	trees := make(map[int]map[int]bool)
+
+	for i := range rules {
+		if !rules[i].AccountID.Valid {
+			continue
+		}
+
+		accountID := int(rules[i].AccountID.Int64)
+
+		if _, ok := trees[accountID]; !ok {
+			tree, _, err := c.accountTree(ctx, accountID, nil)
+			if err != nil {
+				log.Printf("error listing the sub-accounts of assignment rule %d: %v", rules[i].ID, err)
+			} else {
+				trees[accountID] = tree.ids()
+			}
+		}
+
+		rules[i].accountIDs = trees[accountID]
+	}
+
+	return rules, nil
+}
+
+func (c *APIController) GetAssignmentRules(w http.ResponseWriter, r *http.Request) (int, error) {
+	rules, err := c.supabaseClient.GetAssignmentRules()
+	if err != nil {
+		return http.StatusInternalServerError, err
+	}
+
+	if err := json.NewEncoder(w).Encode(rules); err != nil {
+		return http.StatusInternalServerError, err
+	}
+
+	return http.StatusOK, nil
+}
+
+func (c *APIController) CreateAssignmentRule(w http.ResponseWriter, r *http.Request) (int, error) {
+	body := assignmentRuleBody{Priority: 100}
+	if err := bindJSON(r, &body); err != nil {
+		return http.StatusBadRequest, err
+	}
+
+	newRule, err := newAssignmentRule(body)
+	if err != nil {
+		return http.StatusBadRequest, err
+	}
+
+	newRule.CreatedBy = principalFromContext(r.Context()).actor()
+
+	rule, err := c.supabaseClient.CreateAssignmentRule(newRule)
+	if err != nil {
+		return http.StatusInternalServerError, err
+	}
+
+	c.classificationRulesCache.invalidate()
+	c.liveUngraded.clear()
+
+	c.audit(r.Context(), supabase.AuditLog{
+		Action:  AuditCreateAssignmentRule,
+		Details: assignmentRuleDetails(rule),
+	})
+
+	if err := json.NewEncoder(w).Encode(rule); err != nil {
+		return http.StatusInternalServerError, err
+	}
+
+	return http.StatusOK, nil
+}
+
+func (c *APIController) UpdateAssignmentRule(w http.ResponseWriter, r *http.Request) (int, error) {
+	var req assignmentRuleRequest
+	if err := bind(r, &req); err != nil {
+		return http.StatusBadRequest, err
+	}
+
+	body := assignmentRuleBody{Priority: 100}
+	if err := bindJSON(r, &body); err != nil {
+		return http.StatusBadRequest, err
+	}
+
+	newRule, err := newAssignmentRule(body)
+	if err != nil {
+		return http.StatusBadRequest, err
+	}
+
+	rule, err := c.supabaseClient.UpdateAssignmentRule(req.RuleID, newRule, time.Now())
+	if errors.Is(err, supabase.ErrNotFound) {
+		return http.StatusNotFound, fmt.Errorf("assignment rule %d not found", req.RuleID)
+	}
+	if err != nil {
+		return http.StatusInternalServerError, err
+	}
+
+	c.classificationRulesCache.invalidate()
+	c.liveUngraded.clear()
+
+	c.audit(r.Context(), supabase.AuditLog{
+		Action:  AuditUpdateAssignmentRule,
+		Details: assignmentRuleDetails(rule),
+	})
+
+	if err := json.NewEncoder(w).Encode(rule); err != nil {
+		return http.StatusInternalServerError, err
+	}
+
+	return http.StatusOK, nil
+}
+
+func (c *APIController) DeleteAssignmentRule(w http.ResponseWriter, r *http.Request) (int, error) {
+	var req assignmentRuleRequest
+	if err := bind(r, &req); err != nil {
+		return http.StatusBadRequest, err
+	}
+
+	rule, err := c.supabaseClient.DeleteAssignmentRule(req.RuleID)
+	if errors.Is(err, supabase.ErrNotFound) {
+		return http.StatusNotFound, fmt.Errorf("assignment rule %d not found", req.RuleID)
+	}
+	if err != nil {
+		return http.StatusInternalServerError, err
+	}
+
+	c.classificationRulesCache.invalidate()
+	c.liveUngraded.clear()
+
+	c.audit(r.Context(), supabase.AuditLog{
+		Action:  AuditDeleteAssignmentRule,
+		Details: assignmentRuleDetails(rule),
+	})
+
+	if err := json.NewEncoder(w).Encode(rule); err != nil {
+		return http.StatusInternalServerError, err
+	}
+
+	return http.StatusOK, nil
+}
+
+// newAssignmentRule validates the conditions of a rule, zero values are no
+// condition.
+func newAssignmentRule(body assignmentRuleBody) (supabase.NewAssignmentRule, error) {
+	rule := supabase.NewAssignmentRule{
+		Name:           body.Name,
+		Classification: body.Classification,
+		Exclude:        body.Exclude,
+		Priority:       body.Priority,
+	}
+
+	if body.NamePattern != "" {
+		if _, err := compileNamePattern(body.NamePattern); err != nil {
+			return rule, &bindError{Fields: []fieldError{{Field: "name_pattern", Message: "must be a valid regular expression"}}}
+		}
+
+		rule.NamePattern = null.StringFrom(body.NamePattern)
+	}
+
+	if body.AssignmentGroupID > 0 {
+		rule.AssignmentGroupID = null.IntFrom(int64(body.AssignmentGroupID))
+	}
+
+	if body.GradingType != "" {
+		rule.GradingType = null.StringFrom(body.GradingType)
+	}
+
+	if body.AccountID > 0 {
+		rule.AccountID = null.IntFrom(int64(body.AccountID))
+	}
+
+	if !rule.NamePattern.Valid && !rule.AssignmentGroupID.Valid && !rule.GradingType.Valid && !rule.AccountID.Valid {
+		return rule, &bindError{Fields: []fieldError{{Field: "name_pattern", Message: "or another condition is required"}}}
+	}
+
+	return rule, nil
+}
+
+func assignmentRuleDetails(rule supabase.AssignmentRule) map[string]any {
+	return map[string]any{
+		"rule_id":             rule.ID,
+		"name":                rule.Name,
+		"classification":      rule.Classification,
+		"exclude":             rule.Exclude,
+		"name_pattern":        rule.NamePattern,
+		"assignment_group_id": rule.AssignmentGroupID,
+		"grading_type":        rule.GradingType,
+		"account_id":          rule.AccountID,
+		"priority":            rule.Priority,
+	}
+}
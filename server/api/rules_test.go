@@ -0,0 +1,181 @@
+package api
+
+import (
+	"canvas-admin/supabase"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/guregu/null/v5"
+)
+
+func TestCompileNamePattern(t *testing.T) {
+	tests := []struct {
+		pattern string
+		name    string
+		want    bool
+	}{
+		{pattern: "coversheet", name: "Assessment Coversheet", want: true},
+		{pattern: "^quiz", name: "Weekly quiz", want: false},
+		{pattern: "^quiz", name: "QUIZ 1", want: true},
+		{pattern: "", name: "anything", want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pattern, func(t *testing.T) {
+			pattern, err := compileNamePattern(tt.pattern)
+			if err != nil {
+				t.Fatalf("compileNamePattern(%q) error = %v", tt.pattern, err)
+			}
+
+			if got := pattern.MatchString(tt.name); got != tt.want {
+				t.Errorf("compileNamePattern(%q) matches %q = %v, want %v", tt.pattern, tt.name, got, tt.want)
+			}
+		})
+	}
+
+	if _, err := compileNamePattern("(unclosed"); err == nil {
+		t.Error("compileNamePattern() of an invalid pattern error = nil")
+	}
+}
+
+func TestNewClassificationRules(t *testing.T) {
+	rules := newClassificationRules([]supabase.AssignmentRule{
+		{ID: 1, NamePattern: null.StringFrom("(unclosed")},
+		{ID: 2, NamePattern: null.StringFrom("")},
+		{ID: 3},
+		{ID: 4, NamePattern: null.StringFrom("draft")},
+	})
+
+	ids := make([]int, 0, len(rules))
+	for _, rule := range rules {
+		ids = append(ids, rule.ID)
+	}
+
+	if len(ids) != 3 || ids[0] != 2 || ids[1] != 3 || ids[2] != 4 {
+		t.Fatalf("newClassificationRules() ids = %v, want [2 3 4]", ids)
+	}
+
+	if rules[0].pattern != nil || rules[1].pattern != nil || rules[2].pattern == nil {
+		t.Errorf("newClassificationRules() patterns = %v, %v, %v, want only the last one", rules[0].pattern, rules[1].pattern, rules[2].pattern)
+	}
+}
+
+func TestClassificationRuleMatch(t *testing.T) {
+	target := ruleTarget{name: "Assessment 1 Coversheet", assignmentGroupID: 7, gradingType: "pass_fail", accountID: 3}
+
+	tests := []struct {
+		name string
+		rule supabase.AssignmentRule
+		// sub-accounts of the account condition, nil when they were not listed
+		accountIDs map[int]bool
+		want       bool
+	}{
+		{name: "no conditions", rule: supabase.AssignmentRule{}, want: true},
+		{name: "no pattern", rule: supabase.AssignmentRule{GradingType: null.StringFrom("pass_fail")}, want: true},
+		{name: "pattern", rule: supabase.AssignmentRule{NamePattern: null.StringFrom("coversheet$")}, want: true},
+		{name: "other pattern", rule: supabase.AssignmentRule{NamePattern: null.StringFrom("^coversheet")}, want: false},
+		{name: "assignment group", rule: supabase.AssignmentRule{AssignmentGroupID: null.IntFrom(7)}, want: true},
+		{name: "other assignment group", rule: supabase.AssignmentRule{AssignmentGroupID: null.IntFrom(8)}, want: false},
+		{name: "other grading type", rule: supabase.AssignmentRule{GradingType: null.StringFrom("points")}, want: false},
+		{name: "account", rule: supabase.AssignmentRule{AccountID: null.IntFrom(3)}, want: true},
+		{name: "other account without sub-accounts", rule: supabase.AssignmentRule{AccountID: null.IntFrom(1)}, want: false},
+		{name: "parent account", rule: supabase.AssignmentRule{AccountID: null.IntFrom(1)}, accountIDs: map[int]bool{1: true, 2: true, 3: true}, want: true},
+		{name: "sibling account", rule: supabase.AssignmentRule{AccountID: null.IntFrom(2)}, accountIDs: map[int]bool{2: true, 4: true}, want: false},
+		{
+			name: "every condition",
+			rule: supabase.AssignmentRule{
+				NamePattern:       null.StringFrom("coversheet"),
+				AssignmentGroupID: null.IntFrom(7),
+				GradingType:       null.StringFrom("pass_fail"),
+				AccountID:         null.IntFrom(3),
+			},
+			want: true,
+		},
+		{
+			name: "one condition failing",
+			rule: supabase.AssignmentRule{
+				NamePattern:       null.StringFrom("coversheet"),
+				AssignmentGroupID: null.IntFrom(7),
+				GradingType:       null.StringFrom("points"),
+			},
+			want: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule := newClassificationRules([]supabase.AssignmentRule{tt.rule})[0]
+			rule.accountIDs = tt.accountIDs
+
+			if got := rule.match(target); got != tt.want {
+				t.Errorf("match() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestClassificationRulesMatch(t *testing.T) {
+	rules := newClassificationRules([]supabase.AssignmentRule{
+		{ID: 1, Classification: "coversheet", Exclude: true, NamePattern: null.StringFrom("coversheet")},
+		{ID: 2, Classification: "practice", GradingType: null.StringFrom("not_graded")},
+		{ID: 3, Classification: "assessment", NamePattern: null.StringFrom("assessment")},
+	})
+
+	tests := []struct {
+		name        string
+		rules       classificationRules
+		target      ruleTarget
+		wantID      int
+		wantExclude bool
+	}{
+		{name: "no rules", rules: classificationRules{}, target: ruleTarget{name: "Assessment 1"}},
+		{name: "no match", rules: rules, target: ruleTarget{name: "Reflection", gradingType: "points"}},
+		{name: "first rule wins", rules: rules, target: ruleTarget{name: "Assessment 1 coversheet"}, wantID: 1, wantExclude: true},
+		{name: "in order", rules: rules, target: ruleTarget{name: "Assessment practice", gradingType: "not_graded"}, wantID: 2},
+		{name: "last rule", rules: rules, target: ruleTarget{name: "Assessment 2", gradingType: "points"}, wantID: 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			id := 0
+			if rule := tt.rules.match(tt.target); rule != nil {
+				id = rule.ID
+			}
+
+			if id != tt.wantID {
+				t.Errorf("match() = rule %d, want %d", id, tt.wantID)
+			}
+
+			if got := tt.rules.excludes(tt.target); got != tt.wantExclude {
+				t.Errorf("excludes() = %v, want %v", got, tt.wantExclude)
+			}
+		})
+	}
+}
+
+func TestClassificationRulesFirstLoadCancelled(t *testing.T) {
+	rc := newClassificationRulesCache()
+	// a first load that never ends
+	rc.loading = make(chan struct{})
+
+	c := &APIController{classificationRulesCache: rc}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	done := make(chan classificationRules)
+
+	go func() {
+		done <- c.classificationRules(ctx)
+	}()
+
+	select {
+	case rules := <-done:
+		if len(rules) != len(defaultAssignmentRules) || rules[0].Name != defaultAssignmentRules[0].Name {
+			t.Errorf("classificationRules() = %+v, want the default rules", rules)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("classificationRules() waited for the first load after the context was cancelled")
+	}
+}
@@ -0,0 +1,252 @@
+package api
+
+import (
+	"canvas-admin/canvas"
+	"canvas-admin/supabase"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+const (
+	SessionsTerminated     = "terminated"
+	SessionsWouldTerminate = "would_terminate" // dry run
+	SessionsFailed         = "failed"
+	SessionsUnresolved     = "unresolved" // SIS id without a Canvas user
+
+	AuditTerminateSessions       = "sessions.terminate"
+	AuditTerminateMobileSessions = "mobile_sessions.terminate"
+
+	// sessions of this many users are terminated at a time
+	sessionsConcurrency = 5
+
+	// a batch is terminated within the request, well under the router timeout
+	sessionsMaxUsers = 200
+)
+
+type SessionTermination struct {
+	UserID    int    `json:"user_id"` // 0 when unresolved
+	SISUserID string `json:"sis_user_id"`
+	Name      string `json:"name"`
+	Source    string `json:"source"` // the target the user was found by, e.g. "section 12"
+	Status    string `json:"status"`
+	Error     string `json:"error,omitempty"`
+
+	// the section or course of Source, for the audit log
+	sectionID int
+	courseID  int
+}
+
+type BatchSessionTerminations struct {
+	DryRun     bool                 `json:"dry_run"`
+	Terminated int                  `json:"terminated"`
+	Failed     int                  `json:"failed"`
+	Unresolved int                  `json:"unresolved"`
+	Users      []SessionTermination `json:"users"`
+}
+
+// batchSessionsBody targets the active students of sections and courses, and
+// users by SIS id.
+type batchSessionsBody struct {
+	SectionIDs []int    `json:"section_ids" validate:"dive,gt=0"`
+	CourseIDs  []int    `json:"course_ids" validate:"dive,gt=0"`
+	SISIDs     []string `json:"sis_ids" validate:"dive,required"`
+	DryRun     bool     `json:"dry_run"` // only list the users
+}
+
+// TerminateBatchSessions logs out every targeted user, e.g. a cohort during
+// an assessment integrity incident. A failed termination does not stop the
+// others, the outcome of each user is returned.
+func (c *APIController) TerminateBatchSessions(w http.ResponseWriter, r *http.Request) (int, error) {
+	var body batchSessionsBody
+	if err := bindJSON(r, &body); err != nil {
+		return http.StatusBadRequest, err
+	}
+
+	if len(body.SectionIDs) == 0 && len(body.CourseIDs) == 0 && len(body.SISIDs) == 0 {
+		return http.StatusBadRequest, &bindError{Fields: []fieldError{{Field: "body", Message: "must target at least 1 section, course or SIS id"}}}
+	}
+
+	ctx, code, err := c.actAsStaff(r.Context())
+	if err != nil {
+		return code, err
+	}
+
+	users, code, err := c.sessionTargets(ctx, body)
+	if err != nil {
+		return code, err
+	}
+
+	if len(users) > sessionsMaxUsers {
+		return http.StatusBadRequest, &bindError{Fields: []fieldError{{Field: "body", Message: fmt.Sprintf("must target at most %d users, got %d", sessionsMaxUsers, len(users))}}}
+	}
+
+	var wg sync.WaitGroup
+
+	sem := make(chan struct{}, sessionsConcurrency)
+
+	for i := range users {
+		if users[i].Status == SessionsUnresolved {
+			continue
+		}
+
+		if body.DryRun {
+			users[i].Status = SessionsWouldTerminate
+			continue
+		}
+
+		wg.Add(1)
+
+		go func(u *SessionTermination) {
+			defer wg.Done()
+
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			if ctx.Err() != nil {
+				u.Status, u.Error = SessionsFailed, ctx.Err().Error()
+				return
+			}
+
+			if _, err := c.canvasClient.TerminateUserSessions(ctx, u.UserID); err != nil {
+				u.Status, u.Error = SessionsFailed, err.Error()
+				return
+			}
+
+			u.Status = SessionsTerminated
+		}(&users[i])
+	}
+
+	wg.Wait()
+
+	results := BatchSessionTerminations{DryRun: body.DryRun, Users: users}
+
+	logs := make([]supabase.AuditLog, 0)
+
+	for _, u := range users {
+		switch u.Status {
+		case SessionsTerminated:
+			results.Terminated++
+
+			logs = append(logs, sessionTerminationAuditLog(u))
+		case SessionsFailed:
+			results.Failed++
+
+			logs = append(logs, sessionTerminationAuditLog(u))
+		case SessionsUnresolved:
+			results.Unresolved++
+		}
+	}
+
+	c.audit(ctx, logs...)
+
+	if err := json.NewEncoder(w).Encode(results); err != nil {
+		return http.StatusInternalServerError, err
+	}
+
+	return http.StatusOK, nil
+}
+
+// sessionTargets resolves the targets to users, each once, in the order of the
+// sections, courses and SIS ids.
+func (c *APIController) sessionTargets(ctx context.Context, body batchSessionsBody) ([]SessionTermination, int, error) {
+	results := make([]SessionTermination, 0)
+	seen := make(map[int]bool)
+
+	add := func(enrollments []canvas.Enrollment, sectionID, courseID int, source string) {
+		for _, enrollment := range enrollments {
+			if seen[enrollment.UserID] {
+				continue
+			}
+
+			seen[enrollment.UserID] = true
+
+			results = append(results, SessionTermination{
+				UserID:    enrollment.UserID,
+				SISUserID: enrollment.User.SISUserID,
+				Name:      enrollment.User.Name,
+				Source:    source,
+				sectionID: sectionID,
+				courseID:  courseID,
+			})
+		}
+	}
+
+	states := []canvas.EnrollmentState{canvas.ActiveEnrollment}
+
+	types := []canvas.EnrollmentType{canvas.StudentEnrollment}
+
+	for _, sectionID := range body.SectionIDs {
+		enrollments, code, err := c.canvasClient.GetEnrollmentsBySectionID(ctx, sectionID, states, types)
+		if err != nil {
+			return nil, code, err
+		}
+
+		add(enrollments, sectionID, 0, fmt.Sprintf("section %d", sectionID))
+	}
+
+	for _, courseID := range body.CourseIDs {
+		enrollments, code, err := c.canvasClient.GetEnrollmentsByCourseID(ctx, courseID, states, types)
+		if err != nil {
+			return nil, code, err
+		}
+
+		add(enrollments, 0, courseID, fmt.Sprintf("course %d", courseID))
+	}
+
+	for _, sisID := range body.SISIDs {
+		sisID = strings.TrimSpace(sisID)
+
+		user, code, err := c.canvasClient.GetUserBySisID(ctx, sisID)
+		if code == http.StatusNotFound {
+			results = append(results, SessionTermination{SISUserID: sisID, Source: "sis_id", Status: SessionsUnresolved})
+			continue
+		}
+		if err != nil {
+			return nil, code, err
+		}
+
+		if seen[user.ID] {
+			continue
+		}
+
+		seen[user.ID] = true
+
+		results = append(results, SessionTermination{
+			UserID:    user.ID,
+			SISUserID: user.SISUserID,
+			Name:      user.Name,
+			Source:    "sis_id",
+		})
+	}
+
+	return results, http.StatusOK, nil
+}
+
+// sessionTerminationAuditLog records a terminated user, or a failed attempt,
+// with the target they were found by.
+func sessionTerminationAuditLog(u SessionTermination) supabase.AuditLog {
+	entry := supabase.AuditLog{
+		Action:   AuditTerminateSessions,
+		CourseID: u.courseID,
+		UserIDs:  []int{u.UserID},
+		Details: map[string]any{
+			"source":      u.Source,
+			"sis_user_id": u.SISUserID,
+			"status":      u.Status,
+		},
+	}
+
+	if u.Error != "" {
+		entry.Details["error"] = u.Error
+	}
+
+	if u.sectionID != 0 {
+		entry.SectionIDs = []int{u.sectionID}
+	}
+
+	return entry
+}
@@ -0,0 +1,333 @@
+package api
+
+import (
+	"canvas-admin/canvas"
+	"canvas-admin/sla"
+	"canvas-admin/supabase"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+)
+
+type SLABreach struct {
+	sla.Alert
+	Level    sla.Level `json:"level"`
+	Teachers []string  `json:"teachers"` // login ids of the teachers of the section
+}
+
+type SLAScanResult struct {
+	Breaches      []SLABreach `json:"breaches"`
+	Notifications int         `json:"notifications"` // notifications sent by this scan
+	Alerts        int         `json:"alerts"`        // breaches alerted for the first time at their level
+}
+
+type slaScanRequest struct {
+	AccountIDs []int `json:"account_ids" validate:"required,min=1,dive,gt=0"`
+	DryRun     bool  `json:"dry_run,omitempty"` // report the alerts due without sending them
+}
+
+func (c *APIController) GetSLABreachesByAccountID(w http.ResponseWriter, r *http.Request) (int, error) {
+	var req accountReportRequest
+	if err := bind(r, &req); err != nil {
+		return http.StatusBadRequest, err
+	}
+
+	filter, err := req.coursesFilter()
+	if err != nil {
+		return http.StatusBadRequest, err
+	}
+
+	results, code, err := c.slaBreaches(r.Context(), req.AccountID, filter)
+	if err != nil {
+		return code, err
+	}
+
+	if err := json.NewEncoder(w).Encode(results); err != nil {
+		return http.StatusInternalServerError, err
+	}
+
+	return http.StatusOK, nil
+}
+
+// ScanGradingSLA alerts the teachers of submissions waiting for grading past
+// the SLA, and the account managers once breaches reach the escalation. Each
+// submission attempt is alerted once per level, so the scan can be scheduled
+// as often as needed, e.g. by a job with an API key.
+func (c *APIController) ScanGradingSLA(w http.ResponseWriter, r *http.Request) (int, error) {
+	var req slaScanRequest
+	if err := bindJSON(r, &req); err != nil {
+		return http.StatusBadRequest, err
+	}
+
+	result := SLAScanResult{
+		Breaches: make([]SLABreach, 0),
+	}
+
+	for _, accountID := range req.AccountIDs {
+		breaches, code, err := c.slaBreaches(r.Context(), accountID, canvas.CoursesFilter{})
+		if err != nil {
+			return code, err
+		}
+
+		if !req.DryRun {
+			notifications, alerts, err := c.notifySLABreaches(r.Context(), accountID, breaches)
+			if err != nil {
+				return http.StatusInternalServerError, err
+			}
+
+			result.Notifications += notifications
+			result.Alerts += alerts
+		}
+
+		result.Breaches = append(result.Breaches, breaches...)
+	}
+
+	log.Printf("%s scanned grading sla of accounts %v: %d breaches, %d notifications", principalFromContext(r.Context()).actor(), req.AccountIDs, len(result.Breaches), result.Notifications)
+
+	if err := json.NewEncoder(w).Encode(result); err != nil {
+		return http.StatusInternalServerError, err
+	}
+
+	return http.StatusOK, nil
+}
+
+// slaBreaches returns the submitted but ungraded submissions of the available
+// courses of an account older than the SLA, the oldest first.
+func (c *APIController) slaBreaches(ctx context.Context, accountID int, filter canvas.CoursesFilter) ([]SLABreach, int, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	types := []canvas.CourseEnrollmentType{canvas.StudentCourseEnrollment}
+
+	courses, code, err := c.canvasClient.GetCoursesByAccountID(ctx, accountID, "", types, filter)
+	if err != nil {
+		return nil, code, err
+	}
+
+	now := time.Now()
+
+	rules := c.classificationRules(ctx)
+
+	results := make([]SLABreach, 0)
+
+	for _, course := range courses {
+		select {
+		case <-ctx.Done():
+			return nil, http.StatusRequestTimeout, ctx.Err()
+		default:
+			{
+				if course.WorkflowState != string(canvas.AvailableCourseWorkflowState) {
+					continue
+				}
+
+				_, sectionIDs, code, err := c.needsGradingBySection(ctx, course)
+				if err != nil {
+					return nil, code, err
+				}
+
+				// holds sections with teachers names
+				sectionsWithTeachersMap := make(map[int]sectionWithTeachers)
+
+				// students in many sections are reported in the first one
+				seen := make(map[int]bool)
+
+				for _, sectionID := range sectionIDs {
+					st, code, err := c.getSectionWithTeachers(ctx, sectionsWithTeachersMap, sectionID)
+					if err != nil {
+						return nil, code, err
+					}
+
+					submissions, code, err := c.canvasClient.GetSubmissionsBySectionID(ctx, sectionID, canvas.SubmittedSubmissionWorkflowState)
+					if err != nil {
+						return nil, code, err
+					}
+
+					teachers := make([]string, 0, len(st.teachers))
+
+					for _, teacher := range st.teachers {
+						if teacher.LoginID != "" {
+							teachers = append(teachers, teacher.LoginID)
+						}
+					}
+
+					for _, submission := range submissions {
+						if seen[submission.ID] || rules.excludes(submissionTarget(submission, course.AccountID)) {
+							continue
+						}
+
+						seen[submission.ID] = true
+
+						submittedAt, err := time.Parse(time.RFC3339, submission.SubmittedAt.String)
+						if err != nil {
+							continue
+						}
+
+						days := c.slaConfig.Calendar.BusinessDaysBetween(submittedAt, now)
+
+						level, ok := c.slaConfig.Level(days)
+						if !ok {
+							continue
+						}
+
+						breach := SLABreach{
+							Alert: sla.Alert{
+								SubmissionID:    submission.ID,
+								Attempt:         int(submission.Attempt.Int64),
+								AccountID:       course.AccountID,
+								AccountName:     course.Account.Name,
+								CourseID:        course.ID,
+								CourseName:      course.Name,
+								Section:         st.sisSectionID,
+								AssignmentID:    submission.AssignmentID,
+								AssignmentTitle: submission.Assignment.Name,
+								UserID:          submission.UserID,
+								SubmittedAt:     submission.SubmittedAt.String,
+								BusinessDays:    days,
+								SpeedGraderUrl: fmt.Sprintf("%s/courses/%d/gradebook/speed_grader?assignment_id=%d&student_id=%d",
+									c.canvasClient.HtmlUrl, course.ID, submission.AssignmentID, submission.UserID),
+							},
+							Level:    level,
+							Teachers: teachers,
+						}
+
+						results = append(results, breach)
+					}
+				}
+			}
+		}
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].BusinessDays > results[j].BusinessDays
+	})
+
+	return results, http.StatusOK, nil
+}
+
+// notifySLABreaches sends the alerts not sent yet, one notification per level
+// and recipients. Breaches of sections without teachers go to the managers.
+// Alerts without any recipient are not recorded, so they are sent once a
+// contact is added.
+func (c *APIController) notifySLABreaches(ctx context.Context, accountID int, breaches []SLABreach) (notifications int, alerts int, err error) {
+	if len(breaches) == 0 {
+		return 0, 0, nil
+	}
+
+	submissionIDs := make([]int, 0, len(breaches))
+	accountIDs := []int{accountID}
+
+	for _, b := range breaches {
+		submissionIDs = append(submissionIDs, b.SubmissionID)
+
+		if !slices.Contains(accountIDs, b.AccountID) {
+			accountIDs = append(accountIDs, b.AccountID)
+		}
+	}
+
+	sent, err := c.supabaseClient.GetSLAAlertsBySubmissionIDs(submissionIDs)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	sentKeys := make(map[string]bool, len(sent))
+
+	for _, a := range sent {
+		sentKeys[slaAlertKey(a.SubmissionID, a.Attempt, sla.Level(a.Level))] = true
+	}
+
+	contacts, err := c.supabaseClient.GetSLAEscalationContacts(accountIDs)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	managers := make(map[int][]string)
+
+	for _, contact := range contacts {
+		managers[contact.AccountID] = append(managers[contact.AccountID], contact.Email)
+	}
+
+	groups := make(map[string]*sla.Notification)
+	keys := make([]string, 0)
+
+	add := func(level sla.Level, recipients []string, b SLABreach) {
+		if sentKeys[slaAlertKey(b.SubmissionID, b.Attempt, level)] {
+			return
+		}
+
+		if len(recipients) == 0 {
+			log.Printf("no recipients for sla %s of submission %d in course %d", level, b.SubmissionID, b.CourseID)
+			return
+		}
+
+		recipients = slices.Clone(recipients)
+		slices.Sort(recipients)
+		recipients = slices.Compact(recipients)
+
+		key := string(level) + ":" + strings.Join(recipients, ",")
+
+		if _, ok := groups[key]; !ok {
+			groups[key] = &sla.Notification{Level: level, Recipients: recipients, Alerts: make([]sla.Alert, 0)}
+			keys = append(keys, key)
+		}
+
+		groups[key].Alerts = append(groups[key].Alerts, b.Alert)
+	}
+
+	for _, b := range breaches {
+		// managers of the course's sub-account, and of the scanned account
+		accountManagers := append(slices.Clone(managers[b.AccountID]), managers[accountID]...)
+
+		if len(b.Teachers) > 0 {
+			add(sla.BreachLevel, b.Teachers, b)
+		} else {
+			add(sla.BreachLevel, accountManagers, b)
+		}
+
+		if b.Level == sla.EscalationLevel {
+			add(sla.EscalationLevel, accountManagers, b)
+		}
+	}
+
+	for _, key := range keys {
+		n := groups[key]
+
+		if err := c.slaConfig.Notifier.Notify(ctx, *n); err != nil {
+			return notifications, alerts, fmt.Errorf("error sending sla %s notification: %w", n.Level, err)
+		}
+
+		notifications++
+
+		records := make([]supabase.SLAAlert, 0, len(n.Alerts))
+
+		for _, a := range n.Alerts {
+			records = append(records, supabase.SLAAlert{
+				SubmissionID: a.SubmissionID,
+				Attempt:      a.Attempt,
+				Level:        string(n.Level),
+				AccountID:    a.AccountID,
+				CourseID:     a.CourseID,
+				AssignmentID: a.AssignmentID,
+				UserID:       a.UserID,
+				Recipients:   n.Recipients,
+			})
+		}
+
+		if err := c.supabaseClient.CreateSLAAlerts(records); err != nil {
+			return notifications, alerts, err
+		}
+
+		alerts += len(records)
+	}
+
+	return notifications, alerts, nil
+}
+
+func slaAlertKey(submissionID, attempt int, level sla.Level) string {
+	return fmt.Sprintf("%d:%d:%s", submissionID, attempt, level)
+}
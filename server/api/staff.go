@@ -0,0 +1,59 @@
+package api
+
+import (
+	"canvas-admin/canvas"
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+)
+
+// staffUsers caches the Canvas user ids of staff emails.
+type staffUsers struct {
+	mu  sync.Mutex
+	ids map[string]int
+}
+
+func newStaffUsers() *staffUsers {
+	return &staffUsers{
+		ids: make(map[string]int),
+	}
+}
+
+// actAsStaff returns a context for Canvas write actions made on behalf of the
+// signed in staff member, so they are attributed to them in Canvas. Requests
+// made with an API key, or with masquerading disabled, act as the access
+// token owner.
+func (c *APIController) actAsStaff(ctx context.Context) (context.Context, int, error) {
+	p := principalFromContext(ctx)
+
+	if !c.canvasClient.Masquerade || p.apiKey != nil {
+		return ctx, http.StatusOK, nil
+	}
+
+	if p.email == "" {
+		return ctx, http.StatusForbidden, fmt.Errorf("missing email of signed in user")
+	}
+
+	c.staffUsers.mu.Lock()
+	userID, ok := c.staffUsers.ids[p.email]
+	c.staffUsers.mu.Unlock()
+
+	if !ok {
+		user, code, err := c.canvasClient.GetUserByLoginID(ctx, p.email)
+		if code == http.StatusNotFound {
+			return ctx, http.StatusForbidden, fmt.Errorf("no canvas user with login %s", p.email)
+		}
+		if err != nil {
+			return ctx, code, err
+		}
+
+		userID = user.ID
+
+		c.staffUsers.mu.Lock()
+		c.staffUsers.ids[p.email] = userID
+		c.staffUsers.mu.Unlock()
+	}
+
+	return canvas.WithActingUser(ctx, userID), http.StatusOK, nil
+}
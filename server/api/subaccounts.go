@@ -0,0 +1,266 @@
+package api
+
+import (
+	"canvas-admin/canvas"
+	"context"
+	"encoding/json"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/guregu/null/v5"
+)
+
+// sub-accounts created in Canvas are known after at most this long
+const accountSubtreeTTL = 10 * time.Minute
+
+type AccountTree struct {
+	ID              int           `json:"id"`
+	Name            string        `json:"name"`
+	ParentAccountID null.Int      `json:"parent_account_id"`
+	Depth           int           `json:"depth"`   // 0 for the account of the tree
+	Courses         int           `json:"courses"` // courses of the account and its sub-accounts
+	SubAccounts     []AccountTree `json:"sub_accounts"`
+}
+
+// AccountSubtotal is the total of a report for an account and its
+// sub-accounts.
+type AccountSubtotal struct {
+	AccountID int    `json:"account_id"`
+	Name      string `json:"name"`
+	Depth     int    `json:"depth"`
+	Courses   int    `json:"courses"`
+	Count     int    `json:"count"`
+}
+
+// recursiveAccountReportRequest adds subtotals for every sub-account of the
+// tree to a report. Canvas lists the courses of the sub-accounts of an account
+// either way.
+type recursiveAccountReportRequest struct {
+	accountReportRequest
+	Recursive bool `query:"recursive"`
+}
+
+// GetAccountTree returns an account and all its sub-accounts with the count
+// of the courses with students at each level.
+func (c *APIController) GetAccountTree(w http.ResponseWriter, r *http.Request) (int, error) {
+	var req accountReportRequest
+	if err := bind(r, &req); err != nil {
+		return http.StatusBadRequest, err
+	}
+
+	filter, err := req.coursesFilter()
+	if err != nil {
+		return http.StatusBadRequest, err
+	}
+
+	types := []canvas.CourseEnrollmentType{canvas.StudentCourseEnrollment}
+
+	courses, code, err := c.canvasClient.GetCoursesByAccountID(r.Context(), req.AccountID, "", types, filter)
+	if err != nil {
+		return code, err
+	}
+
+	tree, code, err := c.accountTree(r.Context(), req.AccountID, courses)
+	if err != nil {
+		return code, err
+	}
+
+	if err := json.NewEncoder(w).Encode(tree); err != nil {
+		return http.StatusInternalServerError, err
+	}
+
+	return http.StatusOK, nil
+}
+
+// accountTree returns the tree of an account with its courses counted at each
+// level, the sub-accounts in name order.
+func (c *APIController) accountTree(ctx context.Context, accountID int, courses []canvas.Course) (AccountTree, int, error) {
+	account, code, err := c.canvasClient.GetAccountByID(ctx, accountID)
+	if err != nil {
+		return AccountTree{}, code, err
+	}
+
+	subAccounts, code, err := c.canvasClient.GetSubAccountsByAccountID(ctx, accountID, true)
+	if err != nil {
+		return AccountTree{}, code, err
+	}
+
+	children := make(map[int][]canvas.Account)
+
+	for _, a := range subAccounts {
+		children[int(a.ParentAccountID.Int64)] = append(children[int(a.ParentAccountID.Int64)], a)
+	}
+
+	var build func(a canvas.Account, depth int) AccountTree
+
+	build = func(a canvas.Account, depth int) AccountTree {
+		node := AccountTree{
+			ID:              a.ID,
+			Name:            a.Name,
+			ParentAccountID: a.ParentAccountID,
+			Depth:           depth,
+			SubAccounts:     make([]AccountTree, 0, len(children[a.ID])),
+		}
+
+		sort.Slice(children[a.ID], func(i, j int) bool {
+			return children[a.ID][i].Name < children[a.ID][j].Name
+		})
+
+		for _, child := range children[a.ID] {
+			node.SubAccounts = append(node.SubAccounts, build(child, depth+1))
+		}
+
+		return node
+	}
+
+	tree := build(account, 0)
+
+	counts := make(map[int]int)
+
+	for _, course := range courses {
+		counts[course.AccountID]++
+	}
+
+	tree.rollup(counts, func(t *AccountTree, count int) { t.Courses = count })
+
+	return tree, http.StatusOK, nil
+}
+
+// rollup sets the count of each account and its sub-accounts with set, counts
+// of accounts outside the tree go to its root.
+func (t *AccountTree) rollup(counts map[int]int, set func(t *AccountTree, count int)) {
+	outside := 0
+	ids := t.ids()
+
+	for id, count := range counts {
+		if !ids[id] {
+			outside += count
+		}
+	}
+
+	var walk func(node *AccountTree) int
+
+	walk = func(node *AccountTree) int {
+		total := counts[node.ID]
+
+		for i := range node.SubAccounts {
+			total += walk(&node.SubAccounts[i])
+		}
+
+		set(node, total)
+
+		return total
+	}
+
+	set(t, walk(t)+outside)
+}
+
+func (t AccountTree) ids() map[int]bool {
+	ids := map[int]bool{t.ID: true}
+
+	for _, sub := range t.SubAccounts {
+		for id := range sub.ids() {
+			ids[id] = true
+		}
+	}
+
+	return ids
+}
+
+// ancestors maps the id of every account of the tree to the ids of the
+// account and the accounts above it.
+func (t AccountTree) ancestors() map[int][]int {
+	results := make(map[int][]int)
+
+	var walk func(node AccountTree, path []int)
+
+	walk = func(node AccountTree, path []int) {
+		path = append(path[:len(path):len(path)], node.ID)
+		results[node.ID] = path
+
+		for _, sub := range node.SubAccounts {
+			walk(sub, path)
+		}
+	}
+
+	walk(t, nil)
+
+	return results
+}
+
+// flatten lists the accounts of the tree depth first.
+func (t AccountTree) flatten() []AccountTree {
+	results := []AccountTree{t}
+
+	for _, sub := range t.SubAccounts {
+		results = append(results, sub.flatten()...)
+	}
+
+	return results
+}
+
+// subtotals rolls up report counts by the account of their courses.
+func (t AccountTree) subtotals(counts map[int]int) []AccountSubtotal {
+	totals := make(map[int]int)
+
+	t.rollup(counts, func(node *AccountTree, count int) { totals[node.ID] = count })
+
+	results := make([]AccountSubtotal, 0)
+
+	for _, node := range t.flatten() {
+		results = append(results, AccountSubtotal{
+			AccountID: node.ID,
+			Name:      node.Name,
+			Depth:     node.Depth,
+			Courses:   node.Courses,
+			Count:     totals[node.ID],
+		})
+	}
+
+	return results
+}
+
+// accountSubtreeCache holds the ids of the sub-accounts of accounts, e.g. to
+// check the accounts of API keys on every request.
+type accountSubtreeCache struct {
+	mu      sync.Mutex
+	entries map[int]accountSubtree
+}
+
+type accountSubtree struct {
+	ids       map[int]bool
+	fetchedAt time.Time
+}
+
+func newAccountSubtreeCache() *accountSubtreeCache {
+	return &accountSubtreeCache{entries: make(map[int]accountSubtree)}
+}
+
+// accountSubtree returns the ids of an account and all its sub-accounts. Canvas
+// is requested outside the lock, concurrent misses may both fetch.
+func (c *APIController) accountSubtree(ctx context.Context, accountID int) (map[int]bool, int, error) {
+	sc := c.accountSubtrees
+
+	sc.mu.Lock()
+	entry, ok := sc.entries[accountID]
+	sc.mu.Unlock()
+
+	if ok && time.Since(entry.fetchedAt) < accountSubtreeTTL {
+		return entry.ids, http.StatusOK, nil
+	}
+
+	tree, code, err := c.accountTree(ctx, accountID, nil)
+	if err != nil {
+		return nil, code, err
+	}
+
+	entry = accountSubtree{ids: tree.ids(), fetchedAt: time.Now()}
+
+	sc.mu.Lock()
+	sc.entries[accountID] = entry
+	sc.mu.Unlock()
+
+	return entry.ids, http.StatusOK, nil
+}
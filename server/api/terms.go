@@ -0,0 +1,77 @@
+package api
+
+import (
+	"canvas-admin/canvas"
+	"encoding/json"
+	"net/http"
+	"time"
+)
+
+// termFilter limits an account report to the courses of an enrollment term, or
+// to the courses running between from and to, both inclusive.
+type termFilter struct {
+	EnrollmentTermID int    `query:"enrollment_term_id" validate:"gte=0"`
+	From             string `query:"from" validate:"omitempty,clientdate"`
+	To               string `query:"to" validate:"omitempty,clientdate"`
+}
+
+type accountReportRequest struct {
+	AccountID int `path:"account_id" validate:"required,gt=0"`
+	termFilter
+}
+
+func (f termFilter) coursesFilter() (canvas.CoursesFilter, error) {
+	filter := canvas.CoursesFilter{
+		EnrollmentTermID: f.EnrollmentTermID,
+	}
+
+	// clientdate validated the dates
+	if f.From != "" {
+		filter.EndsAfter, _ = time.Parse(clientDateLayout, f.From)
+	}
+
+	if f.To != "" {
+		to, _ := time.Parse(clientDateLayout, f.To)
+		filter.StartsBefore = to.AddDate(0, 0, 1)
+	}
+
+	if !filter.EndsAfter.IsZero() && !filter.StartsBefore.IsZero() && !filter.StartsBefore.After(filter.EndsAfter) {
+		return filter, &bindError{Fields: []fieldError{{Field: "to", Message: "must not be before from"}}}
+	}
+
+	return filter, nil
+}
+
+// GetEnrollmentTermsByAccountID returns the active terms of the root account
+// of an account.
+func (c *APIController) GetEnrollmentTermsByAccountID(w http.ResponseWriter, r *http.Request) (int, error) {
+	var req accountRequest
+	if err := bind(r, &req); err != nil {
+		return http.StatusBadRequest, err
+	}
+
+	account, code, err := c.canvasClient.GetAccountByID(r.Context(), req.AccountID)
+	if err != nil {
+		return code, err
+	}
+
+	rootAccountID := account.ID
+	if account.RootAccountID.Valid {
+		rootAccountID = int(account.RootAccountID.Int64)
+	}
+
+	results, code, err := c.canvasClient.GetEnrollmentTermsByAccountID(r.Context(), rootAccountID, canvas.ActiveEnrollmentTermWorkflowState)
+	if err != nil {
+		return code, err
+	}
+
+	if results == nil {
+		results = make([]canvas.EnrollmentTerm, 0)
+	}
+
+	if err := json.NewEncoder(w).Encode(results); err != nil {
+		return http.StatusInternalServerError, err
+	}
+
+	return http.StatusOK, nil
+}
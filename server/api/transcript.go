@@ -0,0 +1,171 @@
+package api
+
+import (
+	"canvas-admin/canvas"
+	"canvas-admin/supabase"
+	"canvas-admin/transcript"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const pdfContentType = "application/pdf"
+
+const sisUserIDPrefix = "sis_user_id:"
+
+type statementOfResultsRequest struct {
+	// a Canvas user id, or a SIS id prefixed with sis_user_id:
+	UserID string `path:"user_id" validate:"required"`
+}
+
+// GetStatementOfResults renders the completed enrollments of a student as a
+// PDF statement of results, with the final grades mapped through the grading
+// standard of each course.
+func (c *APIController) GetStatementOfResults(w http.ResponseWriter, r *http.Request) (int, error) {
+	ctx := r.Context()
+
+	var req statementOfResultsRequest
+	if err := bind(r, &req); err != nil {
+		return http.StatusBadRequest, err
+	}
+
+	var user canvas.User
+	var code int
+	var err error
+
+	if sisID, ok := strings.CutPrefix(req.UserID, sisUserIDPrefix); ok && sisID != "" {
+		user, code, err = c.canvasClient.GetUserBySisID(ctx, sisID)
+	} else if id, convErr := strconv.Atoi(req.UserID); convErr == nil && id > 0 {
+		user, code, err = c.canvasClient.GetUserByID(ctx, id)
+	} else {
+		return http.StatusBadRequest, &bindError{Fields: []fieldError{{Field: "user_id", Message: "must be a Canvas user id or " + sisUserIDPrefix + "<sis id>"}}}
+	}
+	if err != nil {
+		return code, err
+	}
+
+	states := []canvas.EnrollmentState{canvas.CompletedEnrollment}
+
+	enrollments, code, err := c.canvasClient.GetEnrollmentsByUserID(ctx, user.ID, states)
+	if err != nil {
+		return code, err
+	}
+
+	courses, code, err := c.canvasClient.GetCoursesByUserID(ctx, user.ID)
+	if err != nil {
+		return code, err
+	}
+
+	coursesMap := make(map[int]canvas.Course, len(courses))
+
+	for _, course := range courses {
+		coursesMap[course.ID] = course
+	}
+
+	gradingStandards := make(map[int]*canvas.GradingStandard)
+
+	statement := transcript.Statement{
+		StudentName: user.Name,
+		StudentID:   user.SISUserID,
+		IssuedAt:    time.Now(),
+		Results:     make([]transcript.Result, 0),
+	}
+
+	for _, enrollment := range enrollments {
+		if enrollment.Role != string(canvas.StudentEnrollment) {
+			continue
+		}
+
+		course, ok := coursesMap[enrollment.CourseID]
+		if !ok {
+			course, code, err = c.canvasClient.GetCourseByID(ctx, enrollment.CourseID)
+			if err != nil {
+				return code, err
+			}
+
+			coursesMap[enrollment.CourseID] = course
+		}
+
+		gradingStandard, code, err := c.courseGradingStandard(ctx, gradingStandards, course)
+		if err != nil {
+			return code, err
+		}
+
+		statement.Results = append(statement.Results, transcript.Result{
+			Qualification: course.Account.Name,
+			CourseName:    course.Name,
+			Grade:         finalGrade(enrollment.Grades, gradingStandard),
+			CompletedAt:   completedAt(enrollment, course),
+		})
+	}
+
+	sort.SliceStable(statement.Results, func(i, j int) bool {
+		a, b := statement.Results[i], statement.Results[j]
+
+		if a.Qualification != b.Qualification {
+			return a.Qualification < b.Qualification
+		}
+
+		if !a.CompletedAt.Equal(b.CompletedAt) {
+			return a.CompletedAt.Before(b.CompletedAt)
+		}
+
+		return a.CourseName < b.CourseName
+	})
+
+	filename := user.SISUserID
+	if filename == "" {
+		filename = strconv.Itoa(user.ID)
+	}
+
+	w.Header().Set("Content-Type", pdfContentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="statement-of-results-%s.pdf"`, filename))
+
+	if err := c.transcripts.Render(ctx, w, statement); err != nil {
+		return http.StatusInternalServerError, err
+	}
+
+	c.audit(ctx, supabase.AuditLog{
+		Action:  AuditGenerateStatementOfResults,
+		UserIDs: []int{user.ID},
+		Details: map[string]any{
+			"sis_user_id": user.SISUserID,
+			"results":     len(statement.Results),
+		},
+	})
+
+	return http.StatusOK, nil
+}
+
+// finalGrade maps the final score through the grading standard, and falls
+// back to the final grade, or score, of Canvas.
+func finalGrade(grades canvas.Grades, gradingStandard *canvas.GradingStandard) string {
+	switch {
+	case gradingStandard != nil && len(gradingStandard.GradingScheme) > 0 && grades.FinalScore.Valid:
+		return gradingSchemeGrade(gradingStandard.GradingScheme, grades.FinalScore.Float64)
+	case grades.FinalGrade.Valid && grades.FinalGrade.String != "":
+		return grades.FinalGrade.String
+	case grades.FinalScore.Valid:
+		return formatPoints(grades.FinalScore.Float64) + "%"
+	}
+
+	return "-"
+}
+
+// completedAt is when the enrollment was concluded, or the end of the course
+// when Canvas did not record it. Zero when both are unknown, the last update
+// of an enrollment is not its completion.
+func completedAt(enrollment canvas.Enrollment, course canvas.Course) time.Time {
+	if t, err := time.Parse(time.RFC3339, enrollment.CompletedAt.String); enrollment.CompletedAt.Valid && err == nil {
+		return t
+	}
+
+	if t, err := time.Parse(time.RFC3339, course.EndAt.String); course.EndAt.Valid && err == nil {
+		return t
+	}
+
+	return time.Time{}
+}
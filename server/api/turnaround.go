@@ -0,0 +1,370 @@
+package api
+
+import (
+	"canvas-admin/canvas"
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+	"time"
+)
+
+type TurnaroundStats struct {
+	Count       int     `json:"count"`
+	MedianHours float64 `json:"median_hours"`
+	P90Hours    float64 `json:"p90_hours"`
+	WithinSLA   float64 `json:"within_sla"` // share of submissions graded within the SLA, 0 to 1
+	BreachCount int     `json:"breach_count"`
+}
+
+type TurnaroundGroup struct {
+	ID         int    `json:"id"`
+	Name       string `json:"name"`
+	CourseName string `json:"course_name"` // empty for graders
+	TurnaroundStats
+}
+
+type TurnaroundBreach struct {
+	CourseID        int     `json:"course_id"`
+	CourseName      string  `json:"course_name"`
+	Section         string  `json:"section"`
+	AssignmentID    int     `json:"assignment_id"`
+	AssignmentTitle string  `json:"assignment_title"`
+	UserID          int     `json:"user_id"`
+	UserName        string  `json:"user_name"`
+	GraderID        int     `json:"grader_id"`
+	GraderName      string  `json:"grader_name"`
+	SubmittedAt     string  `json:"submitted_at"`
+	GradedAt        string  `json:"graded_at"`
+	TurnaroundHours float64 `json:"turnaround_hours"`
+	BusinessDays    int     `json:"business_days"` // after the day of submission, see sla.Calendar
+	SpeedGraderUrl  string  `json:"speedgrader_url"`
+}
+
+type TurnaroundReport struct {
+	AccountID   int                `json:"account_id"`
+	StartTime   string             `json:"start_time"`
+	EndTime     string             `json:"end_time"`
+	SLADays     int                `json:"sla_days"` // business days
+	Account     TurnaroundStats    `json:"account"`
+	Assignments []TurnaroundGroup  `json:"assignments"`
+	Sections    []TurnaroundGroup  `json:"sections"`
+	Graders     []TurnaroundGroup  `json:"graders"`
+	Accounts    []TurnaroundGroup  `json:"accounts,omitempty"` // with recursive, of the sub-accounts too
+	Breaches    []TurnaroundBreach `json:"breaches"`
+}
+
+type turnaroundRequest struct {
+	AccountID int    `path:"account_id" validate:"required,gt=0"`
+	StartTime string `query:"start_time" validate:"required,clientdate"`
+	EndTime   string `query:"end_time" validate:"required,clientdate"`
+	SLADays   int    `query:"sla_days" validate:"gte=1,lte=365"` // business days, SLA_BUSINESS_DAYS by default
+	Recursive bool   `query:"recursive"`
+	termFilter
+}
+
+// gradedSubmission is a submission graded in the report window.
+type gradedSubmission struct {
+	breach     TurnaroundBreach
+	sectionID  int
+	turnaround time.Duration
+}
+
+// breaches tells if the submission was graded later than the SLA, counted in
+// business days like the breach scan.
+func (s gradedSubmission) breaches(slaDays int) bool {
+	return s.breach.BusinessDays > slaDays
+}
+
+// GetGradingTurnaroundByAccountID measures the time from submission to grading
+// of the submissions graded between start_time and end_time, both inclusive.
+// Quizzes graded automatically are left out.
+func (c *APIController) GetGradingTurnaroundByAccountID(w http.ResponseWriter, r *http.Request) (int, error) {
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+
+	req := turnaroundRequest{SLADays: c.slaConfig.BusinessDays}
+	if err := bind(r, &req); err != nil {
+		return http.StatusBadRequest, err
+	}
+
+	filter, err := req.coursesFilter()
+	if err != nil {
+		return http.StatusBadRequest, err
+	}
+
+	// clientdate validated the dates
+	start, _ := time.Parse(clientDateLayout, req.StartTime)
+	end, _ := time.Parse(clientDateLayout, req.EndTime)
+	end = end.AddDate(0, 0, 1)
+
+	if !end.After(start) {
+		return http.StatusBadRequest, &bindError{Fields: []fieldError{{Field: "end_time", Message: "must not be before start_time"}}}
+	}
+
+	types := []canvas.CourseEnrollmentType{canvas.StudentCourseEnrollment}
+
+	courses, code, err := c.canvasClient.GetCoursesByAccountID(ctx, req.AccountID, "", types, filter)
+	if err != nil {
+		return code, err
+	}
+
+	graded := make([]gradedSubmission, 0)
+
+	for _, course := range courses {
+		select {
+		case <-ctx.Done():
+			return http.StatusRequestTimeout, ctx.Err()
+		default:
+			{
+				submissions, code, err := c.gradedSubmissions(ctx, course, start, end)
+				if err != nil {
+					return code, err
+				}
+
+				graded = append(graded, submissions...)
+			}
+		}
+	}
+
+	graderNames := make(map[int]string)
+
+	for i := range graded {
+		graderID := graded[i].breach.GraderID
+
+		if _, ok := graderNames[graderID]; !ok {
+			grader, code, err := c.canvasClient.GetUserByID(ctx, graderID)
+			if code == http.StatusNotFound {
+				grader.Name = fmt.Sprintf("Unknown user %d", graderID)
+			} else if err != nil {
+				return code, err
+			}
+
+			graderNames[graderID] = grader.Name
+		}
+
+		graded[i].breach.GraderName = graderNames[graderID]
+	}
+
+	report := TurnaroundReport{
+		AccountID: req.AccountID,
+		StartTime: start.Format(time.RFC3339),
+		EndTime:   end.Format(time.RFC3339),
+		SLADays:   req.SLADays,
+		Account:   turnaroundStats(graded, req.SLADays),
+		Breaches:  make([]TurnaroundBreach, 0),
+	}
+
+	report.Assignments = turnaroundGroups(graded, req.SLADays, func(s gradedSubmission) (int, string, string) {
+		return s.breach.AssignmentID, s.breach.AssignmentTitle, s.breach.CourseName
+	})
+
+	report.Sections = turnaroundGroups(graded, req.SLADays, func(s gradedSubmission) (int, string, string) {
+		return s.sectionID, s.breach.Section, s.breach.CourseName
+	})
+
+	report.Graders = turnaroundGroups(graded, req.SLADays, func(s gradedSubmission) (int, string, string) {
+		return s.breach.GraderID, s.breach.GraderName, ""
+	})
+
+	if req.Recursive {
+		tree, code, err := c.accountTree(ctx, req.AccountID, courses)
+		if err != nil {
+			return code, err
+		}
+
+		courseAccounts := make(map[int]int, len(courses))
+
+		for _, course := range courses {
+			courseAccounts[course.ID] = course.AccountID
+		}
+
+		ancestors := tree.ancestors()
+
+		byAccount := make(map[int][]gradedSubmission)
+
+		for _, s := range graded {
+			path, ok := ancestors[courseAccounts[s.breach.CourseID]]
+			if !ok {
+				path = []int{tree.ID}
+			}
+
+			for _, id := range path {
+				byAccount[id] = append(byAccount[id], s)
+			}
+		}
+
+		report.Accounts = make([]TurnaroundGroup, 0)
+
+		for _, node := range tree.flatten() {
+			report.Accounts = append(report.Accounts, TurnaroundGroup{
+				ID:              node.ID,
+				Name:            node.Name,
+				TurnaroundStats: turnaroundStats(byAccount[node.ID], req.SLADays),
+			})
+		}
+	}
+
+	for _, s := range graded {
+		if s.breaches(req.SLADays) {
+			report.Breaches = append(report.Breaches, s.breach)
+		}
+	}
+
+	sort.Slice(report.Breaches, func(i, j int) bool {
+		return report.Breaches[i].TurnaroundHours > report.Breaches[j].TurnaroundHours
+	})
+
+	if err := json.NewEncoder(w).Encode(report); err != nil {
+		return http.StatusInternalServerError, err
+	}
+
+	return http.StatusOK, nil
+}
+
+// gradedSubmissions returns the submissions of a course graded by a person
+// between start and end.
+func (c *APIController) gradedSubmissions(ctx context.Context, course canvas.Course, start, end time.Time) ([]gradedSubmission, int, error) {
+	submissions, code, err := c.canvasClient.GetGradedSubmissionsByCourseID(ctx, course.ID, start)
+	if err != nil {
+		return nil, code, err
+	}
+
+	if len(submissions) == 0 {
+		return nil, http.StatusOK, nil
+	}
+
+	students, sectionNames, code, err := c.courseStudents(ctx, course.ID)
+	if err != nil {
+		return nil, code, err
+	}
+
+	rules := c.classificationRules(ctx)
+
+	results := make([]gradedSubmission, 0, len(submissions))
+
+	for _, submission := range submissions {
+		// automatically graded quizzes have no grader or a negative one
+		if !submission.GraderID.Valid || submission.GraderID.Int64 <= 0 {
+			continue
+		}
+
+		if rules.excludes(submissionTarget(submission, course.AccountID)) {
+			continue
+		}
+
+		submittedAt, err := time.Parse(time.RFC3339, submission.SubmittedAt.String)
+		if err != nil {
+			continue
+		}
+
+		gradedAt, err := time.Parse(time.RFC3339, submission.GradedAt.String)
+		if err != nil || gradedAt.Before(start) || !gradedAt.Before(end) || gradedAt.Before(submittedAt) {
+			continue
+		}
+
+		enrollment := students[submission.UserID]
+
+		turnaround := gradedAt.Sub(submittedAt)
+
+		results = append(results, gradedSubmission{
+			sectionID:  enrollment.CourseSectionID,
+			turnaround: turnaround,
+			breach: TurnaroundBreach{
+				CourseID:        course.ID,
+				CourseName:      course.Name,
+				Section:         enrollmentSection(enrollment, sectionNames),
+				AssignmentID:    submission.AssignmentID,
+				AssignmentTitle: submission.Assignment.Name,
+				UserID:          submission.UserID,
+				UserName:        enrollment.User.Name,
+				GraderID:        int(submission.GraderID.Int64),
+				SubmittedAt:     submission.SubmittedAt.String,
+				GradedAt:        submission.GradedAt.String,
+				TurnaroundHours: roundHours(turnaround),
+				BusinessDays:    c.slaConfig.Calendar.BusinessDaysBetween(submittedAt, gradedAt),
+				SpeedGraderUrl: fmt.Sprintf("%s/courses/%d/gradebook/speed_grader?assignment_id=%d&student_id=%d",
+					c.canvasClient.HtmlUrl, course.ID, submission.AssignmentID, submission.UserID),
+			},
+		})
+	}
+
+	return results, http.StatusOK, nil
+}
+
+// turnaroundGroups returns the stats of the submissions grouped by key, the
+// slowest median first.
+func turnaroundGroups(graded []gradedSubmission, slaDays int, key func(gradedSubmission) (id int, name, courseName string)) []TurnaroundGroup {
+	groups := make(map[int][]gradedSubmission)
+	names := make(map[int][2]string)
+
+	for _, s := range graded {
+		id, name, courseName := key(s)
+
+		groups[id] = append(groups[id], s)
+		names[id] = [2]string{name, courseName}
+	}
+
+	results := make([]TurnaroundGroup, 0, len(groups))
+
+	for id, submissions := range groups {
+		results = append(results, TurnaroundGroup{
+			ID:              id,
+			Name:            names[id][0],
+			CourseName:      names[id][1],
+			TurnaroundStats: turnaroundStats(submissions, slaDays),
+		})
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].MedianHours != results[j].MedianHours {
+			return results[i].MedianHours > results[j].MedianHours
+		}
+
+		return results[i].ID < results[j].ID
+	})
+
+	return results
+}
+
+func turnaroundStats(graded []gradedSubmission, slaDays int) TurnaroundStats {
+	stats := TurnaroundStats{Count: len(graded)}
+
+	if len(graded) == 0 {
+		return stats
+	}
+
+	hours := make([]float64, 0, len(graded))
+
+	for _, s := range graded {
+		hours = append(hours, s.turnaround.Hours())
+
+		if s.breaches(slaDays) {
+			stats.BreachCount++
+		}
+	}
+
+	sort.Float64s(hours)
+
+	stats.MedianHours = math.Round(percentile(hours, 0.5)*10) / 10
+	stats.P90Hours = math.Round(percentile(hours, 0.9)*10) / 10
+	stats.WithinSLA = math.Round(float64(stats.Count-stats.BreachCount)/float64(stats.Count)*1000) / 1000
+
+	return stats
+}
+
+// percentile interpolates between the closest ranks of sorted values.
+func percentile(sorted []float64, p float64) float64 {
+	rank := p * float64(len(sorted)-1)
+
+	lower := int(math.Floor(rank))
+	upper := int(math.Ceil(rank))
+
+	return sorted[lower] + (sorted[upper]-sorted[lower])*(rank-float64(lower))
+}
+
+func roundHours(d time.Duration) float64 {
+	return math.Round(d.Hours()*10) / 10
+}
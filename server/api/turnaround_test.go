@@ -0,0 +1,112 @@
+package api
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestPercentile(t *testing.T) {
+	tests := []struct {
+		name   string
+		sorted []float64
+		p      float64
+		want   float64
+	}{
+		{name: "single value", sorted: []float64{7}, p: 0.9, want: 7},
+		{name: "median of an odd count", sorted: []float64{1, 2, 10}, p: 0.5, want: 2},
+		{name: "median of an even count", sorted: []float64{1, 2, 4, 10}, p: 0.5, want: 3},
+		{name: "interpolated", sorted: []float64{0, 10, 20, 30, 40, 50, 60, 70, 80, 90, 100}, p: 0.95, want: 95},
+		{name: "lowest", sorted: []float64{3, 5, 8}, p: 0, want: 3},
+		{name: "highest", sorted: []float64{3, 5, 8}, p: 1, want: 8},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := percentile(tt.sorted, tt.p); got != tt.want {
+				t.Errorf("percentile(%v, %v) = %v, want %v", tt.sorted, tt.p, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTurnaroundStats(t *testing.T) {
+	graded := func(hours float64, businessDays int) gradedSubmission {
+		return gradedSubmission{
+			breach:     TurnaroundBreach{BusinessDays: businessDays},
+			turnaround: time.Duration(hours * float64(time.Hour)),
+		}
+	}
+
+	tests := []struct {
+		name   string
+		graded []gradedSubmission
+		want   TurnaroundStats
+	}{
+		{
+			name: "empty",
+			want: TurnaroundStats{},
+		},
+		{
+			name:   "single value",
+			graded: []gradedSubmission{graded(30, 1)},
+			want:   TurnaroundStats{Count: 1, MedianHours: 30, P90Hours: 30, WithinSLA: 1},
+		},
+		{
+			name:   "at the sla",
+			graded: []gradedSubmission{graded(70, 3)},
+			want:   TurnaroundStats{Count: 1, MedianHours: 70, P90Hours: 70, WithinSLA: 1},
+		},
+		{
+			name:   "past the sla",
+			graded: []gradedSubmission{graded(100, 4)},
+			want:   TurnaroundStats{Count: 1, MedianHours: 100, P90Hours: 100, WithinSLA: 0, BreachCount: 1},
+		},
+		{
+			name:   "unsorted and rounded",
+			graded: []gradedSubmission{graded(100, 4), graded(2.04, 0), graded(50, 2)},
+			want:   TurnaroundStats{Count: 3, MedianHours: 50, P90Hours: 90, WithinSLA: 0.667, BreachCount: 1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := turnaroundStats(tt.graded, 3); got != tt.want {
+				t.Errorf("turnaroundStats() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTurnaroundGroups(t *testing.T) {
+	graded := func(sectionID int, hours float64) gradedSubmission {
+		return gradedSubmission{sectionID: sectionID, turnaround: time.Duration(hours * float64(time.Hour))}
+	}
+
+	key := func(s gradedSubmission) (int, string, string) {
+		return s.sectionID, "", ""
+	}
+
+	tests := []struct {
+		name   string
+		graded []gradedSubmission
+		want   []int
+	}{
+		{name: "empty", want: []int{}},
+		{name: "slowest median first", graded: []gradedSubmission{graded(1, 2), graded(2, 40), graded(1, 4), graded(3, 10)}, want: []int{2, 3, 1}},
+		{name: "ties by id", graded: []gradedSubmission{graded(3, 5), graded(1, 5), graded(2, 5)}, want: []int{1, 2, 3}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := make([]int, 0)
+			for _, g := range turnaroundGroups(tt.graded, 3, key) {
+				got = append(got, g.ID)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("turnaroundGroups() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
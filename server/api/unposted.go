@@ -0,0 +1,312 @@
+package api
+
+import (
+	"canvas-admin/canvas"
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+	"time"
+)
+
+type UnpostedSubmission struct {
+	CourseID        int     `json:"course_id"`
+	CourseName      string  `json:"course_name"`
+	SectionID       int     `json:"section_id"`
+	Section         string  `json:"section"`
+	AssignmentID    int     `json:"assignment_id"`
+	AssignmentTitle string  `json:"assignment_title"`
+	UserID          int     `json:"user_id"`
+	UserName        string  `json:"user_name"`
+	GraderID        int     `json:"grader_id"`
+	GradedAt        string  `json:"graded_at"`
+	AgeDays         float64 `json:"age_days"` // days since grading
+	SpeedGraderUrl  string  `json:"speedgrader_url"`
+}
+
+type UnpostedGroup struct {
+	ID         int     `json:"id"`
+	Name       string  `json:"name"`
+	CourseID   int     `json:"course_id"`
+	CourseName string  `json:"course_name"`
+	Count      int     `json:"count"`
+	MaxAgeDays float64 `json:"max_age_days"`
+}
+
+type UnpostedGradesReport struct {
+	Count       int                  `json:"count"`
+	Assignments []UnpostedGroup      `json:"assignments"`
+	Sections    []UnpostedGroup      `json:"sections"`
+	Accounts    []AccountSubtotal    `json:"accounts,omitempty"` // with recursive
+	Submissions []UnpostedSubmission `json:"submissions"`        // the oldest first
+}
+
+// GetUnpostedGradesByCourse lists the graded submissions of a course hidden
+// from the students by the post policy of their assignment.
+func (c *APIController) GetUnpostedGradesByCourse(w http.ResponseWriter, r *http.Request) (int, error) {
+	var req courseRequest
+	if err := bind(r, &req); err != nil {
+		return http.StatusBadRequest, err
+	}
+
+	course, code, err := c.canvasClient.GetCourseByID(r.Context(), req.CourseID)
+	if err != nil {
+		return code, err
+	}
+
+	submissions, code, err := c.unpostedSubmissions(r.Context(), course, time.Now())
+	if err != nil {
+		return code, err
+	}
+
+	if err := json.NewEncoder(w).Encode(unpostedGradesReport(submissions)); err != nil {
+		return http.StatusInternalServerError, err
+	}
+
+	return http.StatusOK, nil
+}
+
+// GetUnpostedGradesByAccount lists the graded but unposted submissions of the
+// available courses of an account.
+func (c *APIController) GetUnpostedGradesByAccount(w http.ResponseWriter, r *http.Request) (int, error) {
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+
+	var req recursiveAccountReportRequest
+	if err := bind(r, &req); err != nil {
+		return http.StatusBadRequest, err
+	}
+
+	filter, err := req.coursesFilter()
+	if err != nil {
+		return http.StatusBadRequest, err
+	}
+
+	types := []canvas.CourseEnrollmentType{canvas.StudentCourseEnrollment}
+
+	courses, code, err := c.canvasClient.GetCoursesByAccountID(ctx, req.AccountID, "", types, filter)
+	if err != nil {
+		return code, err
+	}
+
+	now := time.Now()
+
+	unposted := make([]UnpostedSubmission, 0)
+
+	for _, course := range courses {
+		select {
+		case <-ctx.Done():
+			return http.StatusRequestTimeout, ctx.Err()
+		default:
+			{
+				if course.WorkflowState != string(canvas.AvailableCourseWorkflowState) {
+					continue
+				}
+
+				submissions, code, err := c.unpostedSubmissions(ctx, course, now)
+				if err != nil {
+					return code, err
+				}
+
+				unposted = append(unposted, submissions...)
+			}
+		}
+	}
+
+	report := unpostedGradesReport(unposted)
+
+	if req.Recursive {
+		tree, code, err := c.accountTree(ctx, req.AccountID, courses)
+		if err != nil {
+			return code, err
+		}
+
+		courseAccounts := make(map[int]int, len(courses))
+
+		for _, course := range courses {
+			courseAccounts[course.ID] = course.AccountID
+		}
+
+		counts := make(map[int]int)
+
+		for _, s := range unposted {
+			counts[courseAccounts[s.CourseID]]++
+		}
+
+		report.Accounts = tree.subtotals(counts)
+	}
+
+	if err := json.NewEncoder(w).Encode(report); err != nil {
+		return http.StatusInternalServerError, err
+	}
+
+	return http.StatusOK, nil
+}
+
+// unpostedSubmissions returns the submissions of a course with a grade, or a
+// score, not posted to the student. Excused submissions have nothing to post.
+func (c *APIController) unpostedSubmissions(ctx context.Context, course canvas.Course, now time.Time) ([]UnpostedSubmission, int, error) {
+	submissions, code, err := c.canvasClient.GetSubmissionsByCourseID(ctx, course.ID, canvas.AllStudents, canvas.GradedSubmissionWorkflowState)
+	if err != nil {
+		return nil, code, err
+	}
+
+	rules := c.classificationRules(ctx)
+
+	results := make([]UnpostedSubmission, 0)
+
+	var students map[int]canvas.Enrollment
+	var sectionNames map[int]string
+
+	for _, submission := range submissions {
+		if submission.PostedAt.Valid || submission.Excused.Bool || (!submission.Score.Valid && !submission.Grade.Valid) {
+			continue
+		}
+
+		if rules.excludes(submissionTarget(submission, course.AccountID)) {
+			continue
+		}
+
+		// most courses have nothing unposted, only then fetch the students
+		if students == nil {
+			students, sectionNames, code, err = c.courseStudents(ctx, course.ID)
+			if err != nil {
+				return nil, code, err
+			}
+		}
+
+		enrollment, ok := students[submission.UserID]
+		if !ok {
+			// no longer a student of the course
+			continue
+		}
+
+		ageDays := 0.0
+
+		if gradedAt, err := time.Parse(time.RFC3339, submission.GradedAt.String); err == nil {
+			ageDays = math.Round(now.Sub(gradedAt).Hours()/24*10) / 10
+		}
+
+		results = append(results, UnpostedSubmission{
+			CourseID:        course.ID,
+			CourseName:      course.Name,
+			SectionID:       enrollment.CourseSectionID,
+			Section:         enrollmentSection(enrollment, sectionNames),
+			AssignmentID:    submission.AssignmentID,
+			AssignmentTitle: submission.Assignment.Name,
+			UserID:          submission.UserID,
+			UserName:        enrollment.User.Name,
+			GraderID:        int(submission.GraderID.Int64),
+			GradedAt:        submission.GradedAt.String,
+			AgeDays:         ageDays,
+			SpeedGraderUrl: fmt.Sprintf("%s/courses/%d/gradebook/speed_grader?assignment_id=%d&student_id=%d",
+				c.canvasClient.HtmlUrl, course.ID, submission.AssignmentID, submission.UserID),
+		})
+	}
+
+	return results, http.StatusOK, nil
+}
+
+func unpostedGradesReport(submissions []UnpostedSubmission) UnpostedGradesReport {
+	sort.SliceStable(submissions, func(i, j int) bool {
+		return submissions[i].AgeDays > submissions[j].AgeDays
+	})
+
+	report := UnpostedGradesReport{
+		Count:       len(submissions),
+		Submissions: submissions,
+	}
+
+	report.Assignments = unpostedGroups(submissions, func(s UnpostedSubmission) (int, string) {
+		return s.AssignmentID, s.AssignmentTitle
+	})
+
+	report.Sections = unpostedGroups(submissions, func(s UnpostedSubmission) (int, string) {
+		return s.SectionID, s.Section
+	})
+
+	return report
+}
+
+// unpostedGroups counts the submissions by key, the oldest group first.
+func unpostedGroups(submissions []UnpostedSubmission, key func(UnpostedSubmission) (id int, name string)) []UnpostedGroup {
+	groups := make(map[int]*UnpostedGroup)
+	results := make([]UnpostedGroup, 0)
+
+	for _, s := range submissions {
+		id, name := key(s)
+
+		group, ok := groups[id]
+		if !ok {
+			group = &UnpostedGroup{ID: id, Name: name, CourseID: s.CourseID, CourseName: s.CourseName}
+			groups[id] = group
+		}
+
+		group.Count++
+		group.MaxAgeDays = math.Max(group.MaxAgeDays, s.AgeDays)
+	}
+
+	for _, group := range groups {
+		results = append(results, *group)
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].MaxAgeDays != results[j].MaxAgeDays {
+			return results[i].MaxAgeDays > results[j].MaxAgeDays
+		}
+
+		return results[i].ID < results[j].ID
+	})
+
+	return results
+}
+
+// courseStudents returns the first enrollment of each student of a course,
+// including inactive and completed ones, and the names of its sections.
+func (c *APIController) courseStudents(ctx context.Context, courseID int) (map[int]canvas.Enrollment, map[int]string, int, error) {
+	states := []canvas.EnrollmentState{canvas.ActiveEnrollment, canvas.CompletedEnrollment, canvas.InactiveEnrollment}
+
+	types := []canvas.EnrollmentType{canvas.StudentEnrollment}
+
+	enrollments, code, err := c.canvasClient.GetEnrollmentsByCourseID(ctx, courseID, states, types)
+	if err != nil {
+		return nil, nil, code, err
+	}
+
+	sections, code, err := c.canvasClient.GetSectionsByCourseID(ctx, courseID)
+	if err != nil {
+		return nil, nil, code, err
+	}
+
+	sectionNames := make(map[int]string, len(sections))
+
+	for _, section := range sections {
+		sectionNames[section.ID] = section.Name
+	}
+
+	// students in many sections are reported in the first one, active
+	// enrollments first
+	students := make(map[int]canvas.Enrollment, len(enrollments))
+
+	for _, enrollment := range enrollments {
+		current, ok := students[enrollment.UserID]
+
+		if !ok || (current.EnrollmentState != string(canvas.ActiveEnrollment) && enrollment.EnrollmentState == string(canvas.ActiveEnrollment)) {
+			students[enrollment.UserID] = enrollment
+		}
+	}
+
+	return students, sectionNames, http.StatusOK, nil
+}
+
+// enrollmentSection is the SIS id of the section of an enrollment, or its name
+// for sections without one.
+func enrollmentSection(enrollment canvas.Enrollment, sectionNames map[int]string) string {
+	if enrollment.SISSectionID != "" {
+		return enrollment.SISSectionID
+	}
+
+	return sectionNames[enrollment.CourseSectionID]
+}
@@ -1,20 +1,34 @@
 package api
 
 import (
-	"fmt"
+	"canvas-admin/supabase"
 	"net/http"
-	"strconv"
-
-	"github.com/go-chi/chi/v5"
 )
 
 func (c *APIController) TerminateUserSessions(w http.ResponseWriter, r *http.Request) (int, error) {
-	userID, err := strconv.Atoi(chi.URLParam(r, "user_id"))
+	var req userRequest
+	if err := bind(r, &req); err != nil {
+		return http.StatusBadRequest, err
+	}
+
+	ctx, code, err := c.actAsStaff(r.Context())
+	if err != nil {
+		return code, err
+	}
+
+	entry := supabase.AuditLog{
+		Action:  AuditTerminateSessions,
+		UserIDs: []int{req.UserID},
+		Details: map[string]any{"source": "user", "status": SessionsTerminated},
+	}
+
+	code, err = c.canvasClient.TerminateUserSessions(ctx, req.UserID)
 	if err != nil {
-		return http.StatusBadRequest, fmt.Errorf("invalid user id")
+		entry.Details["status"], entry.Details["error"] = SessionsFailed, err.Error()
 	}
 
-	code, err := c.canvasClient.TerminateUserSessions(r.Context(), userID)
+	c.audit(ctx, entry)
+
 	if err != nil {
 		return code, err
 	}
@@ -23,7 +37,23 @@ func (c *APIController) TerminateUserSessions(w http.ResponseWriter, r *http.Req
 }
 
 func (c *APIController) TerminateMobileSessions(w http.ResponseWriter, r *http.Request) (int, error) {
-	code, err := c.canvasClient.TerminateMobileSessions(r.Context())
+	ctx, code, err := c.actAsStaff(r.Context())
+	if err != nil {
+		return code, err
+	}
+
+	entry := supabase.AuditLog{
+		Action:  AuditTerminateMobileSessions,
+		Details: map[string]any{"status": SessionsTerminated},
+	}
+
+	code, err = c.canvasClient.TerminateMobileSessions(ctx)
+	if err != nil {
+		entry.Details["status"], entry.Details["error"] = SessionsFailed, err.Error()
+	}
+
+	c.audit(ctx, entry)
+
 	if err != nil {
 		return code, err
 	}
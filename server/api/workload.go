@@ -0,0 +1,244 @@
+package api
+
+import (
+	"canvas-admin/canvas"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/guregu/null/v5"
+)
+
+type TeacherWorkload struct {
+	TeacherID         int                      `json:"teacher_id"` // 0 for sections without a teacher
+	TeacherSisID      string                   `json:"teacher_sis_id"`
+	Name              string                   `json:"name"`
+	NeedsGradingCount int                      `json:"needs_grading_count"`
+	SectionCount      int                      `json:"section_count"`
+	CourseCount       int                      `json:"course_count"`
+	OldestSubmittedAt null.String              `json:"oldest_submitted_at"`
+	OldestWaitingDays null.Int                 `json:"oldest_waiting_days"`
+	Sections          []TeacherWorkloadSection `json:"sections"`
+}
+
+type TeacherWorkloadSection struct {
+	CourseID          int         `json:"course_id"`
+	CourseName        string      `json:"course_name"`
+	Section           string      `json:"section"`
+	NeedsGradingCount int         `json:"needs_grading_count"`
+	OldestSubmittedAt null.String `json:"oldest_submitted_at"`
+	GradebookURL      string      `json:"gradebook_url"`
+}
+
+// GetTeacherWorkloadByAccountID inverts the ungraded assignments report: the
+// submissions awaiting grading of each section are attributed to every
+// teacher of the section.
+func (c *APIController) GetTeacherWorkloadByAccountID(w http.ResponseWriter, r *http.Request) (int, error) {
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+
+	var req accountReportRequest
+	if err := bind(r, &req); err != nil {
+		return http.StatusBadRequest, err
+	}
+
+	filter, err := req.coursesFilter()
+	if err != nil {
+		return http.StatusBadRequest, err
+	}
+
+	types := []canvas.CourseEnrollmentType{canvas.StudentCourseEnrollment}
+
+	courses, code, err := c.canvasClient.GetCoursesByAccountID(ctx, req.AccountID, "", types, filter)
+	if err != nil {
+		return code, err
+	}
+
+	workloads := make(map[int]*TeacherWorkload)
+
+	for _, course := range courses {
+		select {
+		case <-ctx.Done():
+			return http.StatusRequestTimeout, ctx.Err()
+		default:
+			{
+				if course.WorkflowState != string(canvas.AvailableCourseWorkflowState) {
+					continue
+				}
+
+				sections, code, err := c.sectionWorkloads(ctx, course)
+				if err != nil {
+					return code, err
+				}
+
+				for _, sw := range sections {
+					teachers := sw.teachers
+
+					if len(teachers) == 0 {
+						teachers = []canvas.User{{Name: "No teacher"}}
+					}
+
+					for _, teacher := range teachers {
+						workload, ok := workloads[teacher.ID]
+						if !ok {
+							workload = &TeacherWorkload{
+								TeacherID:    teacher.ID,
+								TeacherSisID: teacher.SISUserID,
+								Name:         teacher.Name,
+								Sections:     make([]TeacherWorkloadSection, 0),
+							}
+
+							workloads[teacher.ID] = workload
+						}
+
+						if len(workload.Sections) == 0 || workload.Sections[len(workload.Sections)-1].CourseID != course.ID {
+							workload.CourseCount++
+						}
+
+						workload.NeedsGradingCount += sw.NeedsGradingCount
+						workload.SectionCount++
+						workload.Sections = append(workload.Sections, sw.TeacherWorkloadSection)
+
+						if sw.OldestSubmittedAt.Valid && (!workload.OldestSubmittedAt.Valid || sw.OldestSubmittedAt.String < workload.OldestSubmittedAt.String) {
+							workload.OldestSubmittedAt = sw.OldestSubmittedAt
+						}
+					}
+				}
+			}
+		}
+	}
+
+	now := time.Now()
+
+	results := make([]TeacherWorkload, 0, len(workloads))
+
+	for _, workload := range workloads {
+		if oldest, err := time.Parse(time.RFC3339, workload.OldestSubmittedAt.String); err == nil {
+			workload.OldestWaitingDays = null.IntFrom(int64(now.Sub(oldest).Hours() / 24))
+		}
+
+		results = append(results, *workload)
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].NeedsGradingCount != results[j].NeedsGradingCount {
+			return results[i].NeedsGradingCount > results[j].NeedsGradingCount
+		}
+
+		return results[i].Name < results[j].Name
+	})
+
+	if err := json.NewEncoder(w).Encode(results); err != nil {
+		return http.StatusInternalServerError, err
+	}
+
+	return http.StatusOK, nil
+}
+
+type sectionWorkload struct {
+	TeacherWorkloadSection
+	teachers []canvas.User
+}
+
+// sectionWorkloads returns the sections of a course with submissions
+// awaiting grading, in section id order.
+func (c *APIController) sectionWorkloads(ctx context.Context, course canvas.Course) ([]sectionWorkload, int, error) {
+	needsGrading, sectionIDs, code, err := c.needsGradingBySection(ctx, course)
+	if err != nil {
+		return nil, code, err
+	}
+
+	rules := c.classificationRules(ctx)
+
+	// holds sections with teachers names
+	sectionsWithTeachersMap := make(map[int]sectionWithTeachers)
+
+	results := make([]sectionWorkload, 0, len(sectionIDs))
+
+	for _, sectionID := range sectionIDs {
+		st, code, err := c.getSectionWithTeachers(ctx, sectionsWithTeachersMap, sectionID)
+		if err != nil {
+			return nil, code, err
+		}
+
+		submissions, code, err := c.canvasClient.GetSubmissionsBySectionID(ctx, sectionID, canvas.SubmittedSubmissionWorkflowState)
+		if err != nil {
+			return nil, code, err
+		}
+
+		var oldest time.Time
+
+		for _, submission := range submissions {
+			// excluded work is not counted, it does not wait either
+			if rules.excludes(submissionTarget(submission, course.AccountID)) {
+				continue
+			}
+
+			submittedAt, err := time.Parse(time.RFC3339, submission.SubmittedAt.String)
+			if err != nil {
+				continue
+			}
+
+			if oldest.IsZero() || submittedAt.Before(oldest) {
+				oldest = submittedAt
+			}
+		}
+
+		sw := sectionWorkload{
+			TeacherWorkloadSection: TeacherWorkloadSection{
+				CourseID:          course.ID,
+				CourseName:        course.Name,
+				Section:           st.sisSectionID,
+				NeedsGradingCount: needsGrading[sectionID],
+				GradebookURL:      fmt.Sprintf(`%s/courses/%d/gradebook`, c.canvasClient.HtmlUrl, course.ID),
+			},
+			teachers: st.teachers,
+		}
+
+		if !oldest.IsZero() {
+			sw.OldestSubmittedAt = null.StringFrom(oldest.UTC().Format(time.RFC3339))
+		}
+
+		results = append(results, sw)
+	}
+
+	return results, http.StatusOK, nil
+}
+
+// needsGradingBySection returns the count of submissions awaiting grading by
+// section, and the ids of the sections with any in ascending order.
+func (c *APIController) needsGradingBySection(ctx context.Context, course canvas.Course) (map[int]int, []int, int, error) {
+	assignments, code, err := c.canvasClient.GetAssignmentsByCourseID(ctx, course.ID, "", canvas.UngradedBucket, true)
+	if err != nil {
+		return nil, nil, code, err
+	}
+
+	rules := c.classificationRules(ctx)
+
+	needsGrading := make(map[int]int)
+
+	for _, assignment := range assignments {
+		if rules.excludes(assignmentTarget(assignment, course.AccountID)) {
+			continue
+		}
+
+		for _, section := range assignment.NeedsGradingCountBySection {
+			needsGrading[section.SectionID] += section.NeedsGradingCount
+		}
+	}
+
+	sectionIDs := make([]int, 0, len(needsGrading))
+
+	for sectionID, count := range needsGrading {
+		if count > 0 {
+			sectionIDs = append(sectionIDs, sectionID)
+		}
+	}
+
+	sort.Ints(sectionIDs)
+
+	return needsGrading, sectionIDs, http.StatusOK, nil
+}
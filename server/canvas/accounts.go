@@ -5,6 +5,8 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"net/url"
+	"strconv"
 
 	"github.com/guregu/null/v5"
 )
@@ -36,3 +38,46 @@ func (c *CanvasClient) GetAccountByID(ctx context.Context, accountID int) (accou
 
 	return account, http.StatusOK, nil
 }
+
+// GetSubAccountsByAccountID returns the sub-accounts of an account, and with
+// recursive of their sub-accounts too, in no particular order.
+func (c *CanvasClient) GetSubAccountsByAccountID(ctx context.Context, accountID int, recursive bool) (results []Account, code int, err error) {
+	params := url.Values{}
+
+	params.Add("per_page", strconv.Itoa(c.pageSize))
+
+	if recursive {
+		params.Add("recursive", "true")
+	}
+
+	requestUrl := fmt.Sprintf("%s/accounts/%d/sub_accounts?%s", c.baseUrl, accountID, params.Encode())
+
+	for {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, requestUrl, nil)
+		if err != nil {
+			return nil, http.StatusInternalServerError, err
+		}
+
+		data, link, code, err := c.httpClient.do(req)
+		if err != nil {
+			return nil, code, err
+		}
+
+		accounts := []Account{}
+		if err := json.Unmarshal(data, &accounts); err != nil {
+			return nil, http.StatusInternalServerError, err
+		}
+
+		results = append(results, accounts...)
+
+		nextUrl := getNextUrl(link)
+
+		if nextUrl == "" {
+			break
+		}
+
+		requestUrl = nextUrl
+	}
+
+	return results, http.StatusOK, nil
+}
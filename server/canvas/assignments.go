@@ -31,6 +31,7 @@ type Assignment struct {
 	DueAt                      null.String           `json:"due_at"`
 	UnlockAt                   null.String           `json:"unlock_at"`
 	LockAt                     null.String           `json:"lock_at"`
+	PointsPossible             null.Float            `json:"points_possible"`
 	NeedsGradingCount          int                   `json:"needs_grading_count"`
 	Published                  bool                  `json:"published"`
 	HtmlUrl                    string                `json:"html_url"`
@@ -38,9 +39,14 @@ type Assignment struct {
 	AllDates                   []AssignmentDate      `json:"all_dates"`
 	GradingStandardID          null.Int              `json:"grading_standard_id"`
 	GradingType                string                `json:"grading_type"`
+	AssignmentGroupID          int                   `json:"assignment_group_id"`
 	OmitFromFinalGrade         bool                  `json:"omit_from_final_grade"`
 	WorkflowState              string                `json:"workflow_state"`
+	HasOverrides               bool                  `json:"has_overrides"`
 	Overrides                  []struct {
+		ID              int         `json:"id"`
+		Title           string      `json:"title"`
+		GroupID         null.Int    `json:"group_id"`
 		CourseSectionID null.Int    `json:"course_section_id"`
 		DueAt           null.String `json:"due_at"`
 		LockAt          null.String `json:"lock_at"`
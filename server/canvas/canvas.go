@@ -1,10 +1,13 @@
 package canvas
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
 	"regexp"
+	"strconv"
 	"strings"
 	"time"
 )
@@ -14,6 +17,20 @@ type CanvasClient struct {
 	pageSize   int
 	httpClient *httpClient
 	HtmlUrl    string
+
+	// Masquerade enables acting as staff members for attributable actions,
+	// see WithActingUser. The access token owner needs the "Become other
+	// users" permission.
+	Masquerade bool
+}
+
+type actingUserContextKey struct{}
+
+// WithActingUser makes the requests made with ctx act as the Canvas user
+// (as_user_id), so Canvas attributes them to that user and checks that
+// user's permissions instead of the access token owner's.
+func WithActingUser(ctx context.Context, userID int) context.Context {
+	return context.WithValue(ctx, actingUserContextKey{}, userID)
 }
 
 type httpClient struct {
@@ -36,13 +53,20 @@ func (c *httpClient) do(req *http.Request) (data []byte, link string, code int,
 	bearer := "Bearer " + c.accessToken
 	req.Header.Add("Authorization", bearer)
 
+	if userID, ok := req.Context().Value(actingUserContextKey{}).(int); ok {
+		query := req.URL.Query()
+		query.Set("as_user_id", strconv.Itoa(userID))
+		req.URL.RawQuery = query.Encode()
+	}
+
 	res, err := c.client.Do(req)
 	if err != nil {
 		return nil, "", http.StatusInternalServerError, err
 	}
 	defer res.Body.Close()
 
-	if res.StatusCode != http.StatusOK {
+	// writes answer 201 Created
+	if res.StatusCode != http.StatusOK && res.StatusCode != http.StatusCreated {
 		return nil, "", res.StatusCode, fmt.Errorf("unsuccessful request: %s", req.URL.RequestURI())
 	}
 
@@ -64,14 +88,31 @@ func NewCanvasClient(baseUrl string, accessToken string, pageSize int, htmlUrl s
 }
 
 func getNextUrl(linkTxt string) string {
+	return getLinkUrl(linkTxt, "next")
+}
+
+// getLastPage returns the page number of the last link, 0 when Canvas did
+// not count the pages.
+func getLastPage(linkTxt string) int {
+	lastUrl, err := url.Parse(getLinkUrl(linkTxt, "last"))
+	if err != nil {
+		return 0
+	}
+
+	page, _ := strconv.Atoi(lastUrl.Query().Get("page"))
+
+	return page
+}
+
+func getLinkUrl(linkTxt string, rel string) string {
 	url := ""
 
 	if linkTxt != "" {
 		links := strings.Split(linkTxt, ",")
-		nextRegEx := regexp.MustCompile(`^<(.*)>; rel="next"$`)
+		linkRegEx := regexp.MustCompile(`^<(.*)>; rel="` + regexp.QuoteMeta(rel) + `"$`)
 
 		for i := 0; i < len(links); i++ {
-			matches := nextRegEx.Match([]byte(links[i]))
+			matches := linkRegEx.Match([]byte(links[i]))
 
 			if matches {
 				startIndex := strings.Index(links[i], "<")
@@ -7,6 +7,7 @@ import (
 	"net/http"
 	"net/url"
 	"strconv"
+	"time"
 
 	"github.com/guregu/null/v5"
 )
@@ -24,27 +25,30 @@ const (
 type CourseWorkflowState string
 
 const (
+	CreatedCourseWorkflowState   CourseWorkflowState = "created"
 	ClaimedCourseWorkflowState   CourseWorkflowState = "claimed"
 	AvailableCourseWorkflowState CourseWorkflowState = "available"
+	CompletedCourseWorkflowState CourseWorkflowState = "completed"
 	DeletedCourseWorkflowState   CourseWorkflowState = "deleted"
 )
 
 type Course struct {
-	ID                int         `json:"id"`
-	CourseCode        string      `json:"course_code"`
-	Name              string      `json:"name"`
-	SISCourseID       null.String `json:"sis_course_id"`
-	GradingStandardID null.Int    `json:"grading_standard_id"`
-	AccountID         int         `json:"account_id"`
-	RootAccountID     int         `json:"root_account_id"`
-	FriendlyName      null.String `json:"friendly_name"`
-	WorkflowState     string      `json:"workflow_state"`
-	StartAt           null.String `json:"start_at"`
-	EndAt             null.String `json:"end_at"`
-	IsPublic          bool        `json:"is_public"`
-	EnrollmentTermID  int         `json:"enrollment_term_id"`
-	Account           Account     `json:"account"`
-	Sections          []Section   `json:"sections"`
+	ID                int           `json:"id"`
+	CourseCode        string        `json:"course_code"`
+	Name              string        `json:"name"`
+	SISCourseID       null.String   `json:"sis_course_id"`
+	GradingStandardID null.Int      `json:"grading_standard_id"`
+	AccountID         int           `json:"account_id"`
+	RootAccountID     int           `json:"root_account_id"`
+	FriendlyName      null.String   `json:"friendly_name"`
+	WorkflowState     string        `json:"workflow_state"`
+	StartAt           null.String   `json:"start_at"`
+	EndAt             null.String   `json:"end_at"`
+	IsPublic          bool          `json:"is_public"`
+	EnrollmentTermID  int           `json:"enrollment_term_id"`
+	Account           Account       `json:"account"`
+	Sections          []Section     `json:"sections"`
+	Teachers          []UserDisplay `json:"teachers,omitempty"` // only with CoursesFilter.IncludeTeachers
 }
 
 func (c *CanvasClient) GetCourseByID(ctx context.Context, courseID int) (course Course, code int, err error) {
@@ -71,15 +75,42 @@ func (c *CanvasClient) GetCourseByID(ctx context.Context, courseID int) (course
 	return course, http.StatusOK, nil
 }
 
-// If "types" is set, only return courses that have at least one user enrolled in in the course with one of the specified enrollment types.
-func (c *CanvasClient) GetCoursesByAccountID(ctx context.Context, accountID int, searchTerm string, types []CourseEnrollmentType) (results []Course, code int, err error) {
-	if len(searchTerm) == 1 {
-		return nil, http.StatusBadRequest, fmt.Errorf("course search term is less than 2 characters")
-	}
+// CoursesFilter limits the courses of an account to a term, or to the courses
+// running in a date range. Canvas falls back to the dates of the term for
+// courses without their own. The zero value returns every course.
+type CoursesFilter struct {
+	EnrollmentTermID int
+	StartsBefore     time.Time
+	EndsAfter        time.Time
+	States           []CourseWorkflowState
+	Published        null.Bool
+	// match the search term against the names of the teachers instead
+	SearchByTeacher bool
+	IncludeTeachers bool
+}
+
+// CoursesSort is an order Canvas lists the courses of an account in.
+type CoursesSort string
+
+const (
+	CourseNameSort  CoursesSort = "course_name"
+	SISCourseIDSort CoursesSort = "sis_course_id"
+	TeacherSort     CoursesSort = "teacher"
+	AccountNameSort CoursesSort = "account_name"
+)
 
+// CoursesPage is a page of the courses of an account.
+type CoursesPage struct {
+	Courses []Course
+	// courses over all pages, null when Canvas did not count the pages
+	Total null.Int
+}
+
+// coursesParams are the parameters of the courses of an account, without
+// paging.
+func coursesParams(searchTerm string, types []CourseEnrollmentType, filter CoursesFilter) url.Values {
 	params := url.Values{}
 
-	params.Add("per_page", strconv.Itoa(c.pageSize))
 	params.Add("include[]", "account")
 
 	if len(searchTerm) >= 2 {
@@ -90,6 +121,47 @@ func (c *CanvasClient) GetCoursesByAccountID(ctx context.Context, accountID int,
 		params.Add("enrollment_type[]", string(t))
 	}
 
+	if filter.EnrollmentTermID != 0 {
+		params.Add("enrollment_term_id", strconv.Itoa(filter.EnrollmentTermID))
+	}
+
+	if !filter.StartsBefore.IsZero() {
+		params.Add("starts_before", filter.StartsBefore.UTC().Format(time.RFC3339))
+	}
+
+	if !filter.EndsAfter.IsZero() {
+		params.Add("ends_after", filter.EndsAfter.UTC().Format(time.RFC3339))
+	}
+
+	for _, state := range filter.States {
+		params.Add("state[]", string(state))
+	}
+
+	if filter.Published.Valid {
+		params.Add("published", strconv.FormatBool(filter.Published.Bool))
+	}
+
+	if filter.SearchByTeacher && len(searchTerm) >= 2 {
+		params.Add("search_by", "teacher")
+	}
+
+	if filter.IncludeTeachers {
+		params.Add("include[]", "teachers")
+	}
+
+	return params
+}
+
+// If "types" is set, only return courses that have at least one user enrolled in in the course with one of the specified enrollment types.
+func (c *CanvasClient) GetCoursesByAccountID(ctx context.Context, accountID int, searchTerm string, types []CourseEnrollmentType, filter CoursesFilter) (results []Course, code int, err error) {
+	if len(searchTerm) == 1 {
+		return nil, http.StatusBadRequest, fmt.Errorf("course search term is less than 2 characters")
+	}
+
+	params := coursesParams(searchTerm, types, filter)
+
+	params.Add("per_page", strconv.Itoa(c.pageSize))
+
 	requestUrl := fmt.Sprintf("%s/accounts/%d/courses?%s", c.baseUrl, accountID, params.Encode())
 
 	for {
@@ -122,6 +194,72 @@ func (c *CanvasClient) GetCoursesByAccountID(ctx context.Context, accountID int,
 	return results, http.StatusOK, nil
 }
 
+// GetCoursesPageByAccountID returns a page, from 1, of the courses of an
+// account sorted by Canvas. The total is counted from the last link of the
+// Link header, which takes a request for the last page.
+func (c *CanvasClient) GetCoursesPageByAccountID(ctx context.Context, accountID int, searchTerm string, filter CoursesFilter, sort CoursesSort, desc bool, page, perPage int) (result CoursesPage, code int, err error) {
+	if len(searchTerm) == 1 {
+		return result, http.StatusBadRequest, fmt.Errorf("course search term is less than 2 characters")
+	}
+
+	params := coursesParams(searchTerm, nil, filter)
+
+	params.Add("sort", string(sort))
+	params.Add("order", "asc")
+	params.Add("page", strconv.Itoa(page))
+	params.Add("per_page", strconv.Itoa(perPage))
+
+	if desc {
+		params.Set("order", "desc")
+	}
+
+	requestUrl := fmt.Sprintf("%s/accounts/%d/courses?%s", c.baseUrl, accountID, params.Encode())
+
+	courses, link, code, err := c.getCourses(ctx, requestUrl)
+	if err != nil {
+		return result, code, err
+	}
+
+	result.Courses = courses
+
+	lastPage := getLastPage(link)
+
+	switch {
+	case lastPage == page:
+		result.Total = null.IntFrom(int64((page-1)*perPage + len(courses)))
+	case lastPage != 0:
+		last, _, code, err := c.getCourses(ctx, getLinkUrl(link, "last"))
+		if err != nil {
+			return result, code, err
+		}
+
+		result.Total = null.IntFrom(int64((lastPage-1)*perPage + len(last)))
+	case page == 1 && getNextUrl(link) == "":
+		result.Total = null.IntFrom(int64(len(courses)))
+	}
+
+	return result, http.StatusOK, nil
+}
+
+func (c *CanvasClient) getCourses(ctx context.Context, requestUrl string) (results []Course, link string, code int, err error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, requestUrl, nil)
+	if err != nil {
+		return nil, "", http.StatusInternalServerError, err
+	}
+
+	data, link, code, err := c.httpClient.do(req)
+	if err != nil {
+		return nil, "", code, err
+	}
+
+	results = []Course{}
+	if err := json.Unmarshal(data, &results); err != nil {
+		return nil, "", http.StatusInternalServerError, err
+	}
+
+	return results, link, http.StatusOK, nil
+}
+
 func (c *CanvasClient) GetCoursesByUserID(ctx context.Context, userID int) (results []Course, code int, err error) {
 	params := url.Values{}
 
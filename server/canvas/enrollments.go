@@ -24,6 +24,8 @@ type Enrollment struct {
 	Type            string `json:"type"`
 	CreatedAt       string `json:"created_at"`
 	UpdatedAt       string `json:"updated_at"`
+	// when the enrollment was concluded, null for other states
+	CompletedAt null.String `json:"completed_at"`
 }
 
 type Grades struct {
@@ -185,3 +187,59 @@ func (c *CanvasClient) GetEnrollmentsBySectionID(ctx context.Context, sectionID
 
 	return results, http.StatusOK, nil
 }
+
+// EnrollmentTask ends an enrollment: conclude keeps it readable to the
+// student, deactivate hides the course until reactivated, delete removes it.
+type EnrollmentTask string
+
+const (
+	ConcludeEnrollmentTask   EnrollmentTask = "conclude"
+	DeactivateEnrollmentTask EnrollmentTask = "deactivate"
+	DeleteEnrollmentTask     EnrollmentTask = "delete"
+)
+
+func (c *CanvasClient) EndEnrollment(ctx context.Context, courseID, enrollmentID int, task EnrollmentTask) (enrollment Enrollment, code int, err error) {
+	params := url.Values{}
+
+	params.Add("task", string(task))
+
+	requestUrl := fmt.Sprintf("%s/courses/%d/enrollments/%d?%s", c.baseUrl, courseID, enrollmentID, params.Encode())
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, requestUrl, nil)
+	if err != nil {
+		return enrollment, http.StatusInternalServerError, err
+	}
+
+	data, _, code, err := c.httpClient.do(req)
+	if err != nil {
+		return enrollment, code, err
+	}
+
+	if err := json.Unmarshal(data, &enrollment); err != nil {
+		return enrollment, http.StatusInternalServerError, err
+	}
+
+	return enrollment, http.StatusOK, nil
+}
+
+// ReactivateEnrollment activates a deactivated enrollment, Canvas cannot
+// reactivate concluded or deleted ones.
+func (c *CanvasClient) ReactivateEnrollment(ctx context.Context, courseID, enrollmentID int) (enrollment Enrollment, code int, err error) {
+	requestUrl := fmt.Sprintf("%s/courses/%d/enrollments/%d/reactivate", c.baseUrl, courseID, enrollmentID)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, requestUrl, nil)
+	if err != nil {
+		return enrollment, http.StatusInternalServerError, err
+	}
+
+	data, _, code, err := c.httpClient.do(req)
+	if err != nil {
+		return enrollment, code, err
+	}
+
+	if err := json.Unmarshal(data, &enrollment); err != nil {
+		return enrollment, http.StatusInternalServerError, err
+	}
+
+	return enrollment, http.StatusOK, nil
+}
@@ -65,3 +65,25 @@ func (c *CanvasClient) GetGradingStandardsByContext(ctx context.Context, context
 
 	return results, http.StatusOK, nil
 }
+
+// GetGradingStandardByID returns a grading standard visible in a context, e.g.
+// a course's own standard or one of its accounts.
+func (c *CanvasClient) GetGradingStandardByID(ctx context.Context, context GradingStandardContext, contextID, gradingStandardID int) (result GradingStandard, code int, err error) {
+	requestUrl := fmt.Sprintf("%s/%s/%d/grading_standards/%d", c.baseUrl, context, contextID, gradingStandardID)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, requestUrl, nil)
+	if err != nil {
+		return result, http.StatusInternalServerError, err
+	}
+
+	data, _, code, err := c.httpClient.do(req)
+	if err != nil {
+		return result, code, err
+	}
+
+	if err := json.Unmarshal(data, &result); err != nil {
+		return result, http.StatusInternalServerError, err
+	}
+
+	return result, http.StatusOK, nil
+}
@@ -0,0 +1,67 @@
+package canvas
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+type graphqlRequest struct {
+	Query     string         `json:"query"`
+	Variables map[string]any `json:"variables"`
+}
+
+type graphqlResponse struct {
+	Data   json.RawMessage `json:"data"`
+	Errors []struct {
+		Message string `json:"message"`
+	} `json:"errors"`
+}
+
+// graphql runs a query or mutation of the Canvas GraphQL API, for the actions
+// missing from the REST API, and decodes its data into dst.
+func (c *CanvasClient) graphql(ctx context.Context, query string, variables map[string]any, dst any) (code int, err error) {
+	body, err := json.Marshal(graphqlRequest{Query: query, Variables: variables})
+	if err != nil {
+		return http.StatusInternalServerError, err
+	}
+
+	requestUrl := fmt.Sprintf("%s/api/graphql", c.HtmlUrl)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, requestUrl, bytes.NewReader(body))
+	if err != nil {
+		return http.StatusInternalServerError, err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	data, _, code, err := c.httpClient.do(req)
+	if err != nil {
+		return code, err
+	}
+
+	var res graphqlResponse
+	if err := json.Unmarshal(data, &res); err != nil {
+		return http.StatusInternalServerError, err
+	}
+
+	if len(res.Errors) > 0 {
+		messages := make([]string, 0, len(res.Errors))
+
+		for _, e := range res.Errors {
+			messages = append(messages, e.Message)
+		}
+
+		return http.StatusBadGateway, errors.New("graphql: " + strings.Join(messages, "; "))
+	}
+
+	if err := json.Unmarshal(res.Data, dst); err != nil {
+		return http.StatusInternalServerError, err
+	}
+
+	return http.StatusOK, nil
+}
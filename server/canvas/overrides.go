@@ -0,0 +1,125 @@
+package canvas
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/guregu/null/v5"
+)
+
+// AssignmentOverride changes the dates of an assignment for some students
+// (ADHOC), a section or a group.
+type AssignmentOverride struct {
+	ID              int         `json:"id"`
+	AssignmentID    int         `json:"assignment_id"`
+	Title           string      `json:"title"`
+	StudentIDs      []int       `json:"student_ids"`
+	CourseSectionID null.Int    `json:"course_section_id"`
+	GroupID         null.Int    `json:"group_id"`
+	DueAt           null.String `json:"due_at"`
+	UnlockAt        null.String `json:"unlock_at"`
+	LockAt          null.String `json:"lock_at"`
+}
+
+// AdhocAssignmentOverride sets the due and lock dates of some students, the
+// unlock date stays the one of the assignment.
+type AdhocAssignmentOverride struct {
+	StudentIDs []int       `json:"student_ids"`
+	DueAt      null.String `json:"due_at"`
+	LockAt     null.String `json:"lock_at"`
+}
+
+func (c *CanvasClient) GetAssignmentOverrides(ctx context.Context, courseID, assignmentID int) (results []AssignmentOverride, code int, err error) {
+	params := url.Values{}
+
+	params.Add("per_page", strconv.Itoa(c.pageSize))
+
+	requestUrl := fmt.Sprintf("%s/courses/%d/assignments/%d/overrides?%s", c.baseUrl, courseID, assignmentID, params.Encode())
+
+	for {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, requestUrl, nil)
+		if err != nil {
+			return nil, http.StatusInternalServerError, err
+		}
+
+		data, link, code, err := c.httpClient.do(req)
+		if err != nil {
+			return nil, code, err
+		}
+
+		overrides := []AssignmentOverride{}
+		if err := json.Unmarshal(data, &overrides); err != nil {
+			return nil, http.StatusInternalServerError, err
+		}
+
+		results = append(results, overrides...)
+
+		nextUrl := getNextUrl(link)
+
+		if nextUrl == "" {
+			break
+		}
+
+		requestUrl = nextUrl
+	}
+
+	return results, http.StatusOK, nil
+}
+
+func (c *CanvasClient) CreateAssignmentOverride(ctx context.Context, courseID, assignmentID int, override AdhocAssignmentOverride) (result AssignmentOverride, code int, err error) {
+	requestUrl := fmt.Sprintf("%s/courses/%d/assignments/%d/overrides", c.baseUrl, courseID, assignmentID)
+
+	return c.writeAssignmentOverride(ctx, http.MethodPost, requestUrl, override)
+}
+
+func (c *CanvasClient) UpdateAssignmentOverride(ctx context.Context, courseID, assignmentID, overrideID int, override AdhocAssignmentOverride) (result AssignmentOverride, code int, err error) {
+	requestUrl := fmt.Sprintf("%s/courses/%d/assignments/%d/overrides/%d", c.baseUrl, courseID, assignmentID, overrideID)
+
+	return c.writeAssignmentOverride(ctx, http.MethodPut, requestUrl, override)
+}
+
+func (c *CanvasClient) DeleteAssignmentOverride(ctx context.Context, courseID, assignmentID, overrideID int) (code int, err error) {
+	requestUrl := fmt.Sprintf("%s/courses/%d/assignments/%d/overrides/%d", c.baseUrl, courseID, assignmentID, overrideID)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, requestUrl, nil)
+	if err != nil {
+		return http.StatusInternalServerError, err
+	}
+
+	_, _, code, err = c.httpClient.do(req)
+	if err != nil {
+		return code, err
+	}
+
+	return http.StatusOK, nil
+}
+
+func (c *CanvasClient) writeAssignmentOverride(ctx context.Context, method, requestUrl string, override AdhocAssignmentOverride) (result AssignmentOverride, code int, err error) {
+	body, err := json.Marshal(map[string]AdhocAssignmentOverride{"assignment_override": override})
+	if err != nil {
+		return result, http.StatusInternalServerError, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, requestUrl, bytes.NewReader(body))
+	if err != nil {
+		return result, http.StatusInternalServerError, err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	data, _, code, err := c.httpClient.do(req)
+	if err != nil {
+		return result, code, err
+	}
+
+	if err := json.Unmarshal(data, &result); err != nil {
+		return result, http.StatusInternalServerError, err
+	}
+
+	return result, http.StatusOK, nil
+}
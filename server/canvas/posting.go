@@ -0,0 +1,120 @@
+package canvas
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// PostingProgress is the Canvas job posting or hiding the grades of an
+// assignment, the students see the change once it completes.
+type PostingProgress struct {
+	ID    string `json:"_id"`
+	State string `json:"state"`
+}
+
+type postingPayload struct {
+	Progress PostingProgress `json:"progress"`
+	Errors   []struct {
+		Attribute string `json:"attribute"`
+		Message   string `json:"message"`
+	} `json:"errors"`
+}
+
+const postAssignmentGradesMutation = `mutation($assignmentId: ID!, $gradedOnly: Boolean) {
+  result: postAssignmentGrades(input: {assignmentId: $assignmentId, gradedOnly: $gradedOnly}) {
+    progress { _id state }
+    errors { attribute message }
+  }
+}`
+
+const postAssignmentGradesForSectionsMutation = `mutation($assignmentId: ID!, $sectionIds: [ID!]!, $gradedOnly: Boolean) {
+  result: postAssignmentGradesForSections(input: {assignmentId: $assignmentId, sectionIds: $sectionIds, gradedOnly: $gradedOnly}) {
+    progress { _id state }
+    errors { attribute message }
+  }
+}`
+
+const hideAssignmentGradesMutation = `mutation($assignmentId: ID!) {
+  result: hideAssignmentGrades(input: {assignmentId: $assignmentId}) {
+    progress { _id state }
+    errors { attribute message }
+  }
+}`
+
+const hideAssignmentGradesForSectionsMutation = `mutation($assignmentId: ID!, $sectionIds: [ID!]!) {
+  result: hideAssignmentGradesForSections(input: {assignmentId: $assignmentId, sectionIds: $sectionIds}) {
+    progress { _id state }
+    errors { attribute message }
+  }
+}`
+
+// PostAssignmentGrades posts the graded submissions of an assignment to the
+// students, of the sections only when sectionIDs is set.
+func (c *CanvasClient) PostAssignmentGrades(ctx context.Context, assignmentID int, sectionIDs []int) (progress PostingProgress, code int, err error) {
+	variables := map[string]any{
+		"assignmentId": strconv.Itoa(assignmentID),
+		"gradedOnly":   true,
+	}
+
+	mutation := postAssignmentGradesMutation
+
+	if len(sectionIDs) > 0 {
+		variables["sectionIds"] = graphqlIDs(sectionIDs)
+		mutation = postAssignmentGradesForSectionsMutation
+	}
+
+	return c.postingMutation(ctx, mutation, variables)
+}
+
+// HideAssignmentGrades hides the grades of an assignment from the students,
+// of the sections only when sectionIDs is set.
+func (c *CanvasClient) HideAssignmentGrades(ctx context.Context, assignmentID int, sectionIDs []int) (progress PostingProgress, code int, err error) {
+	variables := map[string]any{
+		"assignmentId": strconv.Itoa(assignmentID),
+	}
+
+	mutation := hideAssignmentGradesMutation
+
+	if len(sectionIDs) > 0 {
+		variables["sectionIds"] = graphqlIDs(sectionIDs)
+		mutation = hideAssignmentGradesForSectionsMutation
+	}
+
+	return c.postingMutation(ctx, mutation, variables)
+}
+
+func (c *CanvasClient) postingMutation(ctx context.Context, mutation string, variables map[string]any) (progress PostingProgress, code int, err error) {
+	var data struct {
+		Result postingPayload `json:"result"`
+	}
+
+	code, err = c.graphql(ctx, mutation, variables, &data)
+	if err != nil {
+		return progress, code, err
+	}
+
+	if len(data.Result.Errors) > 0 {
+		messages := make([]string, 0, len(data.Result.Errors))
+
+		for _, e := range data.Result.Errors {
+			messages = append(messages, e.Attribute+" "+e.Message)
+		}
+
+		return progress, http.StatusUnprocessableEntity, errors.New(strings.Join(messages, "; "))
+	}
+
+	return data.Result.Progress, http.StatusOK, nil
+}
+
+func graphqlIDs(ids []int) []string {
+	results := make([]string, 0, len(ids))
+
+	for _, id := range ids {
+		results = append(results, strconv.Itoa(id))
+	}
+
+	return results
+}
@@ -1,12 +1,14 @@
 package canvas
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
 	"net/url"
 	"strconv"
+	"time"
 
 	"github.com/guregu/null/v5"
 )
@@ -33,23 +35,128 @@ type Submission struct {
 	GradedAt                      null.String `json:"graded_at"`
 	GraderID                      null.Int    `json:"grader_id"`
 	Late                          bool        `json:"late"`
+	Missing                       bool        `json:"missing"`
 	Excused                       null.Bool   `json:"excused"`
+	PostedAt                      null.String `json:"posted_at"`      // null while the grade is hidden from the student
+	ExtraAttempts                 null.Int    `json:"extra_attempts"` // granted to the student on top of the allowed attempts
 	Assignment                    struct {
-		ID             int        `json:"id"`
-		PointsPossible null.Float `json:"points_possible"`
-		Name           string     `json:"name"`
+		ID                int         `json:"id"`
+		AllowedAttempts   int         `json:"allowed_attempts"` // -1 for unlimited
+		PointsPossible    null.Float  `json:"points_possible"`
+		Name              string      `json:"name"`
+		LockAt            null.String `json:"lock_at"`
+		GradingType       string      `json:"grading_type"`
+		AssignmentGroupID int         `json:"assignment_group_id"`
+		SubmissionTypes   []string    `json:"submission_types"`
 	} `json:"assignment"`
 }
 
+// AllStudents is the studentID of GetSubmissionsByCourseID returning the
+// submissions of every student of the course in one pass.
+const AllStudents = 0
+
+// An empty submissionWorkflowState returns submissions in any state.
 func (c *CanvasClient) GetSubmissionsByCourseID(ctx context.Context, courseID int, studentID int, submissionWorkflowState SubmissionWorkflowState) (results []Submission, code int, err error) {
 	params := url.Values{}
 
 	params.Add("page", "1")
 	params.Add("per_page", strconv.Itoa(c.pageSize))
-	params.Add("student_ids[]", strconv.Itoa(studentID))
+	params.Add("include[]", "assignment")
+
+	if studentID == AllStudents {
+		params.Add("student_ids[]", "all")
+	} else {
+		params.Add("student_ids[]", strconv.Itoa(studentID))
+	}
+
+	if submissionWorkflowState != "" {
+		params.Add("workflow_state", string(submissionWorkflowState))
+	}
+
+	requestUrl := fmt.Sprintf("%s/courses/%d/students/submissions?%s", c.baseUrl, courseID, params.Encode())
+
+	for {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, requestUrl, nil)
+		if err != nil {
+			return nil, http.StatusInternalServerError, err
+		}
+
+		data, link, code, err := c.httpClient.do(req)
+		if err != nil {
+			return nil, code, err
+		}
+
+		submissions := []Submission{}
+		if err := json.Unmarshal(data, &submissions); err != nil {
+			return nil, http.StatusInternalServerError, err
+		}
+
+		results = append(results, submissions...)
+
+		nextUrl := getNextUrl(link)
+		if nextUrl == "" {
+			break
+		}
+
+		requestUrl = nextUrl
+	}
+
+	return results, http.StatusOK, nil
+}
+
+// GetSubmissionsBySectionID returns the submissions of all students of a section.
+func (c *CanvasClient) GetSubmissionsBySectionID(ctx context.Context, sectionID int, submissionWorkflowState SubmissionWorkflowState) (results []Submission, code int, err error) {
+	params := url.Values{}
+
+	params.Add("page", "1")
+	params.Add("per_page", strconv.Itoa(c.pageSize))
+	params.Add("student_ids[]", "all")
 	params.Add("include[]", "assignment")
 	params.Add("workflow_state", string(submissionWorkflowState))
 
+	requestUrl := fmt.Sprintf("%s/sections/%d/students/submissions?%s", c.baseUrl, sectionID, params.Encode())
+
+	for {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, requestUrl, nil)
+		if err != nil {
+			return nil, http.StatusInternalServerError, err
+		}
+
+		data, link, code, err := c.httpClient.do(req)
+		if err != nil {
+			return nil, code, err
+		}
+
+		submissions := []Submission{}
+		if err := json.Unmarshal(data, &submissions); err != nil {
+			return nil, http.StatusInternalServerError, err
+		}
+
+		results = append(results, submissions...)
+
+		nextUrl := getNextUrl(link)
+		if nextUrl == "" {
+			break
+		}
+
+		requestUrl = nextUrl
+	}
+
+	return results, http.StatusOK, nil
+}
+
+// GetGradedSubmissionsByCourseID returns the graded submissions of all
+// students of a course graded since gradedSince.
+func (c *CanvasClient) GetGradedSubmissionsByCourseID(ctx context.Context, courseID int, gradedSince time.Time) (results []Submission, code int, err error) {
+	params := url.Values{}
+
+	params.Add("page", "1")
+	params.Add("per_page", strconv.Itoa(c.pageSize))
+	params.Add("student_ids[]", "all")
+	params.Add("include[]", "assignment")
+	params.Add("workflow_state", string(GradedSubmissionWorkflowState))
+	params.Add("graded_since", gradedSince.UTC().Format(time.RFC3339))
+
 	requestUrl := fmt.Sprintf("%s/courses/%d/students/submissions?%s", c.baseUrl, courseID, params.Encode())
 
 	for {
@@ -80,3 +187,52 @@ func (c *CanvasClient) GetSubmissionsByCourseID(ctx context.Context, courseID in
 
 	return results, http.StatusOK, nil
 }
+
+func (c *CanvasClient) GetSubmission(ctx context.Context, courseID, assignmentID, userID int) (submission Submission, code int, err error) {
+	requestUrl := fmt.Sprintf("%s/courses/%d/assignments/%d/submissions/%d?include[]=assignment", c.baseUrl, courseID, assignmentID, userID)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, requestUrl, nil)
+	if err != nil {
+		return submission, http.StatusInternalServerError, err
+	}
+
+	data, _, code, err := c.httpClient.do(req)
+	if err != nil {
+		return submission, code, err
+	}
+
+	if err := json.Unmarshal(data, &submission); err != nil {
+		return submission, http.StatusInternalServerError, err
+	}
+
+	return submission, http.StatusOK, nil
+}
+
+// UpdateSubmissionExtraAttempts sets the attempts a student has on top of the
+// allowed attempts of the assignment.
+func (c *CanvasClient) UpdateSubmissionExtraAttempts(ctx context.Context, courseID, assignmentID, userID, extraAttempts int) (submission Submission, code int, err error) {
+	body, err := json.Marshal(map[string]any{"submission": map[string]int{"extra_attempts": extraAttempts}})
+	if err != nil {
+		return submission, http.StatusInternalServerError, err
+	}
+
+	requestUrl := fmt.Sprintf("%s/courses/%d/assignments/%d/submissions/%d", c.baseUrl, courseID, assignmentID, userID)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, requestUrl, bytes.NewReader(body))
+	if err != nil {
+		return submission, http.StatusInternalServerError, err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	data, _, code, err := c.httpClient.do(req)
+	if err != nil {
+		return submission, code, err
+	}
+
+	if err := json.Unmarshal(data, &submission); err != nil {
+		return submission, http.StatusInternalServerError, err
+	}
+
+	return submission, http.StatusOK, nil
+}
@@ -0,0 +1,70 @@
+package canvas
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/guregu/null/v5"
+)
+
+type EnrollmentTerm struct {
+	ID            int         `json:"id"`
+	Name          string      `json:"name"`
+	SISTermID     null.String `json:"sis_term_id"`
+	StartAt       null.String `json:"start_at"`
+	EndAt         null.String `json:"end_at"`
+	WorkflowState string      `json:"workflow_state"`
+}
+
+type EnrollmentTermWorkflowState string
+
+const (
+	ActiveEnrollmentTermWorkflowState  EnrollmentTermWorkflowState = "active"
+	DeletedEnrollmentTermWorkflowState EnrollmentTermWorkflowState = "deleted"
+	AllEnrollmentTermWorkflowState     EnrollmentTermWorkflowState = "all"
+)
+
+// Terms belong to the root account, sub-accounts share them.
+func (c *CanvasClient) GetEnrollmentTermsByAccountID(ctx context.Context, rootAccountID int, workflowState EnrollmentTermWorkflowState) (results []EnrollmentTerm, code int, err error) {
+	params := url.Values{}
+
+	params.Add("per_page", strconv.Itoa(c.pageSize))
+	params.Add("workflow_state[]", string(workflowState))
+
+	requestUrl := fmt.Sprintf("%s/accounts/%d/terms?%s", c.baseUrl, rootAccountID, params.Encode())
+
+	for {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, requestUrl, nil)
+		if err != nil {
+			return nil, http.StatusInternalServerError, err
+		}
+
+		data, link, code, err := c.httpClient.do(req)
+		if err != nil {
+			return nil, code, err
+		}
+
+		terms := struct {
+			EnrollmentTerms []EnrollmentTerm `json:"enrollment_terms"`
+		}{}
+		if err := json.Unmarshal(data, &terms); err != nil {
+			return nil, http.StatusInternalServerError, err
+		}
+
+		results = append(results, terms.EnrollmentTerms...)
+
+		nextUrl := getNextUrl(link)
+
+		if nextUrl == "" {
+			break
+		}
+
+		requestUrl = nextUrl
+	}
+
+	return results, http.StatusOK, nil
+}
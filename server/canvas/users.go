@@ -5,12 +5,20 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"net/url"
 )
 
 type User struct {
 	ID        int    `json:"id"`
 	Name      string `json:"name"`
 	SISUserID string `json:"sis_user_id"`
+	LoginID   string `json:"login_id"` // only returned to account admins
+}
+
+// UserDisplay is the short form of a user Canvas embeds in other objects.
+type UserDisplay struct {
+	ID          int    `json:"id"`
+	DisplayName string `json:"display_name"`
 }
 
 func (c *CanvasClient) GetUserBySisID(ctx context.Context, sisID string) (user User, code int, err error) {
@@ -32,6 +40,26 @@ func (c *CanvasClient) GetUserBySisID(ctx context.Context, sisID string) (user U
 	return user, http.StatusOK, nil
 }
 
+// Login ids are usually the email addresses staff sign in with.
+func (c *CanvasClient) GetUserByLoginID(ctx context.Context, loginID string) (user User, code int, err error) {
+	requestUrl := fmt.Sprintf("%s/users/sis_login_id:%s", c.baseUrl, url.PathEscape(loginID))
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, requestUrl, nil)
+	if err != nil {
+		return user, http.StatusInternalServerError, err
+	}
+
+	data, _, code, err := c.httpClient.do(req)
+	if err != nil {
+		return user, code, err
+	}
+	if err := json.Unmarshal(data, &user); err != nil {
+		return user, http.StatusInternalServerError, err
+	}
+
+	return user, http.StatusOK, nil
+}
+
 func (c *CanvasClient) GetUserByID(ctx context.Context, userID int) (user User, code int, err error) {
 	requestUrl := fmt.Sprintf("%s/users/%d", c.baseUrl, userID)
 
@@ -3,7 +3,9 @@ package main
 import (
 	"canvas-admin/api"
 	"canvas-admin/canvas"
+	"canvas-admin/sla"
 	"canvas-admin/supabase"
+	"canvas-admin/transcript"
 	"context"
 	"log"
 	"os"
@@ -51,6 +53,9 @@ func init() {
 
 	canvasClient := canvas.NewCanvasClient(canvasBaseUrl, canvasAccessToken, canvasPageSize, canvasHtmlUrl)
 
+	// optional, act as the signed in staff member for write actions
+	canvasClient.Masquerade = os.Getenv("CANVAS_MASQUERADE") == "true"
+
 	supabaseBaseUrl := os.Getenv("SUPABASE_BASE_URL")
 	if supabaseBaseUrl == "" {
 		log.Panic("missing env: SUPABASE_BASE_URL")
@@ -71,7 +76,42 @@ func init() {
 		log.Panic(err)
 	}
 
-	controller := api.NewAPIController(canvasClient, supabaseClient, []byte(supabaseJwtSecret))
+	// Supabase signs access tokens with the jwt secret (HS256) or, once asymmetric
+	// signing keys are enabled, with keys published at the jwks url (RS256, ES256).
+	authConfig := api.AuthConfig{
+		Secret:   []byte(supabaseJwtSecret),
+		JWKSURL:  supabaseBaseUrl + "/auth/v1/.well-known/jwks.json",
+		Issuer:   supabaseBaseUrl + "/auth/v1",
+		Audience: "authenticated",
+	}
+
+	if err := authConfig.Validate(); err != nil {
+		log.Panic(err)
+	}
+
+	// optional, see sla.LoadConfig for the SLA_* and SMTP_* variables
+	slaConfig, err := sla.LoadConfig(os.Getenv)
+	if err != nil {
+		log.Panic(err)
+	}
+
+	// optional, see transcript.LoadConfig for the TRANSCRIPT_* variables
+	transcriptConfig, err := transcript.LoadConfig(os.Getenv)
+	if err != nil {
+		log.Panic(err)
+	}
+
+	// optional, Canvas Live Events signed with a shared secret (HS256) or with
+	// keys published at a jwks url (RS256, ES256), for the issuer and audience
+	// set on the subscription, refused when unset
+	liveEventsConfig := api.LiveEventsConfig{
+		Secret:   []byte(os.Getenv("LIVE_EVENTS_SECRET")),
+		JWKSURL:  os.Getenv("LIVE_EVENTS_JWKS_URL"),
+		Issuer:   os.Getenv("LIVE_EVENTS_ISSUER"),
+		Audience: os.Getenv("LIVE_EVENTS_AUDIENCE"),
+	}
+
+	controller := api.NewAPIController(canvasClient, supabaseClient, authConfig, slaConfig, transcriptConfig, liveEventsConfig)
 
 	router := api.NewRouter(controller, webUrl)
 
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"bytes"
+	"canvas-admin/api"
+	"encoding/json"
+	"flag"
+	"log"
+	"os"
+)
+
+// Writes the OpenAPI document of the API and the TypeScript client generated
+// from it. With -check, fails when the files on disk are out of date instead.
+//
+//	go run ./cmd/openapi -json openapi.json -ts ../web/src/canvas/api.gen.ts
+func main() {
+	jsonPath := flag.String("json", "openapi.json", "path of the OpenAPI document")
+	tsPath := flag.String("ts", "../web/src/canvas/api.gen.ts", "path of the generated TypeScript client")
+	axiosPath := flag.String("axios", "../axios", "import path of the axios instance used by the TypeScript client")
+	check := flag.Bool("check", false, "fail if the generated files are out of date")
+	flag.Parse()
+
+	doc := api.OpenAPI()
+
+	spec, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		log.Fatalf("error encoding openapi document: %v", err)
+	}
+
+	files := map[string][]byte{
+		*jsonPath: append(spec, '\n'),
+		*tsPath:   doc.TypeScript(*axiosPath),
+	}
+
+	for path, data := range files {
+		if *check {
+			existing, err := os.ReadFile(path)
+			if err != nil || !bytes.Equal(existing, data) {
+				log.Fatalf("%s is out of date, run go run ./cmd/openapi", path)
+			}
+
+			continue
+		}
+
+		if err := os.WriteFile(path, data, 0644); err != nil {
+			log.Fatalf("error writing %s: %v", path, err)
+		}
+	}
+}
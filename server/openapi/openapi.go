@@ -0,0 +1,255 @@
+package openapi
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/guregu/null/v5"
+)
+
+type Document struct {
+	OpenAPI    string                          `json:"openapi"`
+	Info       Info                            `json:"info"`
+	Paths      map[string]map[string]Operation `json:"paths"`
+	Components Components                      `json:"components"`
+
+	schemaTypes map[string]reflect.Type
+}
+
+type Info struct {
+	Title   string `json:"title"`
+	Version string `json:"version"`
+}
+
+type Components struct {
+	Schemas         map[string]*Schema        `json:"schemas"`
+	SecuritySchemes map[string]SecurityScheme `json:"securitySchemes,omitempty"`
+}
+
+type SecurityScheme struct {
+	Type         string `json:"type"`
+	Scheme       string `json:"scheme,omitempty"`
+	BearerFormat string `json:"bearerFormat,omitempty"`
+	In           string `json:"in,omitempty"`
+	Name         string `json:"name,omitempty"`
+}
+
+type Operation struct {
+	OperationID string                `json:"operationId"`
+	Summary     string                `json:"summary,omitempty"`
+	Description string                `json:"description,omitempty"`
+	Parameters  []Parameter           `json:"parameters,omitempty"`
+	RequestBody *RequestBody          `json:"requestBody,omitempty"`
+	Responses   map[string]Response   `json:"responses"`
+	Security    []map[string][]string `json:"security,omitempty"`
+}
+
+type Parameter struct {
+	Name     string  `json:"name"`
+	In       string  `json:"in"`
+	Required bool    `json:"required"`
+	Schema   *Schema `json:"schema"`
+}
+
+type RequestBody struct {
+	Required bool                 `json:"required"`
+	Content  map[string]MediaType `json:"content"`
+}
+
+type Response struct {
+	Description string               `json:"description"`
+	Content     map[string]MediaType `json:"content,omitempty"`
+}
+
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Nullable             bool               `json:"nullable,omitempty"`
+	Enum                 []string           `json:"enum,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+}
+
+const (
+	JSON = "application/json"
+
+	schemaRefPrefix = "#/components/schemas/"
+)
+
+var (
+	nullStringType = reflect.TypeOf(null.String{})
+	nullIntType    = reflect.TypeOf(null.Int{})
+	nullFloatType  = reflect.TypeOf(null.Float{})
+	nullBoolType   = reflect.TypeOf(null.Bool{})
+	nullTimeType   = reflect.TypeOf(null.Time{})
+	timeType       = reflect.TypeOf(time.Time{})
+)
+
+func NewDocument(title, version string) *Document {
+	return &Document{
+		OpenAPI: "3.0.3",
+		Info: Info{
+			Title:   title,
+			Version: version,
+		},
+		Paths: make(map[string]map[string]Operation),
+		Components: Components{
+			Schemas:         make(map[string]*Schema),
+			SecuritySchemes: make(map[string]SecurityScheme),
+		},
+		schemaTypes: make(map[string]reflect.Type),
+	}
+}
+
+func (d *Document) AddOperation(method, path string, op Operation) {
+	if d.Paths[path] == nil {
+		d.Paths[path] = make(map[string]Operation)
+	}
+
+	d.Paths[path][strings.ToLower(method)] = op
+}
+
+// SchemaOf returns the schema of the JSON encoding of t. Named structs are
+// added to the components and referenced.
+func (d *Document) SchemaOf(t reflect.Type) *Schema {
+	switch t {
+	case nullStringType:
+		return &Schema{Type: "string", Nullable: true}
+	case nullIntType:
+		return &Schema{Type: "integer", Nullable: true}
+	case nullFloatType:
+		return &Schema{Type: "number", Nullable: true}
+	case nullBoolType:
+		return &Schema{Type: "boolean", Nullable: true}
+	case nullTimeType:
+		return &Schema{Type: "string", Format: "date-time", Nullable: true}
+	case timeType:
+		return &Schema{Type: "string", Format: "date-time"}
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer:
+		s := d.SchemaOf(t.Elem())
+		if s.Ref != "" {
+			return s
+		}
+
+		s.Nullable = true
+
+		return s
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: "integer"}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte"}
+		}
+
+		return &Schema{Type: "array", Items: d.SchemaOf(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: d.SchemaOf(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return d.structSchema(t)
+		}
+
+		name := d.schemaName(t)
+
+		if _, ok := d.Components.Schemas[name]; !ok {
+			// placeholder stops recursion on self referencing types
+			d.Components.Schemas[name] = &Schema{}
+			*d.Components.Schemas[name] = *d.structSchema(t)
+		}
+
+		return &Schema{Ref: schemaRefPrefix + name}
+	case reflect.Interface:
+		return &Schema{}
+	}
+
+	panic(fmt.Sprintf("openapi: unsupported type %s", t))
+}
+
+func (d *Document) structSchema(t reflect.Type) *Schema {
+	s := &Schema{
+		Type:       "object",
+		Properties: make(map[string]*Schema),
+	}
+
+	d.addFields(s, t)
+
+	sort.Strings(s.Required)
+
+	return s
+}
+
+func (d *Document) addFields(s *Schema, t reflect.Type) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+
+		name, opts, _ := strings.Cut(tag, ",")
+
+		if f.Anonymous && name == "" && f.Type.Kind() == reflect.Struct {
+			d.addFields(s, f.Type)
+			continue
+		}
+
+		if !f.IsExported() {
+			continue
+		}
+
+		if name == "" {
+			name = f.Name
+		}
+
+		s.Properties[name] = d.SchemaOf(f.Type)
+
+		if !strings.Contains(opts, "omitempty") {
+			s.Required = append(s.Required, name)
+		}
+	}
+}
+
+// schemaName exports the Go type name, e.g. errorResponse becomes
+// ErrorResponse. Names used by another type are prefixed with the package
+// name, e.g. CanvasGradeChangeLogCourse.
+func (d *Document) schemaName(t reflect.Type) string {
+	name := upperFirst(t.Name())
+
+	if owner, ok := d.schemaTypes[name]; ok && owner != t {
+		pkg := t.PkgPath()[strings.LastIndex(t.PkgPath(), "/")+1:]
+		name = upperFirst(pkg) + name
+	}
+
+	d.schemaTypes[name] = t
+
+	return name
+}
+
+func upperFirst(s string) string {
+	r := []rune(s)
+	r[0] = unicode.ToUpper(r[0])
+
+	return string(r)
+}
@@ -0,0 +1,239 @@
+package openapi
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// TypeScript renders the component schemas as TypeScript interfaces and each
+// operation as an axios call. The axios instance is imported from axiosPath.
+func (d *Document) TypeScript(axiosPath string) []byte {
+	var b bytes.Buffer
+
+	b.WriteString("// Code generated by cmd/openapi. DO NOT EDIT.\n\n")
+	b.WriteString("import { AxiosRequestConfig } from 'axios';\n")
+	fmt.Fprintf(&b, "import { axios } from '%s';\n", axiosPath)
+
+	names := make([]string, 0, len(d.Components.Schemas))
+	for name := range d.Components.Schemas {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		fmt.Fprintf(&b, "\nexport interface %s %s\n", name, tsObject(d.Components.Schemas[name], ""))
+	}
+
+	type entry struct {
+		method string
+		path   string
+		op     Operation
+	}
+
+	entries := make([]entry, 0)
+	for path, ops := range d.Paths {
+		for method, op := range ops {
+			entries = append(entries, entry{method, path, op})
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].op.OperationID < entries[j].op.OperationID
+	})
+
+	for _, e := range entries {
+		writeTSOperation(&b, e.method, e.path, e.op)
+	}
+
+	return b.Bytes()
+}
+
+func writeTSOperation(b *bytes.Buffer, method, path string, op Operation) {
+	fn := lowerFirst(op.OperationID)
+	paramsType := op.OperationID + "Params"
+
+	args := make([]string, 0, 3)
+
+	if len(op.Parameters) > 0 {
+		fmt.Fprintf(b, "\nexport interface %s {\n", paramsType)
+
+		for _, p := range op.Parameters {
+			optional := ""
+			if !p.Required {
+				optional = "?"
+			}
+
+			fmt.Fprintf(b, "  %s%s: %s;\n", p.Name, optional, tsType(p.Schema, "  "))
+		}
+
+		b.WriteString("}\n")
+
+		args = append(args, "params: "+paramsType)
+	}
+
+	if op.RequestBody != nil {
+		if mt, ok := op.RequestBody.Content[JSON]; ok {
+			args = append(args, "body: "+tsType(mt.Schema, ""))
+		}
+	}
+
+	args = append(args, "config?: AxiosRequestConfig")
+
+	url := path
+	query := make([]string, 0)
+
+	for _, p := range op.Parameters {
+		switch p.In {
+		case "path":
+			url = strings.ReplaceAll(url, "{"+p.Name+"}", "${params."+p.Name+"}")
+		case "query":
+			query = append(query, fmt.Sprintf("%s: params.%s", p.Name, p.Name))
+		}
+	}
+
+	switch {
+	case op.Description != "":
+		fmt.Fprintf(b, "\n/**\n * %s\n *\n * %s\n */", op.Summary, strings.ReplaceAll(op.Description, "\n", "\n * "))
+	case op.Summary != "":
+		fmt.Fprintf(b, "\n/** %s */", op.Summary)
+	}
+
+	fmt.Fprintf(b, "\nexport const %s = async (\n  %s\n) => {\n", fn, strings.Join(args, ",\n  "))
+	response := tsResponseType(op)
+
+	fmt.Fprintf(b, "  const { data } = await axios.request<%s>({\n", response)
+	b.WriteString("    ...config,\n")
+
+	if response == "Blob" {
+		b.WriteString("    responseType: 'blob',\n")
+	}
+
+	fmt.Fprintf(b, "    method: '%s',\n", method)
+	fmt.Fprintf(b, "    url: `%s`,\n", url)
+
+	if len(query) > 0 {
+		fmt.Fprintf(b, "    params: { %s },\n", strings.Join(query, ", "))
+	}
+
+	if op.RequestBody != nil {
+		b.WriteString("    data: body,\n")
+	}
+
+	b.WriteString("  });\n\n  return data;\n};\n")
+}
+
+func tsResponseType(op Operation) string {
+	codes := make([]string, 0, len(op.Responses))
+	for code := range op.Responses {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+
+	for _, code := range codes {
+		if !strings.HasPrefix(code, "2") {
+			continue
+		}
+
+		if mt, ok := op.Responses[code].Content[JSON]; ok {
+			return tsType(mt.Schema, "")
+		}
+
+		if len(op.Responses[code].Content) > 0 {
+			return "Blob"
+		}
+	}
+
+	return "void"
+}
+
+func tsType(s *Schema, indent string) string {
+	t := tsBaseType(s, indent)
+
+	if s.Nullable {
+		return t + " | null"
+	}
+
+	return t
+}
+
+func tsBaseType(s *Schema, indent string) string {
+	if s.Ref != "" {
+		return strings.TrimPrefix(s.Ref, schemaRefPrefix)
+	}
+
+	switch s.Type {
+	case "string":
+		if len(s.Enum) > 0 {
+			values := make([]string, 0, len(s.Enum))
+			for _, e := range s.Enum {
+				values = append(values, fmt.Sprintf("'%s'", e))
+			}
+
+			return strings.Join(values, " | ")
+		}
+
+		return "string"
+	case "integer", "number":
+		return "number"
+	case "boolean":
+		return "boolean"
+	case "array":
+		item := tsType(s.Items, indent)
+		if strings.Contains(item, " ") {
+			item = "(" + item + ")"
+		}
+
+		return item + "[]"
+	case "object":
+		if s.AdditionalProperties != nil {
+			return fmt.Sprintf("Record<string, %s>", tsType(s.AdditionalProperties, indent))
+		}
+
+		return tsObject(s, indent)
+	}
+
+	return "unknown"
+}
+
+func tsObject(s *Schema, indent string) string {
+	if len(s.Properties) == 0 {
+		return "{}"
+	}
+
+	required := make(map[string]bool, len(s.Required))
+	for _, r := range s.Required {
+		required[r] = true
+	}
+
+	names := make([]string, 0, len(s.Properties))
+	for name := range s.Properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+
+	b.WriteString("{\n")
+
+	for _, name := range names {
+		optional := ""
+		if !required[name] {
+			optional = "?"
+		}
+
+		fmt.Fprintf(&b, "%s  %s%s: %s;\n", indent, name, optional, tsType(s.Properties[name], indent+"  "))
+	}
+
+	b.WriteString(indent + "}")
+
+	return b.String()
+}
+
+func lowerFirst(s string) string {
+	r := []rune(s)
+	r[0] = unicode.ToLower(r[0])
+
+	return string(r)
+}
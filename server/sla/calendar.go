@@ -0,0 +1,72 @@
+package sla
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	// the lambda runtime has no zoneinfo
+	_ "time/tzdata"
+)
+
+const dateLayout = "2006-01-02"
+
+// Calendar counts business days, Monday to Friday except holidays, in a
+// time zone.
+type Calendar struct {
+	location *time.Location
+	holidays map[string]bool
+}
+
+// NewCalendar parses holidays in the YYYY-MM-DD format.
+func NewCalendar(location *time.Location, holidays []string) (*Calendar, error) {
+	c := &Calendar{
+		location: location,
+		holidays: make(map[string]bool, len(holidays)),
+	}
+
+	for _, holiday := range holidays {
+		holiday = strings.TrimSpace(holiday)
+		if holiday == "" {
+			continue
+		}
+
+		if _, err := time.Parse(dateLayout, holiday); err != nil {
+			return nil, fmt.Errorf("invalid holiday %q, expected YYYY-MM-DD", holiday)
+		}
+
+		c.holidays[holiday] = true
+	}
+
+	return c, nil
+}
+
+func (c *Calendar) IsBusinessDay(t time.Time) bool {
+	t = t.In(c.location)
+
+	if t.Weekday() == time.Saturday || t.Weekday() == time.Sunday {
+		return false
+	}
+
+	return !c.holidays[t.Format(dateLayout)]
+}
+
+// BusinessDaysBetween counts the business days after the day of from up to
+// and including the day of to, e.g. from a Friday to the next Monday is 1.
+func (c *Calendar) BusinessDaysBetween(from, to time.Time) int {
+	from = from.In(c.location)
+	to = to.In(c.location)
+
+	day := time.Date(from.Year(), from.Month(), from.Day(), 12, 0, 0, 0, c.location)
+	last := time.Date(to.Year(), to.Month(), to.Day(), 12, 0, 0, 0, c.location)
+
+	days := 0
+
+	for day = day.AddDate(0, 0, 1); !day.After(last); day = day.AddDate(0, 0, 1) {
+		if c.IsBusinessDay(day) {
+			days++
+		}
+	}
+
+	return days
+}
@@ -0,0 +1,110 @@
+package sla
+
+import (
+	"testing"
+	"time"
+)
+
+func mustLocation(t *testing.T, name string) *time.Location {
+	t.Helper()
+
+	location, err := time.LoadLocation(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return location
+}
+
+func TestNewCalendar(t *testing.T) {
+	tests := []struct {
+		name     string
+		holidays []string
+		wantErr  bool
+	}{
+		{name: "none", holidays: nil},
+		{name: "empty env", holidays: []string{""}},
+		{name: "padded", holidays: []string{" 2026-12-25", "2026-12-28 "}},
+		{name: "invalid", holidays: []string{"25/12/2026"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewCalendar(time.UTC, tt.holidays)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("NewCalendar() = %v, want error %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestBusinessDaysBetween(t *testing.T) {
+	sydney := mustLocation(t, "Australia/Sydney")
+
+	calendar, err := NewCalendar(sydney, []string{"2026-12-25", "2026-12-28", "2027-01-01"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	at := func(value string) time.Time {
+		t.Helper()
+
+		parsed, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return parsed
+	}
+
+	tests := []struct {
+		name     string
+		from, to string
+		want     int
+	}{
+		{name: "same day", from: "2026-10-14T09:00:00+11:00", to: "2026-10-14T17:00:00+11:00", want: 0},
+		{name: "next day", from: "2026-10-14T09:00:00+11:00", to: "2026-10-15T08:00:00+11:00", want: 1},
+		{name: "friday to monday", from: "2026-10-16T16:00:00+11:00", to: "2026-10-19T09:00:00+11:00", want: 1},
+		{name: "saturday to monday", from: "2026-10-17T10:00:00+11:00", to: "2026-10-19T09:00:00+11:00", want: 1},
+		{name: "friday to sunday", from: "2026-10-16T16:00:00+11:00", to: "2026-10-18T09:00:00+11:00", want: 0},
+		{name: "two weeks", from: "2026-10-05T09:00:00+11:00", to: "2026-10-19T09:00:00+11:00", want: 10},
+		// christmas, boxing day observed and new year
+		{name: "over holidays", from: "2026-12-24T09:00:00+11:00", to: "2027-01-04T09:00:00+11:00", want: 4},
+		{name: "holiday", from: "2026-12-24T09:00:00+11:00", to: "2026-12-25T09:00:00+11:00", want: 0},
+		// a thursday afternoon in utc is already friday in sydney
+		{name: "time zone", from: "2026-10-15T14:00:00Z", to: "2026-10-16T09:00:00+11:00", want: 0},
+		{name: "daylight saving starts", from: "2026-10-02T09:00:00+10:00", to: "2026-10-05T09:00:00+11:00", want: 1},
+		{name: "backwards", from: "2026-10-19T09:00:00+11:00", to: "2026-10-14T09:00:00+11:00", want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := calendar.BusinessDaysBetween(at(tt.from), at(tt.to)); got != tt.want {
+				t.Errorf("BusinessDaysBetween(%s, %s) = %d, want %d", tt.from, tt.to, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsBusinessDay(t *testing.T) {
+	calendar, err := NewCalendar(time.UTC, []string{"2026-12-25"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		day  time.Time
+		want bool
+	}{
+		{day: time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC), want: true},
+		{day: time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC), want: false},
+		{day: time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC), want: false},
+		{day: time.Date(2026, 12, 25, 12, 0, 0, 0, time.UTC), want: false},
+	}
+
+	for _, tt := range tests {
+		if got := calendar.IsBusinessDay(tt.day); got != tt.want {
+			t.Errorf("IsBusinessDay(%s) = %v, want %v", tt.day.Format(dateLayout), got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package sla
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Config is the marking policy and where its alerts are sent.
+type Config struct {
+	// submissions must be graded within BusinessDays of being submitted
+	BusinessDays int
+	// alerts are escalated after EscalationDays more business days
+	EscalationDays int
+	Calendar       *Calendar
+	Notifier       Notifier
+}
+
+// LoadConfig reads the optional SLA_* and SMTP_* variables with getenv, e.g.
+// os.Getenv. By default submissions are due within 10 business days, escalated
+// 5 business days later, and notifications are only logged.
+func LoadConfig(getenv func(string) string) (Config, error) {
+	config := Config{
+		BusinessDays:   10,
+		EscalationDays: 5,
+	}
+
+	var err error
+
+	if v := getenv("SLA_BUSINESS_DAYS"); v != "" {
+		if config.BusinessDays, err = strconv.Atoi(v); err != nil || config.BusinessDays < 1 {
+			return config, fmt.Errorf("invalid env: SLA_BUSINESS_DAYS")
+		}
+	}
+
+	if v := getenv("SLA_ESCALATION_DAYS"); v != "" {
+		if config.EscalationDays, err = strconv.Atoi(v); err != nil || config.EscalationDays < 1 {
+			return config, fmt.Errorf("invalid env: SLA_ESCALATION_DAYS")
+		}
+	}
+
+	location := time.UTC
+
+	if v := getenv("SLA_TIMEZONE"); v != "" {
+		if location, err = time.LoadLocation(v); err != nil {
+			return config, fmt.Errorf("invalid env: SLA_TIMEZONE")
+		}
+	}
+
+	// comma separated YYYY-MM-DD dates
+	if config.Calendar, err = NewCalendar(location, strings.Split(getenv("SLA_HOLIDAYS"), ",")); err != nil {
+		return config, fmt.Errorf("invalid env: SLA_HOLIDAYS: %w", err)
+	}
+
+	switch notifier := getenv("SLA_NOTIFIER"); notifier {
+	case "", "sink":
+		config.Notifier = NewSinkNotifier()
+	case "email":
+		host := getenv("SMTP_HOST")
+		if host == "" {
+			return config, fmt.Errorf("missing env: SMTP_HOST")
+		}
+
+		port, err := strconv.Atoi(getenv("SMTP_PORT"))
+		if err != nil {
+			return config, fmt.Errorf("invalid env: SMTP_PORT")
+		}
+
+		from := getenv("SMTP_FROM")
+		if from == "" {
+			return config, fmt.Errorf("missing env: SMTP_FROM")
+		}
+
+		config.Notifier = NewEmailNotifier(host, port, getenv("SMTP_USERNAME"), getenv("SMTP_PASSWORD"), from)
+	case "webhook":
+		url := getenv("SLA_WEBHOOK_URL")
+		if url == "" {
+			return config, fmt.Errorf("missing env: SLA_WEBHOOK_URL")
+		}
+
+		config.Notifier = NewWebhookNotifier(url)
+	default:
+		return config, fmt.Errorf("invalid env: SLA_NOTIFIER %q, expected sink, email or webhook", notifier)
+	}
+
+	return config, nil
+}
+
+// Level returns the alert level of a submission waiting for grading for days
+// business days, false while it is within the policy.
+func (c Config) Level(days int) (Level, bool) {
+	switch {
+	case days > c.BusinessDays+c.EscalationDays:
+		return EscalationLevel, true
+	case days > c.BusinessDays:
+		return BreachLevel, true
+	}
+
+	return "", false
+}
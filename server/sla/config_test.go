@@ -0,0 +1,137 @@
+package sla
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestConfigLevel(t *testing.T) {
+	config := Config{BusinessDays: 10, EscalationDays: 5}
+
+	tests := []struct {
+		days      int
+		wantLevel Level
+		wantAlert bool
+	}{
+		{days: 0},
+		{days: 10},
+		{days: 11, wantLevel: BreachLevel, wantAlert: true},
+		{days: 15, wantLevel: BreachLevel, wantAlert: true},
+		{days: 16, wantLevel: EscalationLevel, wantAlert: true},
+		{days: 40, wantLevel: EscalationLevel, wantAlert: true},
+	}
+
+	for _, tt := range tests {
+		level, alert := config.Level(tt.days)
+		if level != tt.wantLevel || alert != tt.wantAlert {
+			t.Errorf("Level(%d) = %q, %v, want %q, %v", tt.days, level, alert, tt.wantLevel, tt.wantAlert)
+		}
+	}
+}
+
+func TestLoadConfig(t *testing.T) {
+	tests := []struct {
+		name         string
+		env          map[string]string
+		wantErr      bool
+		wantNotifier Notifier
+		wantDays     int
+	}{
+		{
+			name:         "defaults",
+			env:          map[string]string{},
+			wantNotifier: &SinkNotifier{},
+			wantDays:     10,
+		},
+		{
+			name: "email",
+			env: map[string]string{
+				"SLA_NOTIFIER":      "email",
+				"SLA_BUSINESS_DAYS": "5",
+				"SMTP_HOST":         "smtp.example.edu",
+				"SMTP_PORT":         "587",
+				"SMTP_FROM":         "marking@example.edu",
+			},
+			wantNotifier: &EmailNotifier{},
+			wantDays:     5,
+		},
+		{
+			name: "webhook",
+			env: map[string]string{
+				"SLA_NOTIFIER":    "webhook",
+				"SLA_WEBHOOK_URL": "https://hooks.example.edu/sla",
+				"SLA_TIMEZONE":    "Australia/Sydney",
+				"SLA_HOLIDAYS":    "2026-12-25,2026-12-28",
+			},
+			wantNotifier: &WebhookNotifier{},
+			wantDays:     10,
+		},
+		{
+			name:    "email without host",
+			env:     map[string]string{"SLA_NOTIFIER": "email", "SMTP_PORT": "587", "SMTP_FROM": "marking@example.edu"},
+			wantErr: true,
+		},
+		{
+			name:    "webhook without url",
+			env:     map[string]string{"SLA_NOTIFIER": "webhook"},
+			wantErr: true,
+		},
+		{
+			name:    "unknown notifier",
+			env:     map[string]string{"SLA_NOTIFIER": "pager"},
+			wantErr: true,
+		},
+		{
+			name:    "invalid business days",
+			env:     map[string]string{"SLA_BUSINESS_DAYS": "0"},
+			wantErr: true,
+		},
+		{
+			name:    "invalid escalation days",
+			env:     map[string]string{"SLA_ESCALATION_DAYS": "soon"},
+			wantErr: true,
+		},
+		{
+			name:    "invalid time zone",
+			env:     map[string]string{"SLA_TIMEZONE": "Mars/Olympus_Mons"},
+			wantErr: true,
+		},
+		{
+			name:    "invalid holiday",
+			env:     map[string]string{"SLA_HOLIDAYS": "2026-13-01"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config, err := LoadConfig(func(key string) string {
+				return tt.env[key]
+			})
+
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("LoadConfig() want an error")
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("LoadConfig(): %v", err)
+			}
+
+			if config.BusinessDays != tt.wantDays {
+				t.Errorf("BusinessDays = %d, want %d", config.BusinessDays, tt.wantDays)
+			}
+
+			if config.Calendar == nil {
+				t.Error("Calendar = nil")
+			}
+
+			if got, want := fmt.Sprintf("%T", config.Notifier), fmt.Sprintf("%T", tt.wantNotifier); got != want {
+				t.Errorf("Notifier = %s, want %s", got, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,258 @@
+package sla
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"net/smtp"
+	"strings"
+	"sync"
+	"time"
+)
+
+type Level string
+
+const (
+	// BreachLevel alerts the teachers of the section
+	BreachLevel Level = "breach"
+	// EscalationLevel alerts the managers of the account
+	EscalationLevel Level = "escalation"
+)
+
+// Alert is a submission waiting for grading longer than the policy allows.
+type Alert struct {
+	SubmissionID    int    `json:"submission_id"`
+	Attempt         int    `json:"attempt"`
+	AccountID       int    `json:"account_id"`
+	AccountName     string `json:"account_name"`
+	CourseID        int    `json:"course_id"`
+	CourseName      string `json:"course_name"`
+	Section         string `json:"section"`
+	AssignmentID    int    `json:"assignment_id"`
+	AssignmentTitle string `json:"assignment_title"`
+	UserID          int    `json:"user_id"`
+	SubmittedAt     string `json:"submitted_at"`
+	BusinessDays    int    `json:"business_days"`
+	SpeedGraderUrl  string `json:"speedgrader_url"`
+}
+
+// Notification groups the alerts of a level sent to the same recipients.
+type Notification struct {
+	Level      Level    `json:"level"`
+	Recipients []string `json:"recipients"`
+	Alerts     []Alert  `json:"alerts"`
+}
+
+type Notifier interface {
+	Notify(ctx context.Context, n Notification) error
+}
+
+func (n Notification) subject() string {
+	if n.Level == EscalationLevel {
+		return fmt.Sprintf("Escalation: %d submissions overdue for marking", len(n.Alerts))
+	}
+
+	return fmt.Sprintf("%d submissions overdue for marking", len(n.Alerts))
+}
+
+func (n Notification) body() string {
+	var b strings.Builder
+
+	b.WriteString("The following submissions have not been marked within the marking timeframe.\n\n")
+
+	for _, a := range n.Alerts {
+		fmt.Fprintf(&b, "%s, %s (%s)\n", a.CourseName, a.AssignmentTitle, a.Section)
+		fmt.Fprintf(&b, "  submitted %s, %d business days ago\n", a.SubmittedAt, a.BusinessDays)
+		fmt.Fprintf(&b, "  %s\n\n", a.SpeedGraderUrl)
+	}
+
+	return b.String()
+}
+
+// EmailNotifier sends a plain text email to the recipients.
+type EmailNotifier struct {
+	host string
+	addr string
+	auth smtp.Auth
+	from string
+}
+
+func NewEmailNotifier(host string, port int, username, password, from string) *EmailNotifier {
+	var auth smtp.Auth
+
+	if username != "" {
+		auth = smtp.PlainAuth("", username, password, host)
+	}
+
+	return &EmailNotifier{
+		host: host,
+		addr: fmt.Sprintf("%s:%d", host, port),
+		auth: auth,
+		from: from,
+	}
+}
+
+func (e *EmailNotifier) Notify(ctx context.Context, n Notification) error {
+	if len(n.Recipients) == 0 {
+		return nil
+	}
+
+	var msg bytes.Buffer
+
+	fmt.Fprintf(&msg, "From: %s\r\n", e.from)
+	fmt.Fprintf(&msg, "To: %s\r\n", strings.Join(n.Recipients, ", "))
+	fmt.Fprintf(&msg, "Subject: %s\r\n", n.subject())
+	msg.WriteString("MIME-Version: 1.0\r\n")
+	msg.WriteString("Content-Type: text/plain; charset=UTF-8\r\n\r\n")
+	msg.WriteString(strings.ReplaceAll(n.body(), "\n", "\r\n"))
+
+	err := e.send(ctx, n.Recipients, msg.Bytes())
+	if err != nil && ctx.Err() != nil {
+		return ctx.Err()
+	}
+
+	return err
+}
+
+// send is smtp.SendMail, but dialling with ctx and giving up when it is done.
+func (e *EmailNotifier) send(ctx context.Context, to []string, msg []byte) error {
+	var dialer net.Dialer
+
+	conn, err := dialer.DialContext(ctx, "tcp", e.addr)
+	if err != nil {
+		return err
+	}
+
+	// unblocks the exchange below
+	stop := context.AfterFunc(ctx, func() {
+		conn.Close()
+	})
+	defer stop()
+
+	client, err := smtp.NewClient(conn, e.host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer client.Close()
+
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		if err := client.StartTLS(&tls.Config{ServerName: e.host}); err != nil {
+			return err
+		}
+	}
+
+	if e.auth != nil {
+		if ok, _ := client.Extension("AUTH"); !ok {
+			return fmt.Errorf("smtp: server doesn't support AUTH")
+		}
+
+		if err := client.Auth(e.auth); err != nil {
+			return err
+		}
+	}
+
+	if err := client.Mail(e.from); err != nil {
+		return err
+	}
+
+	for _, addr := range to {
+		if err := client.Rcpt(addr); err != nil {
+			return err
+		}
+	}
+
+	w, err := client.Data()
+	if err != nil {
+		return err
+	}
+
+	if _, err := w.Write(msg); err != nil {
+		return err
+	}
+
+	if err := w.Close(); err != nil {
+		return err
+	}
+
+	return client.Quit()
+}
+
+// WebhookNotifier posts the notification as JSON, e.g. to a chat or ticketing
+// integration.
+type WebhookNotifier struct {
+	url        string
+	httpClient *http.Client
+}
+
+func NewWebhookNotifier(url string) *WebhookNotifier {
+	return &WebhookNotifier{
+		url: url,
+		httpClient: &http.Client{
+			Timeout: 10 * time.Second,
+		},
+	}
+}
+
+func (h *WebhookNotifier) Notify(ctx context.Context, n Notification) error {
+	data, err := json.Marshal(n)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, h.url, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	res, err := h.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		return fmt.Errorf("unsuccessful webhook request: %s", res.Status)
+	}
+
+	return nil
+}
+
+// SinkNotifier logs notifications and keeps them in memory instead of sending
+// them, for local development and tests.
+type SinkNotifier struct {
+	mu   sync.Mutex
+	sent []Notification
+}
+
+func NewSinkNotifier() *SinkNotifier {
+	return &SinkNotifier{
+		sent: make([]Notification, 0),
+	}
+}
+
+func (s *SinkNotifier) Notify(ctx context.Context, n Notification) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	log.Printf("sla %s notification to %s: %s", n.Level, strings.Join(n.Recipients, ", "), n.subject())
+
+	s.sent = append(s.sent, n)
+
+	return nil
+}
+
+// Sent returns the notifications received so far.
+func (s *SinkNotifier) Sent() []Notification {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]Notification(nil), s.sent...)
+}
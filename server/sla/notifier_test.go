@@ -0,0 +1,299 @@
+package sla
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testNotification(level Level) Notification {
+	return Notification{
+		Level:      level,
+		Recipients: []string{"teacher@example.edu", "coordinator@example.edu"},
+		Alerts: []Alert{{
+			SubmissionID:    101,
+			Attempt:         1,
+			AccountID:       1,
+			CourseID:        12,
+			CourseName:      "Certificate III in Fitness",
+			Section:         "FIT30121-2026-T3",
+			AssignmentID:    34,
+			AssignmentTitle: "Assessment 2",
+			UserID:          56,
+			SubmittedAt:     "2026-10-01T09:00:00Z",
+			BusinessDays:    12,
+			SpeedGraderUrl:  "https://canvas.example.edu/courses/12/gradebook/speed_grader?assignment_id=34&student_id=56",
+		}},
+	}
+}
+
+func TestNotificationSubject(t *testing.T) {
+	if got := testNotification(BreachLevel).subject(); got != "1 submissions overdue for marking" {
+		t.Errorf("subject() = %q", got)
+	}
+
+	if got := testNotification(EscalationLevel).subject(); !strings.HasPrefix(got, "Escalation: ") {
+		t.Errorf("subject() = %q, want an escalation", got)
+	}
+}
+
+func TestSinkNotifier(t *testing.T) {
+	sink := NewSinkNotifier()
+
+	for _, level := range []Level{BreachLevel, EscalationLevel} {
+		if err := sink.Notify(context.Background(), testNotification(level)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	sent := sink.Sent()
+	if len(sent) != 2 || sent[0].Level != BreachLevel || sent[1].Level != EscalationLevel {
+		t.Fatalf("Sent() = %+v", sent)
+	}
+
+	// callers cannot change the recorded notifications
+	sent[0].Level = EscalationLevel
+
+	if sink.Sent()[0].Level != BreachLevel {
+		t.Error("Sent() shares its slice")
+	}
+}
+
+func TestWebhookNotifier(t *testing.T) {
+	received := make(chan Notification, 1)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.Header.Get("Content-Type") != "application/json" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		var n Notification
+		if err := json.NewDecoder(r.Body).Decode(&n); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		received <- n
+	}))
+	defer server.Close()
+
+	want := testNotification(EscalationLevel)
+
+	if err := NewWebhookNotifier(server.URL).Notify(context.Background(), want); err != nil {
+		t.Fatal(err)
+	}
+
+	got := <-received
+	if got.Level != want.Level || len(got.Alerts) != 1 || got.Alerts[0] != want.Alerts[0] {
+		t.Errorf("received %+v, want %+v", got, want)
+	}
+}
+
+func TestWebhookNotifierError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	if err := NewWebhookNotifier(server.URL).Notify(context.Background(), testNotification(BreachLevel)); err == nil {
+		t.Fatal("Notify() want an error")
+	}
+}
+
+// smtpServer accepts one message without TLS or auth, enough for
+// net/smtp, and sends what it received on messages.
+type smtpServer struct {
+	listener net.Listener
+	messages chan smtpMessage
+}
+
+type smtpMessage struct {
+	from string
+	to   []string
+	data string
+}
+
+func newSMTPServer(t *testing.T) *smtpServer {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := &smtpServer{listener: listener, messages: make(chan smtpMessage, 1)}
+
+	t.Cleanup(func() {
+		listener.Close()
+	})
+
+	go s.serve()
+
+	return s
+}
+
+func (s *smtpServer) serve() {
+	conn, err := s.listener.Accept()
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	r := bufio.NewReader(conn)
+	reply := func(line string) {
+		fmt.Fprintf(conn, "%s\r\n", line)
+	}
+
+	var msg smtpMessage
+
+	reply("220 localhost ESMTP")
+
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+
+		line = strings.TrimRight(line, "\r\n")
+		command := strings.ToUpper(strings.SplitN(line, " ", 2)[0])
+
+		switch command {
+		case "EHLO", "HELO":
+			reply("250 localhost")
+		case "MAIL":
+			msg.from = strings.Trim(strings.TrimPrefix(line, "MAIL FROM:"), "<>")
+			reply("250 OK")
+		case "RCPT":
+			msg.to = append(msg.to, strings.Trim(strings.TrimPrefix(line, "RCPT TO:"), "<>"))
+			reply("250 OK")
+		case "DATA":
+			reply("354 End data with <CR><LF>.<CR><LF>")
+
+			var data strings.Builder
+
+			for {
+				line, err := r.ReadString('\n')
+				if err != nil {
+					return
+				}
+
+				if line == ".\r\n" {
+					break
+				}
+
+				data.WriteString(line)
+			}
+
+			msg.data = data.String()
+			reply("250 OK")
+		case "QUIT":
+			reply("221 Bye")
+			s.messages <- msg
+			return
+		default:
+			reply("502 Command not implemented")
+		}
+	}
+}
+
+func (s *smtpServer) port() int {
+	return s.listener.Addr().(*net.TCPAddr).Port
+}
+
+func TestEmailNotifier(t *testing.T) {
+	server := newSMTPServer(t)
+
+	notifier := NewEmailNotifier("127.0.0.1", server.port(), "", "", "marking@example.edu")
+
+	n := testNotification(EscalationLevel)
+
+	if err := notifier.Notify(context.Background(), n); err != nil {
+		t.Fatalf("Notify(): %v", err)
+	}
+
+	msg := <-server.messages
+
+	if msg.from != "marking@example.edu" {
+		t.Errorf("from = %q", msg.from)
+	}
+
+	if strings.Join(msg.to, ",") != strings.Join(n.Recipients, ",") {
+		t.Errorf("to = %v, want %v", msg.to, n.Recipients)
+	}
+
+	for _, want := range []string{
+		"Subject: " + n.subject(),
+		"To: teacher@example.edu, coordinator@example.edu",
+		n.Alerts[0].SpeedGraderUrl,
+	} {
+		if !strings.Contains(msg.data, want) {
+			t.Errorf("message does not contain %q:\n%s", want, msg.data)
+		}
+	}
+}
+
+func TestEmailNotifierWithoutRecipients(t *testing.T) {
+	// nothing listens on the port, Notify must not dial
+	notifier := NewEmailNotifier("127.0.0.1", 1, "", "", "marking@example.edu")
+
+	n := testNotification(BreachLevel)
+	n.Recipients = nil
+
+	if err := notifier.Notify(context.Background(), n); err != nil {
+		t.Fatalf("Notify(): %v", err)
+	}
+}
+
+func TestEmailNotifierContext(t *testing.T) {
+	// accepts connections but never greets
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+
+	go func() {
+		conns := make([]net.Conn, 0)
+
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				for _, conn := range conns {
+					conn.Close()
+				}
+
+				return
+			}
+
+			conns = append(conns, conn)
+		}
+	}()
+
+	notifier := NewEmailNotifier("127.0.0.1", listener.Addr().(*net.TCPAddr).Port, "", "", "marking@example.edu")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	done := make(chan error, 1)
+
+	go func() {
+		done <- notifier.Notify(ctx, testNotification(BreachLevel))
+	}()
+
+	select {
+	case err := <-done:
+		if err != context.DeadlineExceeded {
+			t.Errorf("Notify() = %v, want %v", err, context.DeadlineExceeded)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Notify() ignored the context")
+	}
+}
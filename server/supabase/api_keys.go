@@ -0,0 +1,111 @@
+package supabase
+
+import (
+	"time"
+
+	"github.com/guregu/null/v5"
+)
+
+const apiKeysTable = "api_keys"
+
+type APIKey struct {
+	ID         string    `json:"id"`
+	Name       string    `json:"name"`
+	Prefix     string    `json:"prefix"`
+	KeyHash    string    `json:"key_hash"`
+	Scopes     []string  `json:"scopes"`
+	AccountIDs []int     `json:"account_ids"`
+	CreatedBy  string    `json:"created_by"`
+	CreatedAt  time.Time `json:"created_at"`
+	ExpiresAt  null.Time `json:"expires_at"`
+	RevokedAt  null.Time `json:"revoked_at"`
+	LastUsedAt null.Time `json:"last_used_at"`
+}
+
+type NewAPIKey struct {
+	Name       string    `json:"name"`
+	Prefix     string    `json:"prefix"`
+	KeyHash    string    `json:"key_hash"`
+	Scopes     []string  `json:"scopes"`
+	AccountIDs []int     `json:"account_ids"`
+	CreatedBy  string    `json:"created_by"`
+	ExpiresAt  null.Time `json:"expires_at"`
+}
+
+func (c *SupabaseClient) GetAPIKeyByHash(keyHash string) (apiKey APIKey, err error) {
+	results := []APIKey{}
+
+	_, err = c.serviceClient.From(apiKeysTable).
+		Select("*", "", false).
+		Eq("key_hash", keyHash).
+		Limit(1, "").
+		ExecuteTo(&results)
+	if err != nil {
+		return apiKey, err
+	}
+
+	if len(results) == 0 {
+		return apiKey, ErrNotFound
+	}
+
+	return results[0], nil
+}
+
+func (c *SupabaseClient) GetAPIKeys() (results []APIKey, err error) {
+	results = []APIKey{}
+
+	_, err = c.serviceClient.From(apiKeysTable).
+		Select("*", "", false).
+		Order("created_at", nil).
+		ExecuteTo(&results)
+	if err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
+
+func (c *SupabaseClient) CreateAPIKey(newAPIKey NewAPIKey) (apiKey APIKey, err error) {
+	results := []APIKey{}
+
+	_, err = c.serviceClient.From(apiKeysTable).
+		Insert(newAPIKey, false, "", "representation", "").
+		ExecuteTo(&results)
+	if err != nil {
+		return apiKey, err
+	}
+
+	if len(results) == 0 {
+		return apiKey, ErrNotFound
+	}
+
+	return results[0], nil
+}
+
+func (c *SupabaseClient) RevokeAPIKey(id string, revokedAt time.Time) (apiKey APIKey, err error) {
+	results := []APIKey{}
+
+	_, err = c.serviceClient.From(apiKeysTable).
+		Update(map[string]any{"revoked_at": revokedAt}, "representation", "").
+		Eq("id", id).
+		Is("revoked_at", "null").
+		ExecuteTo(&results)
+	if err != nil {
+		return apiKey, err
+	}
+
+	if len(results) == 0 {
+		return apiKey, ErrNotFound
+	}
+
+	return results[0], nil
+}
+
+func (c *SupabaseClient) UpdateAPIKeyLastUsedAt(id string, lastUsedAt time.Time) error {
+	_, _, err := c.serviceClient.From(apiKeysTable).
+		Update(map[string]any{"last_used_at": lastUsedAt}, "minimal", "").
+		Eq("id", id).
+		Execute()
+
+	return err
+}
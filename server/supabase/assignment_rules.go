@@ -0,0 +1,119 @@
+package supabase
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/guregu/null/v5"
+	"github.com/supabase-community/postgrest-go"
+)
+
+const assignmentRulesTable = "assignment_rules"
+
+type AssignmentRule struct {
+	ID                int         `json:"id"`
+	Name              string      `json:"name"`
+	Classification    string      `json:"classification"`
+	Exclude           bool        `json:"exclude"`
+	NamePattern       null.String `json:"name_pattern"`
+	AssignmentGroupID null.Int    `json:"assignment_group_id"`
+	GradingType       null.String `json:"grading_type"`
+	AccountID         null.Int    `json:"account_id"`
+	Priority          int         `json:"priority"`
+	CreatedBy         string      `json:"created_by"`
+	CreatedAt         time.Time   `json:"created_at"`
+	UpdatedAt         time.Time   `json:"updated_at"`
+}
+
+type NewAssignmentRule struct {
+	Name              string      `json:"name"`
+	Classification    string      `json:"classification"`
+	Exclude           bool        `json:"exclude"`
+	NamePattern       null.String `json:"name_pattern"`
+	AssignmentGroupID null.Int    `json:"assignment_group_id"`
+	GradingType       null.String `json:"grading_type"`
+	AccountID         null.Int    `json:"account_id"`
+	Priority          int         `json:"priority"`
+	CreatedBy         string      `json:"created_by"`
+}
+
+// GetAssignmentRules returns the rules in the order they apply.
+func (c *SupabaseClient) GetAssignmentRules() (results []AssignmentRule, err error) {
+	results = []AssignmentRule{}
+
+	_, err = c.serviceClient.From(assignmentRulesTable).
+		Select("*", "", false).
+		Order("priority", &postgrest.OrderOpts{Ascending: true}).
+		Order("id", &postgrest.OrderOpts{Ascending: true}).
+		ExecuteTo(&results)
+	if err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
+
+func (c *SupabaseClient) CreateAssignmentRule(newRule NewAssignmentRule) (rule AssignmentRule, err error) {
+	results := []AssignmentRule{}
+
+	_, err = c.serviceClient.From(assignmentRulesTable).
+		Insert(newRule, false, "", "representation", "").
+		ExecuteTo(&results)
+	if err != nil {
+		return rule, err
+	}
+
+	if len(results) == 0 {
+		return rule, ErrNotFound
+	}
+
+	return results[0], nil
+}
+
+// UpdateAssignmentRule replaces the conditions and outcome of a rule, its
+// author is kept.
+func (c *SupabaseClient) UpdateAssignmentRule(id int, newRule NewAssignmentRule, updatedAt time.Time) (rule AssignmentRule, err error) {
+	results := []AssignmentRule{}
+
+	_, err = c.serviceClient.From(assignmentRulesTable).
+		Update(map[string]any{
+			"name":                newRule.Name,
+			"classification":      newRule.Classification,
+			"exclude":             newRule.Exclude,
+			"name_pattern":        newRule.NamePattern,
+			"assignment_group_id": newRule.AssignmentGroupID,
+			"grading_type":        newRule.GradingType,
+			"account_id":          newRule.AccountID,
+			"priority":            newRule.Priority,
+			"updated_at":          updatedAt,
+		}, "representation", "").
+		Eq("id", strconv.Itoa(id)).
+		ExecuteTo(&results)
+	if err != nil {
+		return rule, err
+	}
+
+	if len(results) == 0 {
+		return rule, ErrNotFound
+	}
+
+	return results[0], nil
+}
+
+func (c *SupabaseClient) DeleteAssignmentRule(id int) (rule AssignmentRule, err error) {
+	results := []AssignmentRule{}
+
+	_, err = c.serviceClient.From(assignmentRulesTable).
+		Delete("representation", "").
+		Eq("id", strconv.Itoa(id)).
+		ExecuteTo(&results)
+	if err != nil {
+		return rule, err
+	}
+
+	if len(results) == 0 {
+		return rule, ErrNotFound
+	}
+
+	return results[0], nil
+}
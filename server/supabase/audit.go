@@ -0,0 +1,39 @@
+package supabase
+
+const auditLogsTable = "audit_logs"
+
+type AuditLog struct {
+	Actor        string         `json:"actor"`
+	Action       string         `json:"action"`
+	CourseID     int            `json:"course_id,omitempty"`
+	AssignmentID int            `json:"assignment_id,omitempty"`
+	SectionIDs   []int          `json:"section_ids"`
+	UserIDs      []int          `json:"user_ids"`
+	Details      map[string]any `json:"details"`
+}
+
+func (c *SupabaseClient) CreateAuditLogs(logs []AuditLog) error {
+	if len(logs) == 0 {
+		return nil
+	}
+
+	for i := range logs {
+		if logs[i].SectionIDs == nil {
+			logs[i].SectionIDs = []int{}
+		}
+
+		if logs[i].UserIDs == nil {
+			logs[i].UserIDs = []int{}
+		}
+
+		if logs[i].Details == nil {
+			logs[i].Details = map[string]any{}
+		}
+	}
+
+	_, _, err := c.serviceClient.From(auditLogsTable).
+		Insert(logs, false, "", "minimal", "").
+		Execute()
+
+	return err
+}
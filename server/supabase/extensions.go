@@ -0,0 +1,55 @@
+package supabase
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/guregu/null/v5"
+)
+
+const extensionsTable = "extensions"
+
+type Extension struct {
+	OverrideID   int         `json:"override_id"`
+	CourseID     int         `json:"course_id"`
+	AssignmentID int         `json:"assignment_id"`
+	UserID       int         `json:"user_id"`
+	DueAt        null.String `json:"due_at"`
+	LockAt       null.String `json:"lock_at"`
+	Reason       string      `json:"reason"`
+	ApprovedBy   string      `json:"approved_by"`
+	CreatedBy    string      `json:"created_by"`
+	UpdatedAt    time.Time   `json:"updated_at"`
+}
+
+func (c *SupabaseClient) GetExtensionsByCourseID(courseID int) (results []Extension, err error) {
+	results = []Extension{}
+
+	_, err = c.serviceClient.From(extensionsTable).
+		Select("*", "", false).
+		Eq("course_id", strconv.Itoa(courseID)).
+		ExecuteTo(&results)
+	if err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
+
+// UpsertExtension records an extension, replacing the record of its override.
+func (c *SupabaseClient) UpsertExtension(extension Extension) error {
+	_, _, err := c.serviceClient.From(extensionsTable).
+		Upsert(extension, "override_id", "minimal", "").
+		Execute()
+
+	return err
+}
+
+func (c *SupabaseClient) DeleteExtension(overrideID int) error {
+	_, _, err := c.serviceClient.From(extensionsTable).
+		Delete("minimal", "").
+		Eq("override_id", strconv.Itoa(overrideID)).
+		Execute()
+
+	return err
+}
@@ -0,0 +1,113 @@
+package supabase
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/guregu/null/v5"
+)
+
+const (
+	liveEventsTable          = "live_events"
+	ungradedSubmissionsTable = "ungraded_submissions"
+	endedEnrollmentsTable    = "ended_enrollments"
+	ungradedCountsView       = "ungraded_counts"
+
+	// postgres error code of unique constraint violations
+	uniqueViolation = "23505"
+)
+
+type LiveEvent struct {
+	EventKey  string          `json:"event_key"`
+	EventName string          `json:"event_name"`
+	EventTime time.Time       `json:"event_time"`
+	CourseID  null.Int        `json:"course_id"`
+	UserID    null.Int        `json:"user_id"`
+	Payload   json.RawMessage `json:"payload"`
+}
+
+// UngradedSubmission is a submission known from the events, graded ones are
+// kept so an older event cannot mark them awaiting grading again.
+type UngradedSubmission struct {
+	SubmissionID    int         `json:"submission_id"`
+	CourseID        int         `json:"course_id"`
+	AssignmentID    int         `json:"assignment_id"`
+	UserID          int         `json:"user_id"`
+	SubmittedAt     null.String `json:"submitted_at"`
+	UpdatedAt       time.Time   `json:"updated_at"` // event time of the change
+	AwaitingGrading bool        `json:"awaiting_grading"`
+}
+
+// EndedEnrollment is the end of the enrollment of a student in a course.
+type EndedEnrollment struct {
+	CourseID int       `json:"course_id"`
+	UserID   int       `json:"user_id"`
+	EndedAt  time.Time `json:"ended_at"` // event time of the change
+}
+
+type UngradedCount struct {
+	CourseID          int         `json:"course_id"`
+	AssignmentID      int         `json:"assignment_id"`
+	NeedsGradingCount int         `json:"needs_grading_count"`
+	OldestSubmittedAt null.String `json:"oldest_submitted_at"`
+}
+
+// CreateLiveEvent stores an event, ErrDuplicate when its key was stored
+// before.
+func (c *SupabaseClient) CreateLiveEvent(event LiveEvent) error {
+	_, _, err := c.serviceClient.From(liveEventsTable).
+		Insert(event, false, "", "minimal", "").
+		Execute()
+	if err != nil && strings.HasPrefix(err.Error(), "("+uniqueViolation+")") {
+		return ErrDuplicate
+	}
+
+	return err
+}
+
+func (c *SupabaseClient) DeleteLiveEvent(eventKey string) error {
+	_, _, err := c.serviceClient.From(liveEventsTable).
+		Delete("minimal", "").
+		Eq("event_key", eventKey).
+		Execute()
+
+	return err
+}
+
+// UpsertUngradedSubmission stores the state of a submission. A trigger skips
+// the change when the stored one is newer, or when the enrollment of the
+// student ended after it.
+func (c *SupabaseClient) UpsertUngradedSubmission(submission UngradedSubmission) error {
+	_, _, err := c.serviceClient.From(ungradedSubmissionsTable).
+		Upsert(submission, "submission_id", "minimal", "").
+		Execute()
+
+	return err
+}
+
+// UpsertEndedEnrollment stores the end of an enrollment, a trigger marks the
+// submissions of the student from before it as no longer awaiting grading. An
+// end older than the stored one is skipped.
+func (c *SupabaseClient) UpsertEndedEnrollment(enrollment EndedEnrollment) error {
+	_, _, err := c.serviceClient.From(endedEnrollmentsTable).
+		Upsert(enrollment, "course_id,user_id", "minimal", "").
+		Execute()
+
+	return err
+}
+
+func (c *SupabaseClient) GetUngradedCountsByCourseID(courseID int) (results []UngradedCount, err error) {
+	results = []UngradedCount{}
+
+	_, err = c.serviceClient.From(ungradedCountsView).
+		Select("*", "", false).
+		Eq("course_id", strconv.Itoa(courseID)).
+		ExecuteTo(&results)
+	if err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
@@ -0,0 +1,18 @@
+-- Service API keys for machine to machine access to reports.
+-- Only the sha256 hash of a key is stored, the key is shown once on creation.
+create table if not exists public.api_keys (
+  id uuid primary key default gen_random_uuid(),
+  name text not null,
+  prefix text not null,
+  key_hash text not null unique,
+  scopes text[] not null default '{}',        -- operation ids, see /openapi.json
+  account_ids bigint[] not null default '{}', -- empty allows every account
+  created_by text not null,
+  created_at timestamptz not null default now(),
+  expires_at timestamptz,
+  revoked_at timestamptz,
+  last_used_at timestamptz
+);
+
+-- no policies, only the service role used by the server can access api keys
+alter table public.api_keys enable row level security;
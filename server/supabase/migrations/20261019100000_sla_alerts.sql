@@ -0,0 +1,26 @@
+-- Managers alerted when submissions stay ungraded past the marking SLA
+-- escalation, by Canvas account. Maintained in the Supabase dashboard.
+create table if not exists public.sla_escalation_contacts (
+  account_id bigint not null,
+  email text not null,
+  created_at timestamptz not null default now(),
+  primary key (account_id, email)
+);
+
+-- Alerts already sent, so each submission attempt is alerted once per level.
+create table if not exists public.sla_alerts (
+  submission_id bigint not null,
+  attempt integer not null,
+  level text not null check (level in ('breach', 'escalation')),
+  account_id bigint not null,
+  course_id bigint not null,
+  assignment_id bigint not null,
+  user_id bigint not null,
+  recipients text[] not null default '{}',
+  sent_at timestamptz not null default now(),
+  primary key (submission_id, attempt, level)
+);
+
+-- no policies, only the service role used by the server can access these
+alter table public.sla_escalation_contacts enable row level security;
+alter table public.sla_alerts enable row level security;
@@ -0,0 +1,18 @@
+-- Canvas write actions made through the server, e.g. grades posted to
+-- students. One row per action on a Canvas object.
+create table if not exists public.audit_logs (
+  id bigint generated always as identity primary key,
+  actor text not null,  -- staff email or api key
+  action text not null, -- e.g. grades.post
+  course_id bigint,
+  assignment_id bigint,
+  section_ids bigint[] not null default '{}',
+  user_ids bigint[] not null default '{}',
+  details jsonb not null default '{}',
+  created_at timestamptz not null default now()
+);
+
+create index if not exists audit_logs_course_id_idx on public.audit_logs (course_id, created_at desc);
+
+-- no policies, only the service role used by the server can access audit logs
+alter table public.audit_logs enable row level security;
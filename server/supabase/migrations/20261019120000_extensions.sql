@@ -0,0 +1,20 @@
+-- Student extensions granted through the server. Canvas keeps the dates in
+-- an ADHOC assignment override per student, this table why and by whom.
+create table if not exists public.extensions (
+  override_id bigint primary key,
+  course_id bigint not null,
+  assignment_id bigint not null,
+  user_id bigint not null,
+  due_at timestamptz,
+  lock_at timestamptz,
+  reason text not null,
+  approved_by text not null,
+  created_by text not null,
+  created_at timestamptz not null default now(),
+  updated_at timestamptz not null default now()
+);
+
+create index if not exists extensions_course_id_idx on public.extensions (course_id);
+
+-- no policies, only the service role used by the server can access extensions
+alter table public.extensions enable row level security;
@@ -0,0 +1,24 @@
+-- Rules classifying Canvas assignments in reports, e.g. coversheets left out
+-- of grading reports. Every condition set on a rule must match an
+-- assignment, the first matching rule by priority applies.
+create table if not exists public.assignment_rules (
+  id bigint generated always as identity primary key,
+  name text not null,
+  classification text not null,  -- e.g. coversheet, formative
+  exclude boolean not null default false, -- left out of reports
+  name_pattern text,             -- case insensitive regular expression
+  assignment_group_id bigint,
+  grading_type text,             -- e.g. pass_fail, points
+  account_id bigint,             -- of the course
+  priority integer not null default 100, -- lowest first
+  created_by text not null,
+  created_at timestamptz not null default now(),
+  updated_at timestamptz not null default now()
+);
+
+-- the coversheets used to be hard coded in the reports
+insert into public.assignment_rules (name, classification, exclude, name_pattern, created_by)
+values ('Assessment coversheets', 'coversheet', true, 'Assessment Coversheet', 'migration');
+
+-- no policies, only the service role used by the server can access assignment rules
+alter table public.assignment_rules enable row level security;
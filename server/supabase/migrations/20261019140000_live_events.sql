@@ -0,0 +1,36 @@
+-- Canvas Live Events received by the server, e.g. submission_created. An
+-- event delivered twice has the same event_key and is stored once.
+create table if not exists public.live_events (
+  id bigint generated always as identity primary key,
+  event_key text not null unique, -- sha256 of the name, time and body
+  event_name text not null,
+  event_time timestamptz not null,
+  course_id bigint,
+  user_id bigint,
+  payload jsonb not null,         -- metadata and body as sent by Canvas
+  received_at timestamptz not null default now()
+);
+
+create index if not exists live_events_course_id_idx on public.live_events (course_id, event_time desc);
+
+-- Submitted submissions awaiting grading, kept up to date by the events.
+-- Only submissions since Canvas started sending the events are known.
+create table if not exists public.ungraded_submissions (
+  submission_id bigint primary key,
+  course_id bigint not null,
+  assignment_id bigint not null,
+  user_id bigint not null,
+  submitted_at timestamptz,
+  updated_at timestamptz not null -- event time of the last change
+);
+
+create index if not exists ungraded_submissions_course_id_idx on public.ungraded_submissions (course_id, user_id);
+
+create or replace view public.ungraded_counts with (security_invoker = true) as
+  select course_id, assignment_id, count(*) as needs_grading_count, min(submitted_at) as oldest_submitted_at
+  from public.ungraded_submissions
+  group by course_id, assignment_id;
+
+-- no policies, only the service role used by the server can access live events
+alter table public.live_events enable row level security;
+alter table public.ungraded_submissions enable row level security;
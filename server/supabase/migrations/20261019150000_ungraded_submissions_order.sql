@@ -0,0 +1,98 @@
+-- Live Events can arrive out of order, e.g. a grade_change before the
+-- submission_updated sent just before it. Graded submissions are kept as
+-- tombstones and the triggers below skip a change older than the stored one,
+-- within the insert or update itself so concurrent events cannot interleave.
+alter table public.ungraded_submissions
+  add column if not exists awaiting_grading boolean not null default true;
+
+create index if not exists ungraded_submissions_awaiting_idx on public.ungraded_submissions (course_id, assignment_id) where awaiting_grading;
+
+create or replace view public.ungraded_counts with (security_invoker = true) as
+  select course_id, assignment_id, count(*) as needs_grading_count, min(submitted_at) as oldest_submitted_at
+  from public.ungraded_submissions
+  where awaiting_grading
+  group by course_id, assignment_id;
+
+-- Students whose enrollment in a course ended, their submissions from before
+-- ended_at no longer await grading.
+create table if not exists public.ended_enrollments (
+  course_id bigint not null,
+  user_id bigint not null,
+  ended_at timestamptz not null, -- event time of the enrollment change
+  primary key (course_id, user_id)
+);
+
+-- no policies, only the service role used by the server can access them
+alter table public.ended_enrollments enable row level security;
+
+-- serializes the changes of the submissions of a student in a course with the
+-- end of their enrollment
+create or replace function public.lock_ungraded_enrollment(p_course_id bigint, p_user_id bigint) returns void
+language sql as $$
+  select pg_advisory_xact_lock(hashtextextended(p_course_id || ':' || p_user_id, 0));
+$$;
+
+create or replace function public.skip_stale_ungraded_submission() returns trigger
+language plpgsql as $$
+begin
+  if tg_op = 'UPDATE' and new.updated_at < old.updated_at then
+    return null;
+  end if;
+
+  if new.awaiting_grading then
+    perform public.lock_ungraded_enrollment(new.course_id, new.user_id);
+
+    if exists (
+      select 1 from public.ended_enrollments e
+      where e.course_id = new.course_id and e.user_id = new.user_id and e.ended_at >= new.updated_at
+    ) then
+      return null;
+    end if;
+  end if;
+
+  return new;
+end;
+$$;
+
+drop trigger if exists skip_stale_ungraded_submission on public.ungraded_submissions;
+
+create trigger skip_stale_ungraded_submission
+  before insert or update on public.ungraded_submissions
+  for each row execute function public.skip_stale_ungraded_submission();
+
+create or replace function public.skip_stale_ended_enrollment() returns trigger
+language plpgsql as $$
+begin
+  if tg_op = 'UPDATE' and new.ended_at < old.ended_at then
+    return null;
+  end if;
+
+  perform public.lock_ungraded_enrollment(new.course_id, new.user_id);
+
+  return new;
+end;
+$$;
+
+drop trigger if exists skip_stale_ended_enrollment on public.ended_enrollments;
+
+create trigger skip_stale_ended_enrollment
+  before insert or update on public.ended_enrollments
+  for each row execute function public.skip_stale_ended_enrollment();
+
+-- the submissions of the student from before the end no longer await grading
+create or replace function public.end_ungraded_submissions() returns trigger
+language plpgsql as $$
+begin
+  update public.ungraded_submissions
+  set awaiting_grading = false, updated_at = new.ended_at
+  where course_id = new.course_id and user_id = new.user_id and awaiting_grading and updated_at <= new.ended_at;
+
+  return null;
+end;
+$$;
+
+drop trigger if exists end_ungraded_submissions on public.ended_enrollments;
+
+create trigger end_ungraded_submissions
+  after insert or update on public.ended_enrollments
+  for each row execute function public.end_ungraded_submissions();
@@ -0,0 +1,96 @@
+package supabase
+
+import (
+	"strconv"
+)
+
+const (
+	slaEscalationContactsTable = "sla_escalation_contacts"
+	slaAlertsTable             = "sla_alerts"
+
+	slaAlertsBatchSize = 200
+)
+
+type SLAEscalationContact struct {
+	AccountID int    `json:"account_id"`
+	Email     string `json:"email"`
+}
+
+type SLAAlert struct {
+	SubmissionID int      `json:"submission_id"`
+	Attempt      int      `json:"attempt"`
+	Level        string   `json:"level"`
+	AccountID    int      `json:"account_id"`
+	CourseID     int      `json:"course_id"`
+	AssignmentID int      `json:"assignment_id"`
+	UserID       int      `json:"user_id"`
+	Recipients   []string `json:"recipients"`
+}
+
+func (c *SupabaseClient) GetSLAEscalationContacts(accountIDs []int) (results []SLAEscalationContact, err error) {
+	results = []SLAEscalationContact{}
+
+	if len(accountIDs) == 0 {
+		return results, nil
+	}
+
+	_, err = c.serviceClient.From(slaEscalationContactsTable).
+		Select("account_id,email", "", false).
+		In("account_id", itoa(accountIDs)).
+		ExecuteTo(&results)
+	if err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
+
+// GetSLAAlertsBySubmissionIDs returns the alerts already sent for the submissions.
+func (c *SupabaseClient) GetSLAAlertsBySubmissionIDs(submissionIDs []int) (results []SLAAlert, err error) {
+	results = []SLAAlert{}
+
+	if len(submissionIDs) == 0 {
+		return results, nil
+	}
+
+	// keep the in filter within url length limits
+	for start := 0; start < len(submissionIDs); start += slaAlertsBatchSize {
+		end := min(start+slaAlertsBatchSize, len(submissionIDs))
+
+		alerts := []SLAAlert{}
+
+		_, err = c.serviceClient.From(slaAlertsTable).
+			Select("*", "", false).
+			In("submission_id", itoa(submissionIDs[start:end])).
+			ExecuteTo(&alerts)
+		if err != nil {
+			return nil, err
+		}
+
+		results = append(results, alerts...)
+	}
+
+	return results, nil
+}
+
+func (c *SupabaseClient) CreateSLAAlerts(alerts []SLAAlert) error {
+	if len(alerts) == 0 {
+		return nil
+	}
+
+	_, _, err := c.serviceClient.From(slaAlertsTable).
+		Upsert(alerts, "submission_id,attempt,level", "minimal", "").
+		Execute()
+
+	return err
+}
+
+func itoa(values []int) []string {
+	results := make([]string, 0, len(values))
+
+	for _, v := range values {
+		results = append(results, strconv.Itoa(v))
+	}
+
+	return results
+}
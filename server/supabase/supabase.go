@@ -1,10 +1,25 @@
 package supabase
 
-import "github.com/supabase-community/postgrest-go"
+import (
+	"errors"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/supabase-community/postgrest-go"
+)
+
+var (
+	ErrNotFound  = errors.New("not found")
+	ErrDuplicate = errors.New("duplicate")
+)
 
 type SupabaseClient struct {
 	client *postgrest.Client
 	secret string
+
+	// serviceClient reads and writes the tables owned by this server in the
+	// public schema. It authenticates with the service role, bypassing RLS.
+	serviceClient *postgrest.Client
 }
 
 func NewSupabaseClient(baseUrl, publicAnonKey string, secret string) (*SupabaseClient, error) {
@@ -17,10 +32,40 @@ func NewSupabaseClient(baseUrl, publicAnonKey string, secret string) (*SupabaseC
 		return nil, client.ClientError
 	}
 
+	serviceToken, err := newServiceRoleToken(secret)
+	if err != nil {
+		return nil, err
+	}
+
+	serviceClient := postgrest.NewClient(baseUrl, "public", map[string]string{
+		"apiKey": publicAnonKey,
+	})
+	if serviceClient.ClientError != nil {
+		return nil, serviceClient.ClientError
+	}
+
+	serviceClient.SetAuthToken(serviceToken)
+
 	supabase := &SupabaseClient{
-		client: client,
-		secret: secret,
+		client:        client,
+		secret:        secret,
+		serviceClient: serviceClient,
 	}
 
 	return supabase, nil
 }
+
+// newServiceRoleToken signs a service_role token with the project jwt secret,
+// the same claims as the service_role key shown in the Supabase dashboard.
+func newServiceRoleToken(secret string) (string, error) {
+	now := time.Now()
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"iss":  "supabase",
+		"role": "service_role",
+		"iat":  now.Unix(),
+		"exp":  now.AddDate(10, 0, 0).Unix(),
+	})
+
+	return token.SignedString([]byte(secret))
+}
@@ -99,13 +99,35 @@ resource "aws_lambda_function" "function" {
   runtime = "provided.al2023"
   environment {
     variables = {
-      "CANVAS_BASE_URL"          = var.canvas_base_url,
-      "CANVAS_PAGE_SIZE"         = var.canvas_page_size,
-      "CANVAS_ACCESS_TOKEN"      = var.canvas_access_token,
-      "WEB_URL"                  = var.web_url,
-      "SUPABASE_BASE_URL"        = var.supabase_base_url,
-      "SUPABASE_PUBLIC_ANON_KEY" = var.supabase_public_anon_key,
-      "SUPABASE_JWT_SECRET"      = var.supabase_jwt_secret
+      "CANVAS_BASE_URL"              = var.canvas_base_url,
+      "CANVAS_PAGE_SIZE"             = var.canvas_page_size,
+      "CANVAS_ACCESS_TOKEN"          = var.canvas_access_token,
+      "CANVAS_MASQUERADE"            = var.canvas_masquerade,
+      "WEB_URL"                      = var.web_url,
+      "SUPABASE_BASE_URL"            = var.supabase_base_url,
+      "SUPABASE_PUBLIC_ANON_KEY"     = var.supabase_public_anon_key,
+      "SUPABASE_JWT_SECRET"          = var.supabase_jwt_secret,
+      "SLA_BUSINESS_DAYS"            = var.sla_business_days,
+      "SLA_ESCALATION_DAYS"          = var.sla_escalation_days,
+      "SLA_TIMEZONE"                 = var.sla_timezone,
+      "SLA_HOLIDAYS"                 = var.sla_holidays,
+      "SLA_NOTIFIER"                 = var.sla_notifier,
+      "SLA_WEBHOOK_URL"              = var.sla_webhook_url,
+      "SMTP_HOST"                    = var.smtp_host,
+      "SMTP_PORT"                    = var.smtp_port,
+      "SMTP_USERNAME"                = var.smtp_username,
+      "SMTP_PASSWORD"                = var.smtp_password,
+      "SMTP_FROM"                    = var.smtp_from,
+      "TRANSCRIPT_INSTITUTE_NAME"    = var.transcript_institute_name,
+      "TRANSCRIPT_INSTITUTE_DETAILS" = var.transcript_institute_details,
+      "TRANSCRIPT_LOGO_URL"          = var.transcript_logo_url,
+      "TRANSCRIPT_COLOR"             = var.transcript_color,
+      "TRANSCRIPT_TIMEZONE"          = var.transcript_timezone,
+      "TRANSCRIPT_TITLE"             = var.transcript_title,
+      "TRANSCRIPT_INTRO"             = var.transcript_intro,
+      "TRANSCRIPT_FOOTER"            = var.transcript_footer,
+      "LIVE_EVENTS_SECRET"           = var.live_events_secret,
+      "LIVE_EVENTS_JWKS_URL"         = var.live_events_jwks_url
     }
   }
 }
@@ -141,6 +163,12 @@ resource "aws_api_gateway_rest_api" "gw" {
   name        = "${local.service_name}-gw"
   description = "API Gateway for Lambda function."
 
+  # lambda returns downloads such as xlsx and pdf base64 encoded
+  binary_media_types = [
+    "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+    "application/pdf",
+  ]
+
   endpoint_configuration {
     types = ["REGIONAL"]
   }
@@ -215,6 +243,30 @@ resource "aws_api_gateway_integration" "delete_integration" {
   }
 }
 
+resource "aws_api_gateway_method" "post" {
+  rest_api_id   = aws_api_gateway_rest_api.gw.id
+  resource_id   = aws_api_gateway_resource.root.id
+  http_method   = "POST"
+  authorization = "NONE"
+  request_parameters = {
+    "method.request.path.proxy" = true
+  }
+}
+
+resource "aws_api_gateway_integration" "post_integration" {
+  rest_api_id             = aws_api_gateway_rest_api.gw.id
+  resource_id             = aws_api_gateway_resource.root.id
+  http_method             = aws_api_gateway_method.post.http_method
+  integration_http_method = "POST"
+  type                    = "AWS_PROXY"
+  uri                     = aws_lambda_function.function.invoke_arn
+
+  timeout_milliseconds = 29000
+  request_parameters = {
+    "integration.request.path.proxy" = "method.request.path.proxy"
+  }
+}
+
 resource "aws_api_gateway_method" "options" {
   rest_api_id   = aws_api_gateway_rest_api.gw.id
   resource_id   = aws_api_gateway_resource.root.id
@@ -269,6 +321,7 @@ resource "aws_api_gateway_deployment" "deployment" {
   depends_on = [
     aws_api_gateway_integration.get_integration,
     aws_api_gateway_integration.delete_integration,
+    aws_api_gateway_integration.post_integration,
     aws_api_gateway_integration.options_integration,
   ]
 
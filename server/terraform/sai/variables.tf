@@ -25,6 +25,12 @@ variable "canvas_access_token" {
   type        = string
 }
 
+variable "canvas_masquerade" {
+  description = "Act as the signed in staff member for Canvas write actions, \"true\" or \"false\"."
+  type        = string
+  default     = "false"
+}
+
 variable "web_url" {
   description = "Url of web client."
   type        = string
@@ -43,4 +49,130 @@ variable "supabase_public_anon_key" {
 variable "supabase_jwt_secret" {
   description = "Supabase jwt secret used for access token validation."
   type        = string
-}
\ No newline at end of file
+}
+
+variable "sla_business_days" {
+  description = "Business days a submission must be graded within."
+  type        = string
+  default     = "10"
+}
+
+variable "sla_escalation_days" {
+  description = "Further business days before SLA breaches are escalated to account managers."
+  type        = string
+  default     = "5"
+}
+
+variable "sla_timezone" {
+  description = "Time zone of the SLA business days, e.g. Australia/Sydney."
+  type        = string
+  default     = "UTC"
+}
+
+variable "sla_holidays" {
+  description = "Comma separated YYYY-MM-DD dates excluded from SLA business days."
+  type        = string
+  default     = ""
+}
+
+variable "sla_notifier" {
+  description = "SLA alerts notifier, \"sink\" (log only), \"email\" or \"webhook\"."
+  type        = string
+  default     = "sink"
+}
+
+variable "sla_webhook_url" {
+  description = "Url SLA alerts are posted to by the webhook notifier."
+  type        = string
+  default     = ""
+}
+
+variable "smtp_host" {
+  description = "SMTP server of the email notifier."
+  type        = string
+  default     = ""
+}
+
+variable "smtp_port" {
+  description = "SMTP server port of the email notifier."
+  type        = string
+  default     = "587"
+}
+
+variable "smtp_username" {
+  description = "SMTP username of the email notifier."
+  type        = string
+  default     = ""
+}
+
+variable "smtp_password" {
+  description = "SMTP password of the email notifier."
+  type        = string
+  default     = ""
+}
+
+variable "smtp_from" {
+  description = "Sender address of the email notifier."
+  type        = string
+  default     = ""
+}
+
+variable "transcript_institute_name" {
+  description = "Institute name printed on statements of results."
+  type        = string
+  default     = ""
+}
+
+variable "transcript_institute_details" {
+  description = "Lines under the institute name on statements of results, separated by |."
+  type        = string
+  default     = ""
+}
+
+variable "transcript_logo_url" {
+  description = "Url of the png or jpeg logo of statements of results."
+  type        = string
+  default     = ""
+}
+
+variable "transcript_color" {
+  description = "Hex color of the headings of statements of results."
+  type        = string
+  default     = "1F3864"
+}
+
+variable "transcript_timezone" {
+  description = "IANA time zone of the dates of statements of results."
+  type        = string
+  default     = "UTC"
+}
+
+variable "transcript_title" {
+  description = "Title template of statements of results, empty for the default."
+  type        = string
+  default     = ""
+}
+
+variable "transcript_intro" {
+  description = "Intro template of statements of results, empty for the default."
+  type        = string
+  default     = ""
+}
+
+variable "transcript_footer" {
+  description = "Footer template of statements of results, empty for the default."
+  type        = string
+  default     = ""
+}
+
+variable "live_events_secret" {
+  description = "HMAC secret of Canvas Live Events signed with HS256, empty to only accept the jwks keys."
+  type        = string
+  default     = ""
+}
+
+variable "live_events_jwks_url" {
+  description = "JSON Web Key Set url of the keys signing Canvas Live Events, empty to only accept the secret."
+  type        = string
+  default     = ""
+}
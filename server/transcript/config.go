@@ -0,0 +1,108 @@
+package transcript
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"text/template"
+	"time"
+
+	// the lambda runtime has no zoneinfo
+	_ "time/tzdata"
+)
+
+const (
+	defaultTitle  = "Statement of Results"
+	defaultIntro  = "This is to certify that {{.StudentName}}{{if .StudentID}} ({{.StudentID}}){{end}} has achieved the following results."
+	defaultFooter = "Issued on {{.IssuedOn}}{{if .InstituteName}} by {{.InstituteName}}{{end}}."
+	defaultColor  = "1F3864"
+)
+
+var colorRegexp = regexp.MustCompile(`^[0-9A-Fa-f]{6}$`)
+
+// Config is the branding of the statements and the templates of their text.
+type Config struct {
+	InstituteName string
+	// lines under the institute name, e.g. its address and provider number
+	InstituteDetails []string
+	// png or jpeg printed at the top left, fetched once
+	LogoUrl string
+	// hex rgb of the headings, e.g. 1F3864
+	Color    string
+	Location *time.Location
+
+	Title  *template.Template
+	Intro  *template.Template
+	Footer *template.Template
+}
+
+// TemplateData is available to the title, intro and footer templates.
+type TemplateData struct {
+	InstituteName string
+	StudentName   string
+	StudentID     string
+	IssuedOn      string
+}
+
+// LoadConfig reads the optional TRANSCRIPT_* variables with getenv, e.g.
+// os.Getenv. TRANSCRIPT_TITLE, TRANSCRIPT_INTRO and TRANSCRIPT_FOOTER are
+// text/template templates of TemplateData.
+func LoadConfig(getenv func(string) string) (Config, error) {
+	config := Config{
+		InstituteName: getenv("TRANSCRIPT_INSTITUTE_NAME"),
+		LogoUrl:       getenv("TRANSCRIPT_LOGO_URL"),
+		Color:         defaultColor,
+		Location:      time.UTC,
+	}
+
+	// lines separated by |
+	for _, line := range strings.Split(getenv("TRANSCRIPT_INSTITUTE_DETAILS"), "|") {
+		if line = strings.TrimSpace(line); line != "" {
+			config.InstituteDetails = append(config.InstituteDetails, line)
+		}
+	}
+
+	if v := getenv("TRANSCRIPT_COLOR"); v != "" {
+		v = strings.TrimPrefix(v, "#")
+		if !colorRegexp.MatchString(v) {
+			return config, fmt.Errorf("invalid env: TRANSCRIPT_COLOR, expected a hex color like 1F3864")
+		}
+
+		config.Color = v
+	}
+
+	if v := getenv("TRANSCRIPT_TIMEZONE"); v != "" {
+		location, err := time.LoadLocation(v)
+		if err != nil {
+			return config, fmt.Errorf("invalid env: TRANSCRIPT_TIMEZONE")
+		}
+
+		config.Location = location
+	}
+
+	templates := []struct {
+		env      string
+		fallback string
+		t        **template.Template
+	}{
+		{"TRANSCRIPT_TITLE", defaultTitle, &config.Title},
+		{"TRANSCRIPT_INTRO", defaultIntro, &config.Intro},
+		{"TRANSCRIPT_FOOTER", defaultFooter, &config.Footer},
+	}
+
+	for _, tmpl := range templates {
+		text := getenv(tmpl.env)
+		if text == "" {
+			text = tmpl.fallback
+		}
+
+		t, err := template.New(tmpl.env).Option("missingkey=error").Parse(text)
+		if err != nil {
+			return config, fmt.Errorf("invalid env: %s: %w", tmpl.env, err)
+		}
+
+		*tmpl.t = t
+	}
+
+	return config, nil
+}
@@ -0,0 +1,298 @@
+package transcript
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+
+	"github.com/go-pdf/fpdf"
+)
+
+const dateLayout = "2 January 2006"
+
+// Statement is the results of a student, printed grouped by qualification in
+// the order given.
+type Statement struct {
+	StudentName string
+	StudentID   string
+	IssuedAt    time.Time
+	Results     []Result
+}
+
+type Result struct {
+	Qualification string
+	CourseName    string
+	Grade         string
+	CompletedAt   time.Time // zero when unknown
+}
+
+// Generator renders statements as PDF with the branding of a Config.
+type Generator struct {
+	config     Config
+	httpClient *http.Client
+
+	mu       sync.Mutex
+	logo     []byte
+	logoType string
+}
+
+func NewGenerator(config Config) *Generator {
+	return &Generator{
+		config: config,
+		httpClient: &http.Client{
+			Timeout: 10 * time.Second,
+		},
+	}
+}
+
+// Render writes the statement as an A4 PDF. A logo that cannot be fetched is
+// left out, and fetched again for the next statement.
+func (g *Generator) Render(ctx context.Context, w io.Writer, s Statement) error {
+	data := TemplateData{
+		InstituteName: g.config.InstituteName,
+		StudentName:   s.StudentName,
+		StudentID:     s.StudentID,
+		IssuedOn:      s.IssuedAt.In(g.config.Location).Format(dateLayout),
+	}
+
+	title, err := execute(g.config.Title, data)
+	if err != nil {
+		return err
+	}
+
+	intro, err := execute(g.config.Intro, data)
+	if err != nil {
+		return err
+	}
+
+	footer, err := execute(g.config.Footer, data)
+	if err != nil {
+		return err
+	}
+
+	pdf := fpdf.New("P", "mm", "A4", "")
+	pdf.SetTitle(title, true)
+	pdf.SetAuthor(g.config.InstituteName, true)
+	pdf.SetMargins(20, 20, 20)
+	pdf.SetAutoPageBreak(true, 25)
+	pdf.AliasNbPages("")
+
+	// the core fonts are cp1252
+	tr := pdf.UnicodeTranslatorFromDescriptor("")
+
+	red, green, blue := g.rgb()
+
+	pdf.SetFooterFunc(func() {
+		pdf.SetY(-18)
+		pdf.SetFont("Helvetica", "", 8)
+		pdf.SetTextColor(100, 100, 100)
+		pdf.CellFormat(0, 4, tr(footer), "", 1, "L", false, 0, "")
+		pdf.CellFormat(0, 4, fmt.Sprintf("Page %d of {nb}", pdf.PageNo()), "", 0, "R", false, 0, "")
+	})
+
+	pdf.AddPage()
+
+	// header, logo on the left and the institute on the right
+	top := pdf.GetY()
+
+	if logo, logoType := g.getLogo(ctx); logo != nil {
+		options := fpdf.ImageOptions{ImageType: logoType, ReadDpi: true}
+		pdf.RegisterImageOptionsReader("logo", options, bytes.NewReader(logo))
+
+		if pdf.Err() {
+			log.Printf("error reading transcript logo: %v", pdf.Error())
+			pdf.ClearError()
+		} else {
+			pdf.ImageOptions("logo", 20, top, 0, 20, false, options, 0, "")
+		}
+	}
+
+	pdf.SetXY(90, top)
+	pdf.SetFont("Helvetica", "B", 12)
+	pdf.SetTextColor(red, green, blue)
+	pdf.CellFormat(100, 6, tr(g.config.InstituteName), "", 2, "R", false, 0, "")
+
+	pdf.SetFont("Helvetica", "", 9)
+	pdf.SetTextColor(60, 60, 60)
+
+	for _, line := range g.config.InstituteDetails {
+		pdf.CellFormat(100, 4.5, tr(line), "", 2, "R", false, 0, "")
+	}
+
+	y := max(pdf.GetY(), top+20) + 4
+
+	pdf.SetDrawColor(red, green, blue)
+	pdf.SetLineWidth(0.6)
+	pdf.Line(20, y, 190, y)
+
+	pdf.SetXY(20, y+8)
+	pdf.SetFont("Helvetica", "B", 18)
+	pdf.SetTextColor(red, green, blue)
+	pdf.CellFormat(0, 10, tr(title), "", 1, "C", false, 0, "")
+	pdf.Ln(4)
+
+	pdf.SetFont("Helvetica", "", 10)
+	pdf.SetTextColor(0, 0, 0)
+	pdf.MultiCell(0, 5, tr(intro), "", "L", false)
+	pdf.Ln(4)
+
+	details := [][2]string{
+		{"Student", s.StudentName},
+		{"Student ID", s.StudentID},
+		{"Date issued", data.IssuedOn},
+	}
+
+	for _, d := range details {
+		pdf.SetFont("Helvetica", "B", 10)
+		pdf.CellFormat(30, 6, tr(d[0]), "", 0, "L", false, 0, "")
+		pdf.SetFont("Helvetica", "", 10)
+		pdf.CellFormat(0, 6, tr(d[1]), "", 1, "L", false, 0, "")
+	}
+
+	pdf.Ln(6)
+
+	if len(s.Results) == 0 {
+		pdf.SetFont("Helvetica", "I", 10)
+		pdf.CellFormat(0, 6, "No completed enrolments.", "", 1, "L", false, 0, "")
+	}
+
+	widths := []float64{110, 25, 35}
+
+	qualification := ""
+
+	for i, result := range s.Results {
+		if i == 0 || result.Qualification != qualification {
+			qualification = result.Qualification
+
+			// keep a heading with its first rows
+			if pdf.GetY() > 240 {
+				pdf.AddPage()
+			}
+
+			pdf.Ln(2)
+			pdf.SetFont("Helvetica", "B", 11)
+			pdf.SetFillColor(red, green, blue)
+			pdf.SetTextColor(255, 255, 255)
+			pdf.CellFormat(0, 7, tr(qualification), "", 1, "L", true, 0, "")
+
+			pdf.SetFont("Helvetica", "B", 9)
+			pdf.SetFillColor(235, 235, 235)
+			pdf.SetTextColor(0, 0, 0)
+
+			for j, header := range []string{"Course", "Grade", "Completed"} {
+				pdf.CellFormat(widths[j], 6, header, "B", 0, "L", true, 0, "")
+			}
+
+			pdf.Ln(-1)
+		}
+
+		completed := "-"
+		if !result.CompletedAt.IsZero() {
+			completed = result.CompletedAt.In(g.config.Location).Format(dateLayout)
+		}
+
+		pdf.SetFont("Helvetica", "", 9)
+
+		cells := []string{fit(pdf, tr(result.CourseName), widths[0]-2), tr(result.Grade), completed}
+
+		for j, cell := range cells {
+			pdf.CellFormat(widths[j], 6, cell, "B", 0, "L", false, 0, "")
+		}
+
+		pdf.Ln(-1)
+	}
+
+	return pdf.Output(w)
+}
+
+// fit shortens text with an ellipsis to fit in width.
+func fit(pdf *fpdf.Fpdf, text string, width float64) string {
+	if pdf.GetStringWidth(text) <= width {
+		return text
+	}
+
+	for len(text) > 0 && pdf.GetStringWidth(text+"...") > width {
+		text = text[:len(text)-1]
+	}
+
+	return strings.TrimSpace(text) + "..."
+}
+
+func (g *Generator) rgb() (int, int, int) {
+	v, _ := strconv.ParseUint(g.config.Color, 16, 32)
+
+	return int(v >> 16 & 0xff), int(v >> 8 & 0xff), int(v & 0xff)
+}
+
+// getLogo returns the logo and its fpdf image type, nil without one.
+func (g *Generator) getLogo(ctx context.Context) ([]byte, string) {
+	if g.config.LogoUrl == "" {
+		return nil, ""
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.logo != nil {
+		return g.logo, g.logoType
+	}
+
+	logo, logoType, err := g.fetchLogo(ctx)
+	if err != nil {
+		log.Printf("error fetching transcript logo: %v", err)
+		return nil, ""
+	}
+
+	g.logo, g.logoType = logo, logoType
+
+	return logo, logoType
+}
+
+func (g *Generator) fetchLogo(ctx context.Context) ([]byte, string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, g.config.LogoUrl, nil)
+	if err != nil {
+		return nil, "", err
+	}
+
+	res, err := g.httpClient.Do(req)
+	if err != nil {
+		return nil, "", err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return nil, "", fmt.Errorf("unsuccessful logo request: %s", res.Status)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(res.Body, 2<<20))
+	if err != nil {
+		return nil, "", err
+	}
+
+	switch contentType := http.DetectContentType(data); contentType {
+	case "image/png":
+		return data, "PNG", nil
+	case "image/jpeg":
+		return data, "JPG", nil
+	default:
+		return nil, "", fmt.Errorf("unsupported logo type %s, expected png or jpeg", contentType)
+	}
+}
+
+func execute(t *template.Template, data TemplateData) (string, error) {
+	var b strings.Builder
+
+	if err := t.Execute(&b, data); err != nil {
+		return "", err
+	}
+
+	return b.String(), nil
+}
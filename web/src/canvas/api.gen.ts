@@ -0,0 +1,1560 @@
+// Code generated by cmd/openapi. DO NOT EDIT.
+
+import { AxiosRequestConfig } from 'axios';
+import { axios } from '../axios';
+
+export interface APIKey {
+  account_ids: number[];
+  created_at: string;
+  created_by: string;
+  expires_at: string | null;
+  id: string;
+  last_used_at: string | null;
+  name: string;
+  prefix: string;
+  revoked_at: string | null;
+  scopes: string[];
+}
+
+export interface Account {
+  id: number;
+  name: string;
+  parent_account_id: number | null;
+  root_account_id: number | null;
+  workflow_state: string;
+}
+
+export interface AccountSubtotal {
+  account_id: number;
+  count: number;
+  courses: number;
+  depth: number;
+  name: string;
+}
+
+export interface AccountTree {
+  courses: number;
+  depth: number;
+  id: number;
+  name: string;
+  parent_account_id: number | null;
+  sub_accounts: AccountTree[];
+}
+
+export interface AssignmentDate {
+  base: boolean;
+  due_at: string;
+  id: number | null;
+  lock_at: string;
+  set_id: number | null;
+  set_type: string;
+  title: string;
+  unlock_at: string;
+}
+
+export interface AssignmentResult {
+  account: string;
+  course_name: string;
+  course_state: string;
+  discrepancies: Discrepancy[];
+  discrepancy: string;
+  due_at: string;
+  enrollment_role: string;
+  enrollment_state: string;
+  name: string;
+  points_possible: number | null;
+  score: number | null;
+  section: string;
+  status: string;
+  submitted_at: string;
+  title: string;
+  user_sis_id: string;
+}
+
+export interface AssignmentRule {
+  account_id: number | null;
+  assignment_group_id: number | null;
+  classification: string;
+  created_at: string;
+  created_by: string;
+  exclude: boolean;
+  grading_type: string | null;
+  id: number;
+  name: string;
+  name_pattern: string | null;
+  priority: number;
+  updated_at: string;
+}
+
+export interface AssignmentRuleBody {
+  account_id: number;
+  assignment_group_id: number;
+  classification: string;
+  exclude: boolean;
+  grading_type: string;
+  name: string;
+  name_pattern: string;
+  priority: number;
+}
+
+export interface AtRiskStudent {
+  account: string;
+  course_id: number;
+  course_name: string;
+  current_grade: string | null;
+  current_score: number | null;
+  days_since_last_submission: number | null;
+  grades_url: string;
+  last_submitted_at: string | null;
+  late_count: number;
+  missing_count: number;
+  name: string;
+  on_time_count: number;
+  risk_factors: string[];
+  risk_score: number;
+  section: string;
+  user_id: number;
+  user_sis_id: string;
+}
+
+export interface AttemptGrant {
+  allowed_attempts: number;
+  assignment_id: number;
+  assignment_name: string;
+  attempt: number;
+  grade: string;
+  message?: string;
+  status: string;
+  user_id: number;
+}
+
+export interface AttemptTarget {
+  assignment_id: number;
+  user_id: number;
+}
+
+export interface BatchResults {
+  assignments: AssignmentResult[];
+  enrollments: EnrollmentResult[];
+  ungraded_assignments: GetUngradedAssignmentsByUserResponse[];
+  unresolved: BatchUnresolved[];
+}
+
+export interface BatchResultsBody {
+  sis_ids: string[];
+}
+
+export interface BatchSessionTerminations {
+  dry_run: boolean;
+  failed: number;
+  terminated: number;
+  unresolved: number;
+  users: SessionTermination[];
+}
+
+export interface BatchSessionsBody {
+  course_ids: number[];
+  dry_run: boolean;
+  section_ids: number[];
+  sis_ids: string[];
+}
+
+export interface BatchUnresolved {
+  reason: string;
+  sis_id: string;
+}
+
+export interface Course {
+  account: Account;
+  account_id: number;
+  course_code: string;
+  end_at: string | null;
+  enrollment_term_id: number;
+  friendly_name: string | null;
+  grading_standard_id: number | null;
+  id: number;
+  is_public: boolean;
+  name: string;
+  root_account_id: number;
+  sections: Section[];
+  sis_course_id: string | null;
+  start_at: string | null;
+  teachers?: UserDisplay[];
+  workflow_state: string;
+}
+
+export interface CourseSearchResults {
+  courses: Course[];
+  page: number;
+  per_page: number;
+  total: number | null;
+}
+
+export interface CreateAPIKeyRequest {
+  account_ids?: number[];
+  expires_at?: string | null;
+  name: string;
+  scopes: string[];
+}
+
+export interface CreatedAPIKey {
+  account_ids: number[];
+  created_at: string;
+  created_by: string;
+  expires_at: string | null;
+  id: string;
+  key: string;
+  last_used_at: string | null;
+  name: string;
+  prefix: string;
+  revoked_at: string | null;
+  scopes: string[];
+}
+
+export interface Discrepancy {
+  code: string;
+  message: string;
+}
+
+export interface EnrollmentChange {
+  course_id: number;
+  enrollment_id: number;
+  from_state: string;
+  message?: string;
+  name: string;
+  section: string;
+  sis_user_id: string;
+  status: string;
+  to_state: string;
+  user_id: number;
+}
+
+export interface EnrollmentChangesBody {
+  action: string;
+  dry_run: boolean;
+  enrollments: EnrollmentTarget[];
+  force: boolean;
+  reason: string;
+}
+
+export interface EnrollmentChangesResult {
+  action: string;
+  changed: number;
+  changes: EnrollmentChange[];
+  dry_run: boolean;
+  failed: number;
+  refused: number;
+}
+
+export interface EnrollmentResult {
+  account: string;
+  course_name: string;
+  course_state: string;
+  current_grade: string | null;
+  current_score: number | null;
+  enrollment_role: string;
+  enrollment_state: string;
+  grades_url: string;
+  name: string;
+  section: string;
+  sis_id: string;
+}
+
+export interface EnrollmentTarget {
+  course_id: number;
+  user_id: number;
+}
+
+export interface EnrollmentTerm {
+  end_at: string | null;
+  id: number;
+  name: string;
+  sis_term_id: string | null;
+  start_at: string | null;
+  workflow_state: string;
+}
+
+export interface ErrorResponse {
+  error: string;
+  fields?: FieldError[];
+}
+
+export interface ExploredAssignment {
+  account: string;
+  assignment_id: number;
+  classification: string;
+  course_id: number;
+  course_name: string;
+  dates: AssignmentDate[];
+  due_at: string | null;
+  excluded: boolean;
+  grading_type: string;
+  html_url: string;
+  lock_at: string | null;
+  name: string;
+  needs_grading_count: number;
+  points_possible: number | null;
+  published: boolean;
+  unlock_at: string | null;
+}
+
+export interface Extension {
+  approved_by: string;
+  assignment_due_at: string | null;
+  assignment_id: number;
+  assignment_name: string;
+  course_id: number;
+  due_at: string | null;
+  granted_at: string | null;
+  granted_by: string;
+  lock_at: string | null;
+  override_id: number;
+  reason: string;
+  section: string;
+  sis_user_id: string;
+  user_id: number;
+  user_name: string;
+}
+
+export interface ExtensionChange {
+  message?: string;
+  override_id: number;
+  status: string;
+  user_id: number;
+}
+
+export interface FieldError {
+  field: string;
+  message: string;
+}
+
+export interface GetUngradedAssignmentsByUserResponse {
+  account: string;
+  course_name: string;
+  course_state: string;
+  enrollment_role: string;
+  enrollment_state: string;
+  name: string;
+  points_possible: number | null;
+  score: number | null;
+  section: string;
+  speedgrader_url: string;
+  status: string;
+  submitted_at: string;
+  title: string;
+  user_sis_id: string;
+}
+
+export interface GradeChangeLog {
+  account_id: number;
+  assignment_id: number;
+  assignment_title: string;
+  course_id: number;
+  course_name: string;
+  created_at: string;
+  event_type: string;
+  grade_after: string | null;
+  grade_before: string | null;
+  id: string;
+  user_id: number;
+  user_name: string;
+}
+
+export interface GradePosting {
+  assignment_id: number;
+  assignment_title: string;
+  error?: string;
+  progress_id?: string;
+  section_ids: number[];
+  status: string;
+  students: UnpostedSubmission[];
+}
+
+export interface GradePostingResult {
+  assignments: GradePosting[];
+  dry_run: boolean;
+  failed: number;
+  posted: number;
+}
+
+export interface GradebookCell {
+  assignment_id: number;
+  excused: boolean;
+  grade: string | null;
+  late: boolean;
+  missing: boolean;
+  score: number | null;
+  workflow_state: string;
+}
+
+export interface GradebookColumn {
+  due_at: string | null;
+  id: number;
+  name: string;
+  points_possible: number | null;
+}
+
+export interface GradebookMatrix {
+  assignments: GradebookColumn[];
+  course_id: number;
+  course_name: string;
+  students: GradebookRow[];
+}
+
+export interface GradebookRow {
+  cells: GradebookCell[];
+  current_score: number | null;
+  name: string;
+  section: string;
+  user_id: number;
+  user_sis_id: string;
+}
+
+export interface GrantAttemptsBody {
+  attempts: AttemptTarget[];
+  dry_run: boolean;
+  extra_attempts: number;
+  pass_percentage: number;
+  reason: string;
+}
+
+export interface GrantExtensionsBody {
+  approved_by: string;
+  assignment_id: number;
+  due_at: string;
+  lock_at: string;
+  reason: string;
+  user_ids: number[];
+}
+
+export interface HideGradesBody {
+  dry_run: boolean;
+  section_ids: number[];
+}
+
+export interface LiveEventReceipt {
+  event_name: string;
+  status: string;
+}
+
+export interface LiveUngradedCount {
+  assignment_id: number;
+  assignment_name: string;
+  needs_grading_count: number;
+  oldest_submitted_at: string | null;
+}
+
+export interface PostGradesBody {
+  assignment_ids: number[];
+  dry_run: boolean;
+  section_ids: number[];
+}
+
+export interface SLABreach {
+  account_id: number;
+  account_name: string;
+  assignment_id: number;
+  assignment_title: string;
+  attempt: number;
+  business_days: number;
+  course_id: number;
+  course_name: string;
+  level: string;
+  section: string;
+  speedgrader_url: string;
+  submission_id: number;
+  submitted_at: string;
+  teachers: string[];
+  user_id: number;
+}
+
+export interface SLAScanResult {
+  alerts: number;
+  breaches: SLABreach[];
+  notifications: number;
+}
+
+export interface Section {
+  course_id: number;
+  created_at: string;
+  end_at: string | null;
+  id: number;
+  name: string;
+  sis_section_id: string;
+  start_at: string | null;
+  total_students: number | null;
+}
+
+export interface SessionTermination {
+  error?: string;
+  name: string;
+  sis_user_id: string;
+  source: string;
+  status: string;
+  user_id: number;
+}
+
+export interface SlaScanRequest {
+  account_ids: number[];
+  dry_run?: boolean;
+}
+
+export interface TeacherWorkload {
+  course_count: number;
+  name: string;
+  needs_grading_count: number;
+  oldest_submitted_at: string | null;
+  oldest_waiting_days: number | null;
+  section_count: number;
+  sections: TeacherWorkloadSection[];
+  teacher_id: number;
+  teacher_sis_id: string;
+}
+
+export interface TeacherWorkloadSection {
+  course_id: number;
+  course_name: string;
+  gradebook_url: string;
+  needs_grading_count: number;
+  oldest_submitted_at: string | null;
+  section: string;
+}
+
+export interface TurnaroundBreach {
+  assignment_id: number;
+  assignment_title: string;
+  business_days: number;
+  course_id: number;
+  course_name: string;
+  graded_at: string;
+  grader_id: number;
+  grader_name: string;
+  section: string;
+  speedgrader_url: string;
+  submitted_at: string;
+  turnaround_hours: number;
+  user_id: number;
+  user_name: string;
+}
+
+export interface TurnaroundGroup {
+  breach_count: number;
+  count: number;
+  course_name: string;
+  id: number;
+  median_hours: number;
+  name: string;
+  p90_hours: number;
+  within_sla: number;
+}
+
+export interface TurnaroundReport {
+  account: TurnaroundStats;
+  account_id: number;
+  accounts?: TurnaroundGroup[];
+  assignments: TurnaroundGroup[];
+  breaches: TurnaroundBreach[];
+  end_time: string;
+  graders: TurnaroundGroup[];
+  sections: TurnaroundGroup[];
+  sla_days: number;
+  start_time: string;
+}
+
+export interface TurnaroundStats {
+  breach_count: number;
+  count: number;
+  median_hours: number;
+  p90_hours: number;
+  within_sla: number;
+}
+
+export interface UngradedAssignment {
+  course_id: number;
+  due_at: string;
+  gradebook_url: string;
+  lock_at: string;
+  name: string;
+  needs_grading_section: number;
+  published: boolean;
+  section: string;
+  teachers: string;
+  unlock_at: string;
+}
+
+export interface UngradedAssignmentWithAccountCourseInfo {
+  account: string;
+  course_id: number;
+  course_name: string;
+  due_at: string;
+  gradebook_url: string;
+  lock_at: string;
+  name: string;
+  needs_grading_section: number;
+  published: boolean;
+  section: string;
+  teachers: string;
+  unlock_at: string;
+}
+
+export interface UngradedAssignmentsReport {
+  accounts: AccountSubtotal[];
+  assignments: UngradedAssignmentWithAccountCourseInfo[];
+}
+
+export interface UnpostedGradesReport {
+  accounts?: AccountSubtotal[];
+  assignments: UnpostedGroup[];
+  count: number;
+  sections: UnpostedGroup[];
+  submissions: UnpostedSubmission[];
+}
+
+export interface UnpostedGroup {
+  count: number;
+  course_id: number;
+  course_name: string;
+  id: number;
+  max_age_days: number;
+  name: string;
+}
+
+export interface UnpostedSubmission {
+  age_days: number;
+  assignment_id: number;
+  assignment_title: string;
+  course_id: number;
+  course_name: string;
+  graded_at: string;
+  grader_id: number;
+  section: string;
+  section_id: number;
+  speedgrader_url: string;
+  user_id: number;
+  user_name: string;
+}
+
+export interface UserDisplay {
+  display_name: string;
+  id: number;
+}
+
+/** Conclude, deactivate, delete or reactivate the student enrollments of users in courses, or validate them with dry_run */
+export const changeEnrollments = async (
+  body: EnrollmentChangesBody,
+  config?: AxiosRequestConfig
+) => {
+  const { data } = await axios.request<EnrollmentChangesResult>({
+    ...config,
+    method: 'post',
+    url: `/enrollments/changes`,
+    data: body,
+  });
+
+  return data;
+};
+
+/** Create an API key, the key is only returned once. Superadmin only */
+export const createAPIKey = async (
+  body: CreateAPIKeyRequest,
+  config?: AxiosRequestConfig
+) => {
+  const { data } = await axios.request<CreatedAPIKey>({
+    ...config,
+    method: 'post',
+    url: `/api-keys`,
+    data: body,
+  });
+
+  return data;
+};
+
+/** Create an assignment rule, Admin only */
+export const createAssignmentRule = async (
+  body: AssignmentRuleBody,
+  config?: AxiosRequestConfig
+) => {
+  const { data } = await axios.request<AssignmentRule>({
+    ...config,
+    method: 'post',
+    url: `/assignment-rules`,
+    data: body,
+  });
+
+  return data;
+};
+
+export interface DeleteAssignmentRuleParams {
+  rule_id: number;
+}
+
+/** Delete an assignment rule, Admin only */
+export const deleteAssignmentRule = async (
+  params: DeleteAssignmentRuleParams,
+  config?: AxiosRequestConfig
+) => {
+  const { data } = await axios.request<AssignmentRule>({
+    ...config,
+    method: 'delete',
+    url: `/assignment-rules/${params.rule_id}`,
+  });
+
+  return data;
+};
+
+/** API keys, Superadmin only */
+export const getAPIKeys = async (
+  config?: AxiosRequestConfig
+) => {
+  const { data } = await axios.request<APIKey[]>({
+    ...config,
+    method: 'get',
+    url: `/api-keys`,
+  });
+
+  return data;
+};
+
+export interface GetAccountTreeParams {
+  account_id: number;
+  enrollment_term_id?: number;
+  from?: string;
+  to?: string;
+}
+
+/** Sub-account tree of an account with the courses of each sub-account */
+export const getAccountTree = async (
+  params: GetAccountTreeParams,
+  config?: AxiosRequestConfig
+) => {
+  const { data } = await axios.request<AccountTree>({
+    ...config,
+    method: 'get',
+    url: `/accounts/${params.account_id}/tree`,
+    params: { enrollment_term_id: params.enrollment_term_id, from: params.from, to: params.to },
+  });
+
+  return data;
+};
+
+export interface GetAssignmentExplorerByAccountParams {
+  account_id: number;
+  enrollment_term_id?: number;
+  from?: string;
+  to?: string;
+  bucket?: 'all' | 'past' | 'overdue' | 'undated' | 'ungraded' | 'unsubmitted' | 'upcoming' | 'future';
+  search_term?: string;
+  published?: 'true' | 'false';
+  grading_type?: ('pass_fail' | 'percent' | 'letter_grade' | 'gpa_scale' | 'points' | 'not_graded')[];
+}
+
+/** Assignments of the courses of an account by Canvas bucket, search, publishing and grading type, with the dates of each section */
+export const getAssignmentExplorerByAccount = async (
+  params: GetAssignmentExplorerByAccountParams,
+  config?: AxiosRequestConfig
+) => {
+  const { data } = await axios.request<ExploredAssignment[]>({
+    ...config,
+    method: 'get',
+    url: `/accounts/${params.account_id}/assignments`,
+    params: { enrollment_term_id: params.enrollment_term_id, from: params.from, to: params.to, bucket: params.bucket, search_term: params.search_term, published: params.published, grading_type: params.grading_type },
+  });
+
+  return data;
+};
+
+export interface GetAssignmentExplorerByCourseParams {
+  course_id: number;
+  bucket?: 'all' | 'past' | 'overdue' | 'undated' | 'ungraded' | 'unsubmitted' | 'upcoming' | 'future';
+  search_term?: string;
+  published?: 'true' | 'false';
+  grading_type?: ('pass_fail' | 'percent' | 'letter_grade' | 'gpa_scale' | 'points' | 'not_graded')[];
+}
+
+/** Assignments of a course by Canvas bucket, search, publishing and grading type, with the dates of each section */
+export const getAssignmentExplorerByCourse = async (
+  params: GetAssignmentExplorerByCourseParams,
+  config?: AxiosRequestConfig
+) => {
+  const { data } = await axios.request<ExploredAssignment[]>({
+    ...config,
+    method: 'get',
+    url: `/courses/${params.course_id}/assignments`,
+    params: { bucket: params.bucket, search_term: params.search_term, published: params.published, grading_type: params.grading_type },
+  });
+
+  return data;
+};
+
+/** Rules classifying assignments in the reports, e.g. coversheets left out of them, in the order they apply */
+export const getAssignmentRules = async (
+  config?: AxiosRequestConfig
+) => {
+  const { data } = await axios.request<AssignmentRule[]>({
+    ...config,
+    method: 'get',
+    url: `/assignment-rules`,
+  });
+
+  return data;
+};
+
+export interface GetAssignmentsResultsByUserParams {
+  user_id: number;
+}
+
+/** Assignment results of a student with course totals */
+export const getAssignmentsResultsByUser = async (
+  params: GetAssignmentsResultsByUserParams,
+  config?: AxiosRequestConfig
+) => {
+  const { data } = await axios.request<AssignmentResult[]>({
+    ...config,
+    method: 'get',
+    url: `/users/${params.user_id}/assignments-results`,
+  });
+
+  return data;
+};
+
+export interface GetAtRiskStudentsByAccountParams {
+  account_id: number;
+  missing_count?: number;
+  late_count?: number;
+  current_score?: number;
+  inactive_days?: number;
+  min_risk_score?: number;
+  factors?: ('missing' | 'late' | 'low_score' | 'inactive')[];
+  enrollment_term_id?: number;
+  from?: string;
+  to?: string;
+}
+
+/** Active students of all available courses in an account ranked by risk score */
+export const getAtRiskStudentsByAccount = async (
+  params: GetAtRiskStudentsByAccountParams,
+  config?: AxiosRequestConfig
+) => {
+  const { data } = await axios.request<AtRiskStudent[]>({
+    ...config,
+    method: 'get',
+    url: `/accounts/${params.account_id}/at-risk-students`,
+    params: { missing_count: params.missing_count, late_count: params.late_count, current_score: params.current_score, inactive_days: params.inactive_days, min_risk_score: params.min_risk_score, factors: params.factors, enrollment_term_id: params.enrollment_term_id, from: params.from, to: params.to },
+  });
+
+  return data;
+};
+
+export interface GetAtRiskStudentsByCourseParams {
+  course_id: number;
+  missing_count?: number;
+  late_count?: number;
+  current_score?: number;
+  inactive_days?: number;
+  min_risk_score?: number;
+  factors?: ('missing' | 'late' | 'low_score' | 'inactive')[];
+}
+
+/** Active students of a course ranked by risk score */
+export const getAtRiskStudentsByCourse = async (
+  params: GetAtRiskStudentsByCourseParams,
+  config?: AxiosRequestConfig
+) => {
+  const { data } = await axios.request<AtRiskStudent[]>({
+    ...config,
+    method: 'get',
+    url: `/courses/${params.course_id}/at-risk-students`,
+    params: { missing_count: params.missing_count, late_count: params.late_count, current_score: params.current_score, inactive_days: params.inactive_days, min_risk_score: params.min_risk_score, factors: params.factors },
+  });
+
+  return data;
+};
+
+export interface GetAtRiskStudentsBySectionParams {
+  section_id: number;
+  missing_count?: number;
+  late_count?: number;
+  current_score?: number;
+  inactive_days?: number;
+  min_risk_score?: number;
+  factors?: ('missing' | 'late' | 'low_score' | 'inactive')[];
+}
+
+/** Active students of a section ranked by risk score */
+export const getAtRiskStudentsBySection = async (
+  params: GetAtRiskStudentsBySectionParams,
+  config?: AxiosRequestConfig
+) => {
+  const { data } = await axios.request<AtRiskStudent[]>({
+    ...config,
+    method: 'get',
+    url: `/sections/${params.section_id}/at-risk-students`,
+    params: { missing_count: params.missing_count, late_count: params.late_count, current_score: params.current_score, inactive_days: params.inactive_days, min_risk_score: params.min_risk_score, factors: params.factors },
+  });
+
+  return data;
+};
+
+export interface GetBatchResultsBySISIDsParams {
+  reports?: ('enrollments' | 'assignments' | 'ungraded')[];
+}
+
+/** Enrollments, assignments and ungraded assignments of the students of a JSON or CSV list of SIS ids */
+export const getBatchResultsBySISIDs = async (
+  params: GetBatchResultsBySISIDsParams,
+  body: BatchResultsBody,
+  config?: AxiosRequestConfig
+) => {
+  const { data } = await axios.request<BatchResults>({
+    ...config,
+    method: 'post',
+    url: `/users/batch-results`,
+    params: { reports: params.reports },
+    data: body,
+  });
+
+  return data;
+};
+
+export interface GetCoursesByAccountIDParams {
+  account_id: number;
+  enrollment_term_id?: number;
+  from?: string;
+  to?: string;
+}
+
+/** Courses of an account with student enrollments */
+export const getCoursesByAccountID = async (
+  params: GetCoursesByAccountIDParams,
+  config?: AxiosRequestConfig
+) => {
+  const { data } = await axios.request<Course[]>({
+    ...config,
+    method: 'get',
+    url: `/accounts/${params.account_id}/courses`,
+    params: { enrollment_term_id: params.enrollment_term_id, from: params.from, to: params.to },
+  });
+
+  return data;
+};
+
+export interface GetEnrollmentResultsByCourseParams {
+  course_id: number;
+  course_name: string;
+  account_name: string;
+  course_workflow_state: string;
+}
+
+/** Student enrollment results of a course */
+export const getEnrollmentResultsByCourse = async (
+  params: GetEnrollmentResultsByCourseParams,
+  config?: AxiosRequestConfig
+) => {
+  const { data } = await axios.request<EnrollmentResult[]>({
+    ...config,
+    method: 'get',
+    url: `/courses/${params.course_id}/enrollments-results`,
+    params: { course_name: params.course_name, account_name: params.account_name, course_workflow_state: params.course_workflow_state },
+  });
+
+  return data;
+};
+
+export interface GetEnrollmentTermsByAccountIDParams {
+  account_id: number;
+}
+
+/** Active enrollment terms of the root account of an account */
+export const getEnrollmentTermsByAccountID = async (
+  params: GetEnrollmentTermsByAccountIDParams,
+  config?: AxiosRequestConfig
+) => {
+  const { data } = await axios.request<EnrollmentTerm[]>({
+    ...config,
+    method: 'get',
+    url: `/accounts/${params.account_id}/terms`,
+  });
+
+  return data;
+};
+
+export interface GetEnrollmentsResultsByUserParams {
+  user_id: number;
+}
+
+/** Enrollment results of a user */
+export const getEnrollmentsResultsByUser = async (
+  params: GetEnrollmentsResultsByUserParams,
+  config?: AxiosRequestConfig
+) => {
+  const { data } = await axios.request<EnrollmentResult[]>({
+    ...config,
+    method: 'get',
+    url: `/users/${params.user_id}/enrollments-results`,
+  });
+
+  return data;
+};
+
+export interface GetExtensionsByCourseParams {
+  course_id: number;
+  include_expired?: boolean;
+}
+
+/** Active students with their own dates on the assignments of a course, with the reason and approver of each extension, expired ones with include_expired */
+export const getExtensionsByCourse = async (
+  params: GetExtensionsByCourseParams,
+  config?: AxiosRequestConfig
+) => {
+  const { data } = await axios.request<Extension[]>({
+    ...config,
+    method: 'get',
+    url: `/courses/${params.course_id}/extensions`,
+    params: { include_expired: params.include_expired },
+  });
+
+  return data;
+};
+
+export interface GetGradeChangeLogsByGraderIDParams {
+  grader_id: number;
+  start_time: string;
+  end_time: string;
+}
+
+/** Grade change audit logs of a grader */
+export const getGradeChangeLogsByGraderID = async (
+  params: GetGradeChangeLogsByGraderIDParams,
+  config?: AxiosRequestConfig
+) => {
+  const { data } = await axios.request<GradeChangeLog[]>({
+    ...config,
+    method: 'get',
+    url: `/users/${params.grader_id}/grade-change-logs`,
+    params: { start_time: params.start_time, end_time: params.end_time },
+  });
+
+  return data;
+};
+
+export interface GetGradebookByCourseParams {
+  course_id: number;
+  section_id?: number;
+  format?: 'json' | 'csv' | 'xlsx';
+}
+
+/** Students by assignments matrix of a course or section as JSON, CSV or XLSX */
+export const getGradebookByCourse = async (
+  params: GetGradebookByCourseParams,
+  config?: AxiosRequestConfig
+) => {
+  const { data } = await axios.request<GradebookMatrix>({
+    ...config,
+    method: 'get',
+    url: `/courses/${params.course_id}/gradebook`,
+    params: { section_id: params.section_id, format: params.format },
+  });
+
+  return data;
+};
+
+export interface GetGradingTurnaroundByAccountIDParams {
+  account_id: number;
+  start_time: string;
+  end_time: string;
+  sla_days?: number;
+  recursive?: boolean;
+  enrollment_term_id?: number;
+  from?: string;
+  to?: string;
+}
+
+/** Grading turnaround per assignment, section and grader with SLA breaches */
+export const getGradingTurnaroundByAccountID = async (
+  params: GetGradingTurnaroundByAccountIDParams,
+  config?: AxiosRequestConfig
+) => {
+  const { data } = await axios.request<TurnaroundReport>({
+    ...config,
+    method: 'get',
+    url: `/accounts/${params.account_id}/grading-turnaround`,
+    params: { start_time: params.start_time, end_time: params.end_time, sla_days: params.sla_days, recursive: params.recursive, enrollment_term_id: params.enrollment_term_id, from: params.from, to: params.to },
+  });
+
+  return data;
+};
+
+export interface GetLiveUngradedCountsByCourseParams {
+  course_id: number;
+}
+
+/** Submissions awaiting grading of each assignment of a course, from the Canvas Live Events */
+export const getLiveUngradedCountsByCourse = async (
+  params: GetLiveUngradedCountsByCourseParams,
+  config?: AxiosRequestConfig
+) => {
+  const { data } = await axios.request<LiveUngradedCount[]>({
+    ...config,
+    method: 'get',
+    url: `/courses/${params.course_id}/live-ungraded-counts`,
+  });
+
+  return data;
+};
+
+export interface GetSLABreachesByAccountIDParams {
+  account_id: number;
+  enrollment_term_id?: number;
+  from?: string;
+  to?: string;
+}
+
+/** Submissions waiting for grading longer than the marking SLA */
+export const getSLABreachesByAccountID = async (
+  params: GetSLABreachesByAccountIDParams,
+  config?: AxiosRequestConfig
+) => {
+  const { data } = await axios.request<SLABreach[]>({
+    ...config,
+    method: 'get',
+    url: `/accounts/${params.account_id}/sla-breaches`,
+    params: { enrollment_term_id: params.enrollment_term_id, from: params.from, to: params.to },
+  });
+
+  return data;
+};
+
+export interface GetStatementOfResultsParams {
+  user_id: string;
+}
+
+/** Statement of results PDF of the completed enrollments of a student, by Canvas id or sis_user_id:<sis id> */
+export const getStatementOfResults = async (
+  params: GetStatementOfResultsParams,
+  config?: AxiosRequestConfig
+) => {
+  const { data } = await axios.request<Blob>({
+    ...config,
+    responseType: 'blob',
+    method: 'get',
+    url: `/users/${params.user_id}/statement-of-results`,
+  });
+
+  return data;
+};
+
+export interface GetTeacherWorkloadByAccountIDParams {
+  account_id: number;
+  enrollment_term_id?: number;
+  from?: string;
+  to?: string;
+}
+
+/** Submissions awaiting grading per teacher across an account */
+export const getTeacherWorkloadByAccountID = async (
+  params: GetTeacherWorkloadByAccountIDParams,
+  config?: AxiosRequestConfig
+) => {
+  const { data } = await axios.request<TeacherWorkload[]>({
+    ...config,
+    method: 'get',
+    url: `/accounts/${params.account_id}/teacher-workload`,
+    params: { enrollment_term_id: params.enrollment_term_id, from: params.from, to: params.to },
+  });
+
+  return data;
+};
+
+export interface GetUngradedAssignmentSubtotalsByAccountIDParams {
+  account_id: number;
+  enrollment_term_id?: number;
+  from?: string;
+  to?: string;
+}
+
+/** Ungraded assignments of all courses in an account with subtotals for every sub-account */
+export const getUngradedAssignmentSubtotalsByAccountID = async (
+  params: GetUngradedAssignmentSubtotalsByAccountIDParams,
+  config?: AxiosRequestConfig
+) => {
+  const { data } = await axios.request<UngradedAssignmentsReport>({
+    ...config,
+    method: 'get',
+    url: `/accounts/${params.account_id}/ungraded-assignments/subtotals`,
+    params: { enrollment_term_id: params.enrollment_term_id, from: params.from, to: params.to },
+  });
+
+  return data;
+};
+
+export interface GetUngradedAssignmentsByAccountIDParams {
+  account_id: number;
+  enrollment_term_id?: number;
+  from?: string;
+  to?: string;
+}
+
+/** Ungraded assignments of all courses in an account */
+export const getUngradedAssignmentsByAccountID = async (
+  params: GetUngradedAssignmentsByAccountIDParams,
+  config?: AxiosRequestConfig
+) => {
+  const { data } = await axios.request<UngradedAssignmentWithAccountCourseInfo[]>({
+    ...config,
+    method: 'get',
+    url: `/accounts/${params.account_id}/ungraded-assignments`,
+    params: { enrollment_term_id: params.enrollment_term_id, from: params.from, to: params.to },
+  });
+
+  return data;
+};
+
+export interface GetUngradedAssignmentsByCourseParams {
+  course_id: number;
+  course_name: string;
+  account_name: string;
+}
+
+/** Ungraded assignments of a course by section */
+export const getUngradedAssignmentsByCourse = async (
+  params: GetUngradedAssignmentsByCourseParams,
+  config?: AxiosRequestConfig
+) => {
+  const { data } = await axios.request<UngradedAssignmentWithAccountCourseInfo[]>({
+    ...config,
+    method: 'get',
+    url: `/courses/${params.course_id}/ungraded-assignments`,
+    params: { course_name: params.course_name, account_name: params.account_name },
+  });
+
+  return data;
+};
+
+export interface GetUngradedAssignmentsByCoursesParams {
+  ids: number[];
+}
+
+/** Ungraded assignments of many courses by section */
+export const getUngradedAssignmentsByCourses = async (
+  params: GetUngradedAssignmentsByCoursesParams,
+  config?: AxiosRequestConfig
+) => {
+  const { data } = await axios.request<UngradedAssignment[]>({
+    ...config,
+    method: 'get',
+    url: `/courses/ungraded-assignments`,
+    params: { ids: params.ids },
+  });
+
+  return data;
+};
+
+export interface GetUngradedAssignmentsByUserParams {
+  user_id: number;
+}
+
+/** Submitted but ungraded assignments of a student */
+export const getUngradedAssignmentsByUser = async (
+  params: GetUngradedAssignmentsByUserParams,
+  config?: AxiosRequestConfig
+) => {
+  const { data } = await axios.request<GetUngradedAssignmentsByUserResponse[]>({
+    ...config,
+    method: 'get',
+    url: `/users/${params.user_id}/ungraded-assignments`,
+  });
+
+  return data;
+};
+
+export interface GetUnpostedGradesByAccountParams {
+  account_id: number;
+  enrollment_term_id?: number;
+  from?: string;
+  to?: string;
+  recursive?: boolean;
+}
+
+/** Graded submissions of all available courses in an account hidden from students by post policies */
+export const getUnpostedGradesByAccount = async (
+  params: GetUnpostedGradesByAccountParams,
+  config?: AxiosRequestConfig
+) => {
+  const { data } = await axios.request<UnpostedGradesReport>({
+    ...config,
+    method: 'get',
+    url: `/accounts/${params.account_id}/unposted-grades`,
+    params: { enrollment_term_id: params.enrollment_term_id, from: params.from, to: params.to, recursive: params.recursive },
+  });
+
+  return data;
+};
+
+export interface GetUnpostedGradesByCourseParams {
+  course_id: number;
+}
+
+/** Graded submissions of a course hidden from students by post policies */
+export const getUnpostedGradesByCourse = async (
+  params: GetUnpostedGradesByCourseParams,
+  config?: AxiosRequestConfig
+) => {
+  const { data } = await axios.request<UnpostedGradesReport>({
+    ...config,
+    method: 'get',
+    url: `/courses/${params.course_id}/unposted-grades`,
+  });
+
+  return data;
+};
+
+export interface GrantAdditionalAttemptsParams {
+  course_id: number;
+}
+
+/** Grant students with a failed attempt more attempts on assignments of a course, or validate them with dry_run */
+export const grantAdditionalAttempts = async (
+  params: GrantAdditionalAttemptsParams,
+  body: GrantAttemptsBody,
+  config?: AxiosRequestConfig
+) => {
+  const { data } = await axios.request<AttemptGrant[]>({
+    ...config,
+    method: 'post',
+    url: `/courses/${params.course_id}/additional-attempts`,
+    data: body,
+  });
+
+  return data;
+};
+
+export interface GrantExtensionsParams {
+  course_id: number;
+}
+
+/** Grant students new due and lock dates on an assignment */
+export const grantExtensions = async (
+  params: GrantExtensionsParams,
+  body: GrantExtensionsBody,
+  config?: AxiosRequestConfig
+) => {
+  const { data } = await axios.request<ExtensionChange[]>({
+    ...config,
+    method: 'post',
+    url: `/courses/${params.course_id}/extensions`,
+    data: body,
+  });
+
+  return data;
+};
+
+export interface HideGradesByAssignmentParams {
+  course_id: number;
+  assignment_id: number;
+}
+
+/** Hide the grades of an assignment from students, of some sections when set */
+export const hideGradesByAssignment = async (
+  params: HideGradesByAssignmentParams,
+  body: HideGradesBody,
+  config?: AxiosRequestConfig
+) => {
+  const { data } = await axios.request<GradePosting>({
+    ...config,
+    method: 'post',
+    url: `/courses/${params.course_id}/assignments/${params.assignment_id}/grades/hide`,
+    data: body,
+  });
+
+  return data;
+};
+
+/** Store a Canvas Live Event sent as a signed JWT, events are stored once and update the live ungraded counts */
+export const ingestLiveEvent = async (
+  config?: AxiosRequestConfig
+) => {
+  const { data } = await axios.request<LiveEventReceipt>({
+    ...config,
+    method: 'post',
+    url: `/live-events`,
+  });
+
+  return data;
+};
+
+export interface PostGradesByCourseParams {
+  course_id: number;
+}
+
+/** Post the graded but hidden submissions of a course to students, or preview them with dry_run */
+export const postGradesByCourse = async (
+  params: PostGradesByCourseParams,
+  body: PostGradesBody,
+  config?: AxiosRequestConfig
+) => {
+  const { data } = await axios.request<GradePostingResult>({
+    ...config,
+    method: 'post',
+    url: `/courses/${params.course_id}/grades/post`,
+    data: body,
+  });
+
+  return data;
+};
+
+export interface RemoveExtensionParams {
+  course_id: number;
+  assignment_id: number;
+  user_id: number;
+}
+
+/** Remove the extension of a student on an assignment */
+export const removeExtension = async (
+  params: RemoveExtensionParams,
+  config?: AxiosRequestConfig
+) => {
+  const { data } = await axios.request<void>({
+    ...config,
+    method: 'delete',
+    url: `/courses/${params.course_id}/assignments/${params.assignment_id}/extensions/${params.user_id}`,
+  });
+
+  return data;
+};
+
+export interface RevokeAPIKeyParams {
+  api_key_id: string;
+}
+
+/** Revoke an API key, Superadmin only */
+export const revokeAPIKey = async (
+  params: RevokeAPIKeyParams,
+  config?: AxiosRequestConfig
+) => {
+  const { data } = await axios.request<APIKey>({
+    ...config,
+    method: 'delete',
+    url: `/api-keys/${params.api_key_id}`,
+  });
+
+  return data;
+};
+
+/** Alert teachers and escalate to managers the marking SLA breaches of accounts */
+export const scanGradingSLA = async (
+  body: SlaScanRequest,
+  config?: AxiosRequestConfig
+) => {
+  const { data } = await axios.request<SLAScanResult>({
+    ...config,
+    method: 'post',
+    url: `/sla/scan`,
+    data: body,
+  });
+
+  return data;
+};
+
+export interface SearchCoursesByAccountIDParams {
+  account_id: number;
+  search_term?: string;
+  state?: ('created' | 'claimed' | 'available' | 'completed' | 'deleted')[];
+  published?: 'true' | 'false';
+  teacher?: string;
+  sis_course_id_prefix?: string;
+  sort?: 'course_name' | 'sis_course_id' | 'teacher' | 'account_name' | 'start_at';
+  order?: 'asc' | 'desc';
+  page?: number;
+  per_page?: number;
+  enrollment_term_id?: number;
+  from?: string;
+  to?: string;
+}
+
+/**
+ * Search the courses of an account by name, teacher, SIS id prefix, state and term, sorted and paginated
+ *
+ * Canvas filters, sorts and pages the courses, except in these cases where all the matching courses are listed, then filtered, sorted and paged here:
+ * - sort=start_at, Canvas cannot sort by start date.
+ * - sis_course_id_prefix is set, Canvas only searches SIS ids with search_term.
+ * - Both search_term and teacher are set. Canvas searches the courses by search_term, then only the courses with a teacher whose name contains teacher, case insensitively, are kept. With teacher alone Canvas searches the teachers.
+ */
+export const searchCoursesByAccountID = async (
+  params: SearchCoursesByAccountIDParams,
+  config?: AxiosRequestConfig
+) => {
+  const { data } = await axios.request<CourseSearchResults>({
+    ...config,
+    method: 'get',
+    url: `/accounts/${params.account_id}/courses/search`,
+    params: { search_term: params.search_term, state: params.state, published: params.published, teacher: params.teacher, sis_course_id_prefix: params.sis_course_id_prefix, sort: params.sort, order: params.order, page: params.page, per_page: params.per_page, enrollment_term_id: params.enrollment_term_id, from: params.from, to: params.to },
+  });
+
+  return data;
+};
+
+/** Terminate the sessions of the students of sections and courses and of users by SIS id, or preview them with dry_run */
+export const terminateBatchSessions = async (
+  body: BatchSessionsBody,
+  config?: AxiosRequestConfig
+) => {
+  const { data } = await axios.request<BatchSessionTerminations>({
+    ...config,
+    method: 'post',
+    url: `/users/batch-sessions/terminate`,
+    data: body,
+  });
+
+  return data;
+};
+
+/** Terminate all mobile app sessions */
+export const terminateMobileSessions = async (
+  config?: AxiosRequestConfig
+) => {
+  const { data } = await axios.request<void>({
+    ...config,
+    method: 'delete',
+    url: `/users/mobile_sessions`,
+  });
+
+  return data;
+};
+
+export interface TerminateUserSessionsParams {
+  user_id: number;
+}
+
+/** Terminate all sessions of a user */
+export const terminateUserSessions = async (
+  params: TerminateUserSessionsParams,
+  config?: AxiosRequestConfig
+) => {
+  const { data } = await axios.request<void>({
+    ...config,
+    method: 'delete',
+    url: `/users/${params.user_id}/sessions`,
+  });
+
+  return data;
+};
+
+export interface UpdateAssignmentRuleParams {
+  rule_id: number;
+}
+
+/** Replace the conditions and classification of an assignment rule, Admin only */
+export const updateAssignmentRule = async (
+  params: UpdateAssignmentRuleParams,
+  body: AssignmentRuleBody,
+  config?: AxiosRequestConfig
+) => {
+  const { data } = await axios.request<AssignmentRule>({
+    ...config,
+    method: 'post',
+    url: `/assignment-rules/${params.rule_id}`,
+    data: body,
+  });
+
+  return data;
+};
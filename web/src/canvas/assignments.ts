@@ -1,69 +1,24 @@
 import { Semaphore } from 'async-mutex';
-import { axios } from '../axios';
+import {
+  AssignmentResult,
+  GetUngradedAssignmentsByUserResponse,
+  UngradedAssignment,
+  UngradedAssignmentWithAccountCourseInfo,
+  getAssignmentsResultsByUser,
+  getUngradedAssignmentsByAccountID as getUngradedAssignmentsByAccount,
+  getUngradedAssignmentsByCourse,
+  getUngradedAssignmentsByCourses as getUngradedAssignmentsByCourseIDs,
+  getUngradedAssignmentsByUser,
+} from './api.gen';
 import { Course } from './courses';
 
-export interface UngradedAssignment {
-  course_id: number;
-  name: string;
-  section: string;
-  needs_grading_section: number;
-  teachers: string;
-  due_at: string;
-  unlock_at: string;
-  lock_at: string;
-  published: boolean;
-  gradebook_url: string;
-}
-
-export interface UngradedAssignmentWithAccountCourseInfo {
-  account: string;
-  course_name: string;
-  course_id: number;
-  name: string;
-  section: string;
-  needs_grading_section: number;
-  teachers: string;
-  due_at: string;
-  unlock_at: string;
-  lock_at: string;
-  published: boolean;
-  gradebook_url: string;
-}
-
-export interface AssignmentResult {
-  user_sis_id: string;
-  name: string;
-  account: string;
-  course_name: string;
-  section: string;
-  title: string;
-  points_possible: number | null;
-  score: number | null;
-  discrepancy: string;
-  submitted_at: string;
-  status: string;
-  due_at: string;
-  course_state: string;
-  enrollment_role: string;
-  enrollment_state: string;
-}
+export type {
+  AssignmentResult,
+  UngradedAssignment,
+  UngradedAssignmentWithAccountCourseInfo,
+};
 
-export interface UngradedAssignmentByUser {
-  user_sis_id: string;
-  name: string;
-  account: string;
-  course_name: string;
-  section: string;
-  title: string;
-  points_possible: number | null;
-  score: number | null;
-  submitted_at: string;
-  status: string;
-  course_state: string;
-  enrollment_role: string;
-  enrollment_state: string;
-  speedgrader_url: string;
-}
+export type UngradedAssignmentByUser = GetUngradedAssignmentsByUserResponse;
 
 // https://github.com/TanStack/query/discussions/4943
 // Making only 10 API call is parallel
@@ -79,24 +34,14 @@ export const getUngradedAssignmentsByCourseID = async (
   try {
     const data = await ungradedAssignmentsByCourseIDSemaphore.runExclusive(
       async () => {
-        const { data, status } = await axios(
-          `/courses/${courseID}/ungraded-assignments`,
+        return await getUngradedAssignmentsByCourse(
           {
-            signal: signal,
-            params: {
-              course_name: courseName,
-              account_name: accountName,
-            },
-          }
+            course_id: courseID,
+            course_name: courseName,
+            account_name: accountName,
+          },
+          { signal: signal }
         );
-
-        if (status !== 200) {
-          throw new Error(
-            `Error fetching ungraded assignments of course: ${courseID}`
-          );
-        }
-
-        return data as UngradedAssignmentWithAccountCourseInfo[];
       }
     );
 
@@ -111,11 +56,10 @@ export const getAssignmentsResultsByUserID = async (
   userID: number
 ) => {
   try {
-    const { data } = await axios.get(`/users/${userID}/assignments-results`, {
-      signal: signal,
-    });
-
-    return data as AssignmentResult[];
+    return await getAssignmentsResultsByUser(
+      { user_id: userID },
+      { signal: signal }
+    );
   } catch (err) {
     throw err as Error;
   }
@@ -126,11 +70,10 @@ export const getUngradedAssignmentsByUserID = async (
   userID: number
 ) => {
   try {
-    const { data } = await axios.get(`/users/${userID}/ungraded-assignments`, {
-      signal: signal,
-    });
-
-    return data as UngradedAssignmentByUser[];
+    return await getUngradedAssignmentsByUser(
+      { user_id: userID },
+      { signal: signal }
+    );
   } catch (err) {
     throw err as Error;
   }
@@ -141,20 +84,10 @@ export const getUngradedAssignmentsByAccountID = async (
   accountID: number
 ) => {
   try {
-    const { data, status } = await axios(
-      `/accounts/${accountID}/ungraded-assignments`,
-      {
-        signal: signal,
-      }
+    return await getUngradedAssignmentsByAccount(
+      { account_id: accountID },
+      { signal: signal }
     );
-
-    if (status !== 200) {
-      throw new Error(
-        `Error fetching ungraded assignments of account: ${accountID}`
-      );
-    }
-
-    return data as UngradedAssignmentWithAccountCourseInfo[];
   } catch (err) {
     throw err as Error;
   }
@@ -170,21 +103,11 @@ export const getUngradedAssignmentsByCourses = async (
   try {
     const data = await ungradedAssignmentsByCoursesSemaphore.runExclusive(
       async () => {
-        const { data, status } = await axios<UngradedAssignment[]>(
-          `/courses/ungraded-assignments`,
-          {
-            signal: signal,
-            params: {
-              ids: ids,
-            },
-          }
-        );
-
-        if (status !== 200) {
-          throw new Error(
-            `Error fetching ungraded assignments of courses with ids: ${ids}}`
+        const data: UngradedAssignment[] =
+          await getUngradedAssignmentsByCourseIDs(
+            { ids: ids.split(',').map(Number) },
+            { signal: signal }
           );
-        }
 
         return data.map((d) => {
           const course = courses.find((course) => course.id === d.course_id);
@@ -1,31 +1,22 @@
 import { Semaphore } from 'async-mutex';
-import { axios } from '../axios';
 import { Course } from '../supabase/courses';
+import {
+  EnrollmentResult,
+  getEnrollmentResultsByCourse,
+  getEnrollmentsResultsByUser,
+} from './api.gen';
 
-export interface EnrollmentResult {
-  sis_id: string;
-  name: string;
-  account: string;
-  course_name: string;
-  section: string;
-  enrollment_state: string;
-  course_state: string;
-  current_grade: string | null;
-  current_score: number | null;
-  enrollment_role: string;
-  grades_url: string;
-}
+export type { EnrollmentResult };
 
 export const getEnrollmentsResultsByUserID = async (
   signal: AbortSignal,
   userID: number
 ) => {
   try {
-    const { data } = await axios.get(`/users/${userID}/enrollments-results`, {
-      signal: signal,
-    });
-
-    return data as EnrollmentResult[];
+    return await getEnrollmentsResultsByUser(
+      { user_id: userID },
+      { signal: signal }
+    );
   } catch (err) {
     throw err as Error;
   }
@@ -44,25 +35,15 @@ export const getEnrollmentsResultsByCourse = async (
           return [];
         }
 
-        const { data, status } = await axios.get(
-          `/courses/${course.id}/enrollments-results`,
+        return await getEnrollmentResultsByCourse(
           {
-            signal: signal,
-            params: {
-              course_name: course.name,
-              course_workflow_state: course.workflow_state,
-              account_name: course.account_name,
-            },
-          }
+            course_id: course.id,
+            course_name: course.name,
+            course_workflow_state: course.workflow_state,
+            account_name: course.account_name,
+          },
+          { signal: signal }
         );
-
-        if (status !== 200) {
-          throw new Error(
-            `Error fetching enrollments result of course: ${course.id}`
-          );
-        }
-
-        return data as EnrollmentResult[];
       }
     );
 
@@ -1,20 +1,7 @@
 import { Semaphore } from 'async-mutex';
-import { axios } from '../axios';
+import { GradeChangeLog, getGradeChangeLogsByGraderID } from './api.gen';
 
-export interface GradeChangeLog {
-  id: string;
-  event_type: string;
-  grade_defore: string | null;
-  grade_after: string | null;
-  created_at: string;
-  user_name: string;
-  user_id: number;
-  course_name: string;
-  course_id: number;
-  account_id: number;
-  assignment_id: number;
-  assignment_title: string;
-}
+export type { GradeChangeLog };
 
 const gradeChangeLogSemaphore = new Semaphore(10);
 
@@ -26,24 +13,14 @@ export const getGradeChangeLogs = async (
 ) => {
   try {
     const data = await gradeChangeLogSemaphore.runExclusive(async () => {
-      const { data, status } = await axios(
-        `/users/${graderID}/grade-change-logs`,
+      return await getGradeChangeLogsByGraderID(
         {
-          signal: signal,
-          params: {
-            start_time: startTime.toDateString(),
-            end_time: endTime.toDateString(),
-          },
-        }
+          grader_id: graderID,
+          start_time: startTime.toDateString(),
+          end_time: endTime.toDateString(),
+        },
+        { signal: signal }
       );
-
-      if (status !== 200) {
-        throw new Error(
-          `Error fetching grade change logs of grader: ${graderID}}`
-        );
-      }
-
-      return data as GradeChangeLog[];
     });
 
     return data;